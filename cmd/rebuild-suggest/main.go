@@ -0,0 +1,31 @@
+// Command rebuild-suggest recomputes the Redis autocomplete index for brands
+// and models from the car table. Run it after restoring Redis or whenever the
+// index is suspected to have drifted.
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/NhutNam2904/carzone/driver"
+	carStore "github.com/NhutNam2904/carzone/store/car"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
+
+	driver.InitRedis()
+	driver.StartUpDB()
+	defer driver.CloseDB()
+
+	store := carStore.New(driver.GetDBCarManageMent(), driver.GetRedis())
+
+	if err := store.RebuildSuggestIndex(context.Background()); err != nil {
+		log.Fatalf("Error while rebuilding the suggest index: %v", err)
+	}
+
+	log.Println("Successfully rebuilt the suggest index")
+}
@@ -0,0 +1,213 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FakeGateway is an in-memory PaymentGateway for local development. Intents
+// are authorized straight away and every change is posted, signed, to
+// callbackURL like a real provider would. Intents are lost on restart.
+type FakeGateway struct {
+	secret      string
+	callbackURL string
+	client      *http.Client
+
+	mu      sync.Mutex
+	intents map[string]*models.PaymentSnapshot
+}
+
+// NewFakeGateway signs callbacks with secret. An empty secret gets a random
+// one, which is enough when the callbacks come back to this process. With
+// no callbackURL no webhooks are sent.
+func NewFakeGateway(secret, callbackURL string) *FakeGateway {
+	if secret == "" {
+		secret = randomSecret()
+	}
+
+	return &FakeGateway{
+		secret:      secret,
+		callbackURL: callbackURL,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		intents:     map[string]*models.PaymentSnapshot{},
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateIntent(ctx context.Context, reference string, amount models.Money) (models.PaymentSnapshot, error) {
+	intent := &models.PaymentSnapshot{
+		ProviderRef: "pi_" + uuid.NewString(),
+		Status:      models.PaymentStatusAuthorized,
+		Amount:      amount.Amount,
+		Captured:    decimal.Zero,
+		Refunded:    decimal.Zero,
+		Currency:    amount.Currency,
+	}
+
+	g.mu.Lock()
+	g.intents[intent.ProviderRef] = intent
+	snapshot := *intent
+	g.mu.Unlock()
+
+	g.notify("payment_intent.authorized", snapshot)
+	return snapshot, nil
+}
+
+func (g *FakeGateway) GetIntent(ctx context.Context, providerRef string) (models.PaymentSnapshot, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	intent, ok := g.intents[providerRef]
+	if !ok {
+		return models.PaymentSnapshot{}, ErrIntentNotFound
+	}
+	return *intent, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, providerRef string, amount models.Money) (models.PaymentSnapshot, error) {
+	g.mu.Lock()
+
+	intent, ok := g.intents[providerRef]
+	if !ok {
+		g.mu.Unlock()
+		return models.PaymentSnapshot{}, ErrIntentNotFound
+	}
+
+	captured := intent.Captured.Add(amount.Amount)
+	if intent.Status != models.PaymentStatusAuthorized || captured.GreaterThan(intent.Amount) {
+		g.mu.Unlock()
+		return models.PaymentSnapshot{}, fmt.Errorf("%w: capture exceeds authorization", ErrDeclined)
+	}
+
+	intent.Captured = captured
+	intent.Status = models.PaymentStatusCaptured
+	snapshot := *intent
+	g.mu.Unlock()
+
+	g.notify("payment_intent.captured", snapshot)
+	return snapshot, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, providerRef string, amount models.Money) (models.PaymentSnapshot, error) {
+	g.mu.Lock()
+
+	intent, ok := g.intents[providerRef]
+	if !ok {
+		g.mu.Unlock()
+		return models.PaymentSnapshot{}, ErrIntentNotFound
+	}
+
+	refunded := intent.Refunded.Add(amount.Amount)
+	if refunded.GreaterThan(intent.Captured) {
+		g.mu.Unlock()
+		return models.PaymentSnapshot{}, fmt.Errorf("%w: refund exceeds captured amount", ErrDeclined)
+	}
+
+	intent.Refunded = refunded
+	if refunded.Equal(intent.Captured) {
+		intent.Status = models.PaymentStatusRefunded
+	}
+	snapshot := *intent
+	g.mu.Unlock()
+
+	g.notify("payment_intent.refunded", snapshot)
+	return snapshot, nil
+}
+
+func (g *FakeGateway) Cancel(ctx context.Context, providerRef string) (models.PaymentSnapshot, error) {
+	g.mu.Lock()
+
+	intent, ok := g.intents[providerRef]
+	if !ok {
+		g.mu.Unlock()
+		return models.PaymentSnapshot{}, ErrIntentNotFound
+	}
+
+	if intent.Status != models.PaymentStatusAuthorized {
+		g.mu.Unlock()
+		return models.PaymentSnapshot{}, fmt.Errorf("%w: only authorized intents can be cancelled", ErrDeclined)
+	}
+
+	intent.Status = models.PaymentStatusCancelled
+	snapshot := *intent
+	g.mu.Unlock()
+
+	g.notify("payment_intent.cancelled", snapshot)
+	return snapshot, nil
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, signature string) (models.PaymentEvent, error) {
+	if err := Verify(g.secret, payload, signature, time.Now()); err != nil {
+		return models.PaymentEvent{}, err
+	}
+
+	var event models.PaymentEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return models.PaymentEvent{}, err
+	}
+	return event, nil
+}
+
+// notify posts the event in the background; a real provider would retry,
+// the fake just logs failures and relies on reconciliation.
+func (g *FakeGateway) notify(eventType string, snapshot models.PaymentSnapshot) {
+	if g.callbackURL == "" {
+		return
+	}
+
+	event := models.PaymentEvent{
+		ID:      "evt_" + uuid.NewString(),
+		Type:    eventType,
+		Payment: snapshot,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Println("Error marshalling fake payment event: ", err)
+		return
+	}
+
+	go func() {
+		req, err := http.NewRequest(http.MethodPost, g.callbackURL, bytes.NewReader(payload))
+		if err != nil {
+			log.Println("Error building fake payment webhook: ", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SignatureHeader, Sign(g.secret, payload, time.Now()))
+
+		resp, err := g.client.Do(req)
+		if err != nil {
+			log.Println("Error sending fake payment webhook: ", err)
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			log.Printf("Fake payment webhook %s answered %d", event.ID, resp.StatusCode)
+		}
+	}()
+}
+
+func randomSecret() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NhutNam2904/carzone/models"
+)
+
+// SignatureHeader carries the webhook signature on gateway callbacks.
+const SignatureHeader = "X-Payment-Signature"
+
+var (
+	ErrIntentNotFound = errors.New("payment intent not found at provider")
+	ErrDeclined       = errors.New("payment declined by provider")
+)
+
+// PaymentGateway is a payment provider. Every call returns the provider's
+// full view of the intent afterwards, so callers can merge it with
+// models.Payment.Apply whether it arrives here or through a webhook.
+type PaymentGateway interface {
+	// Name identifies the provider; provider references are only unique
+	// per provider.
+	Name() string
+
+	// CreateIntent authorizes amount for the payment identified by
+	// reference (our payment ID).
+	CreateIntent(ctx context.Context, reference string, amount models.Money) (models.PaymentSnapshot, error)
+
+	GetIntent(ctx context.Context, providerRef string) (models.PaymentSnapshot, error)
+
+	Capture(ctx context.Context, providerRef string, amount models.Money) (models.PaymentSnapshot, error)
+
+	Refund(ctx context.Context, providerRef string, amount models.Money) (models.PaymentSnapshot, error)
+
+	// Cancel voids an authorization that has not been captured.
+	Cancel(ctx context.Context, providerRef string) (models.PaymentSnapshot, error)
+
+	// ParseWebhook checks the signature of a callback and decodes it. It
+	// returns models.ErrInvalidWebhookSignature for forged or stale calls.
+	ParseWebhook(payload []byte, signature string) (models.PaymentEvent, error)
+}
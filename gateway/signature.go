@@ -0,0 +1,68 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+)
+
+// SignatureTolerance is how old a signed callback may be before it is
+// rejected as a replay.
+const SignatureTolerance = 5 * time.Minute
+
+// Sign returns a signature header value of the form "t=<unix>,v1=<hex>",
+// where v1 is the HMAC-SHA256 of "<unix>.<payload>" under secret.
+func Sign(secret string, payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + signature(secret, timestamp, payload)
+}
+
+// Verify checks a header produced by Sign. The timestamp must be within
+// SignatureTolerance of now.
+func Verify(secret string, payload []byte, header string, now time.Time) error {
+	var timestamp, sig string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			sig = value
+		}
+	}
+
+	if timestamp == "" || sig == "" {
+		return fmt.Errorf("%w: malformed header", models.ErrInvalidWebhookSignature)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp", models.ErrInvalidWebhookSignature)
+	}
+
+	age := now.Sub(time.Unix(unix, 0))
+	if age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", models.ErrInvalidWebhookSignature)
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, timestamp, payload))) {
+		return models.ErrInvalidWebhookSignature
+	}
+
+	return nil
+}
+
+func signature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+)
+
+const (
+	testSecret  = "whsec_test"
+	testPayload = `{"id":"evt_1"}`
+)
+
+func TestSign(t *testing.T) {
+	got := Sign(testSecret, []byte(testPayload), time.Unix(1700000000, 0))
+	want := "t=1700000000,v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925"
+	if got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	signedAt := time.Unix(1700000000, 0)
+	header := Sign(testSecret, []byte(testPayload), signedAt)
+
+	tests := []struct {
+		name    string
+		secret  string
+		payload string
+		header  string
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", testSecret, testPayload, header, signedAt, false},
+		{"valid with spaces", testSecret, testPayload, "t=1700000000, v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925", signedAt, false},
+		{"at the tolerance", testSecret, testPayload, header, signedAt.Add(SignatureTolerance), false},
+		{"too old", testSecret, testPayload, header, signedAt.Add(SignatureTolerance + time.Second), true},
+		{"too far ahead", testSecret, testPayload, header, signedAt.Add(-SignatureTolerance - time.Second), true},
+		{"wrong secret", "whsec_other", testPayload, header, signedAt, true},
+		{"tampered payload", testSecret, `{"id":"evt_2"}`, header, signedAt, true},
+		{"tampered timestamp", testSecret, testPayload, "t=1700000001,v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925", signedAt, true},
+		{"missing signature", testSecret, testPayload, "t=1700000000", signedAt, true},
+		{"missing timestamp", testSecret, testPayload, "v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925", signedAt, true},
+		{"bad timestamp", testSecret, testPayload, "t=soon,v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925", signedAt, true},
+		{"empty header", testSecret, testPayload, "", signedAt, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, []byte(tt.payload), tt.header, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, models.ErrInvalidWebhookSignature) {
+				t.Errorf("Verify() error = %v, want models.ErrInvalidWebhookSignature", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"go.opentelemetry.io/otel"
+)
+
+type AuditHandler struct {
+	service service.AuditServiceInterface
+}
+
+func NewAuditHandler(service service.AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// GetEntries lists audit entries, newest first. Supported filters: actor,
+// action, entityType, entityId, from and to (RFC 3339) and limit.
+func (h *AuditHandler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("AuditHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetEntries-Handler")
+
+	defer span.End()
+
+	filter, err := parseAuditFilter(r)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.service.GetEntries(ctx, filter)
+
+	if err != nil {
+		log.Println("Error Getting Audit Entries: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	responseBody, err := json.Marshal(entries)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write(responseBody)
+}
+
+func parseAuditFilter(r *http.Request) (models.AuditFilter, error) {
+	query := r.URL.Query()
+
+	filter := models.AuditFilter{
+		Actor:      query.Get("actor"),
+		Action:     query.Get("action"),
+		EntityType: query.Get("entityType"),
+		EntityID:   query.Get("entityId"),
+	}
+
+	if from := query.Get("from"); from != "" {
+		value, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, errors.New("from must be an RFC 3339 timestamp")
+		}
+		filter.From = value
+	}
+
+	if to := query.Get("to"); to != "" {
+		value, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, errors.New("to must be an RFC 3339 timestamp")
+		}
+		filter.To = value
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return filter, errors.New("limit must be a number")
+		}
+		filter.Limit = value
+	}
+
+	return filter, nil
+}
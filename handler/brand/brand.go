@@ -0,0 +1,294 @@
+package brand
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type BrandHandler struct {
+	service service.BrandServiceInterface
+}
+
+func NewBrandHandler(service service.BrandServiceInterface) *BrandHandler {
+	return &BrandHandler{service: service}
+}
+
+func (h *BrandHandler) GetBrands(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetBrands-Handler")
+
+	defer span.End()
+
+	brands, err := h.service.GetBrands(ctx)
+
+	if err != nil {
+		log.Println("Error Getting Brands: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, brands)
+}
+
+func (h *BrandHandler) GetBrandByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetBrandByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	brand, err := h.service.GetBrandById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Get Brand by ID: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		errorMessage := fmt.Sprintf("Error Get Brand by ID: %s", err)
+
+		_, _ = w.Write([]byte(errorMessage))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, brand)
+}
+
+func (h *BrandHandler) CreateBrand(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateBrand-Handler")
+
+	defer span.End()
+
+	var brandReq models.BrandRequest
+
+	if err := readJSON(r, &brandReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	brand, err := h.service.CreateBrand(ctx, &brandReq)
+
+	if err != nil {
+		log.Println("Error Creating Brand: ", err)
+		writeBrandError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, brand)
+}
+
+func (h *BrandHandler) UpdateBrand(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateBrand-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var brandReq models.BrandRequest
+
+	if err := readJSON(r, &brandReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	brand, err := h.service.UpdateBrand(ctx, middleware.Actor(ctx), id, &brandReq)
+
+	if err != nil {
+		log.Println("Error Updating Brand: ", err)
+		writeBrandError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, brand)
+}
+
+func (h *BrandHandler) DeleteBrand(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteBrand-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	brand, err := h.service.DeleteBrand(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Brand: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, brand)
+}
+
+func (h *BrandHandler) GetModelsByBrand(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetModelsByBrand-Handler")
+
+	defer span.End()
+
+	brandID := mux.Vars(r)["id"]
+
+	carModels, err := h.service.GetModelsByBrand(ctx, brandID)
+
+	if err != nil {
+		log.Println("Error Getting Models: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, carModels)
+}
+
+func (h *BrandHandler) GetModelByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetModelByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	carModel, err := h.service.GetModelById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Get Model by ID: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		errorMessage := fmt.Sprintf("Error Get Model by ID: %s", err)
+
+		_, _ = w.Write([]byte(errorMessage))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, carModel)
+}
+
+func (h *BrandHandler) CreateModel(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateModel-Handler")
+
+	defer span.End()
+
+	brandID := mux.Vars(r)["id"]
+
+	var modelReq models.CarModelRequest
+
+	if err := readJSON(r, &modelReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	carModel, err := h.service.CreateModel(ctx, brandID, &modelReq)
+
+	if err != nil {
+		log.Println("Error Creating Model: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, carModel)
+}
+
+func (h *BrandHandler) UpdateModel(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateModel-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var modelReq models.CarModelRequest
+
+	if err := readJSON(r, &modelReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	carModel, err := h.service.UpdateModel(ctx, id, &modelReq)
+
+	if err != nil {
+		log.Println("Error Updating Model: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, carModel)
+}
+
+func (h *BrandHandler) DeleteModel(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BrandHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteModel-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	carModel, err := h.service.DeleteModel(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Model: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, carModel)
+}
+
+// writeBrandError answers 409 for a brand name that is already taken and
+// 500 otherwise.
+func writeBrandError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrBrandNameTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
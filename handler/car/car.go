@@ -2,13 +2,18 @@ package car
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/NhutNam2904/carzone/middleware"
 	"github.com/NhutNam2904/carzone/models"
 	"github.com/NhutNam2904/carzone/service"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"go.opentelemetry.io/otel"
 )
@@ -41,6 +46,13 @@ func (h *CarHandler) GetCarByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if currency := r.URL.Query().Get("currency"); currency != "" && res.ID != uuid.Nil {
+		if err := h.service.ConvertPrice(ctx, res, currency); err != nil {
+			writeConversionError(w, err)
+			return
+		}
+	}
+
 	bodyresponse, err := json.Marshal(res) // byte
 
 	fmt.Print(string(bodyresponse))
@@ -69,16 +81,38 @@ func (h *CarHandler) GetCarByBrand(w http.ResponseWriter, r *http.Request) {
 
 	defer span.End()
 
-	brand := r.URL.Query().Get("brand")
-	isEngine := r.URL.Query().Get("isEngine") == "true"
+	filter, err := parseCarFilter(r)
 
-	res, err := h.service.GetCarByBrand(ctx, brand, isEngine)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	brand := filter.Brand
+	isEngine := filter.IsEngine
+
+	var res []models.Car
+
+	if filter.IsBrandOnly() {
+		res, err = h.service.GetCarByBrand(ctx, brand, isEngine)
+	} else {
+		res, err = h.service.ListCars(ctx, filter)
+	}
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println("Error: ", err)
 		return
 	}
+
+	if currency := r.URL.Query().Get("currency"); currency != "" {
+		for i := range res {
+			if err := h.service.ConvertPrice(ctx, &res[i], currency); err != nil {
+				writeConversionError(w, err)
+				return
+			}
+		}
+	}
 	body, err := json.Marshal(res)
 
 	if err != nil {
@@ -124,7 +158,7 @@ func (h *CarHandler) CreateCar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdCar, err := h.service.CreateCar(ctx, &carReq)
+	createdCar, err := h.service.CreateCar(ctx, middleware.Actor(ctx), &carReq)
 
 	if err != nil {
 		log.Println("Error Creating Car: ", err)
@@ -177,7 +211,7 @@ func (h *CarHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatecar, err := h.service.UpdateCar(ctx, id, &carReq)
+	updatecar, err := h.service.UpdateCar(ctx, middleware.Actor(ctx), id, &carReq)
 
 	if err != nil {
 		log.Println("Error Updating Car: ", err)
@@ -212,7 +246,7 @@ func (h *CarHandler) DeleteCar(w http.ResponseWriter, r *http.Request) {
 
 	id := params["id"]
 
-	cardelete, err := h.service.DeleteCar(ctx, id)
+	cardelete, err := h.service.DeleteCar(ctx, middleware.Actor(ctx), id)
 
 	if err != nil {
 		log.Println("Error Deleting Car: ", err)
@@ -236,3 +270,205 @@ func (h *CarHandler) DeleteCar(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[INFO] Success: Delete Car By ID: %s", id)
 
 }
+
+func (h *CarHandler) SearchCars(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("CarHandler")
+
+	ctx, span := tracer.Start(r.Context(), "SearchCars-Handler")
+
+	defer span.End()
+
+	q := r.URL.Query().Get("q")
+
+	if strings.TrimSpace(q) == "" {
+		http.Error(w, "Query parameter q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	res, err := h.service.SearchCars(ctx, q, limit)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error: ", err)
+		return
+	}
+
+	if currency := r.URL.Query().Get("currency"); currency != "" {
+		for i := range res {
+			if err := h.service.ConvertPrice(ctx, &res[i].Car, currency); err != nil {
+				writeConversionError(w, err)
+				return
+			}
+		}
+	}
+	body, err := json.Marshal(res)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error: ", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(body)
+
+	if err != nil {
+		log.Println("Error Writing Response: ", err)
+	}
+
+	log.Printf("[INFO] Success: SearchCars - Query: %s, ResultCount: %d", q, len(res))
+}
+
+func (h *CarHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("CarHandler")
+
+	ctx, span := tracer.Start(r.Context(), "Suggest-Handler")
+
+	defer span.End()
+
+	prefix := r.URL.Query().Get("prefix")
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	res, err := h.service.Suggest(ctx, prefix, limit)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error: ", err)
+		return
+	}
+	body, err := json.Marshal(res)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error: ", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(body)
+
+	if err != nil {
+		log.Println("Error Writing Response: ", err)
+	}
+}
+
+// writeConversionError answers 400 when the client asked for a currency we
+// cannot convert to, and 500 for anything else.
+func writeConversionError(w http.ResponseWriter, err error) {
+	log.Println("Error Converting Price: ", err)
+
+	if errors.Is(err, models.ErrUnsupportedCurrency) || errors.Is(err, models.ErrExchangeRateNotFound) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func (h *CarHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("CarHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetPriceHistory-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	res, err := h.service.GetPriceHistory(ctx, id)
+
+	if err != nil {
+		log.Println("Error: ", err)
+		if errors.Is(err, models.ErrCarNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	body, err := json.Marshal(res)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error: ", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(body)
+
+	if err != nil {
+		log.Println("Error Writing Response: ", err)
+	}
+}
+
+// parseCarFilter reads the GET /cars query string.
+func parseCarFilter(r *http.Request) (models.CarFilter, error) {
+	query := r.URL.Query()
+
+	filter := models.CarFilter{
+		Brand:    query.Get("brand"),
+		IsEngine: query.Get("isEngine") == "true",
+	}
+
+	if dealershipID := query.Get("dealershipId"); dealershipID != "" {
+		if _, err := uuid.Parse(dealershipID); err != nil {
+			return filter, errors.New("dealershipId must be a valid UUID")
+		}
+		filter.DealershipID = dealershipID
+	}
+
+	if near := query.Get("near"); near != "" {
+		point, err := parseGeoPoint(near)
+		if err != nil {
+			return filter, err
+		}
+		filter.Near = &point
+		filter.RadiusKm = models.DefaultSearchRadiusKm
+
+		if radius := query.Get("radiusKm"); radius != "" {
+			value, err := strconv.ParseFloat(radius, 64)
+			if err != nil || value <= 0 {
+				return filter, errors.New("radiusKm must be a positive number")
+			}
+			filter.RadiusKm = value
+		}
+	}
+
+	if days := query.Get("reducedWithinDays"); days != "" {
+		value, err := strconv.Atoi(days)
+		if err != nil || value <= 0 {
+			return filter, errors.New("reducedWithinDays must be a positive number")
+		}
+		filter.ReducedWithinDays = value
+	}
+
+	return filter, nil
+}
+
+// parseGeoPoint reads a "lat,lng" pair such as "10.7769,106.7009".
+func parseGeoPoint(value string) (models.GeoPoint, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return models.GeoPoint{}, errors.New("near must be given as lat,lng")
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return models.GeoPoint{}, errors.New("near must be given as lat,lng")
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return models.GeoPoint{}, errors.New("near must be given as lat,lng")
+	}
+
+	if err := models.ValidateCoordinates(latitude, longitude); err != nil {
+		return models.GeoPoint{}, err
+	}
+
+	return models.GeoPoint{Latitude: latitude, Longitude: longitude}, nil
+}
@@ -0,0 +1,161 @@
+package dealership
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type DealershipHandler struct {
+	service service.DealershipServiceInterface
+}
+
+func NewDealershipHandler(service service.DealershipServiceInterface) *DealershipHandler {
+	return &DealershipHandler{service: service}
+}
+
+func (h *DealershipHandler) GetDealerships(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("DealershipHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetDealerships-Handler")
+
+	defer span.End()
+
+	dealerships, err := h.service.GetDealerships(ctx)
+
+	if err != nil {
+		log.Println("Error Getting Dealerships: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dealerships)
+}
+
+func (h *DealershipHandler) GetDealershipByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("DealershipHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetDealershipByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	dealership, err := h.service.GetDealershipById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Get Dealership by ID: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		errorMessage := fmt.Sprintf("Error Get Dealership by ID: %s", err)
+
+		_, _ = w.Write([]byte(errorMessage))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dealership)
+}
+
+func (h *DealershipHandler) CreateDealership(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("DealershipHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateDealership-Handler")
+
+	defer span.End()
+
+	var dealershipReq models.DealershipRequest
+
+	if err := readJSON(r, &dealershipReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	dealership, err := h.service.CreateDealership(ctx, &dealershipReq)
+
+	if err != nil {
+		log.Println("Error Creating Dealership: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, dealership)
+}
+
+func (h *DealershipHandler) UpdateDealership(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("DealershipHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateDealership-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var dealershipReq models.DealershipRequest
+
+	if err := readJSON(r, &dealershipReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	dealership, err := h.service.UpdateDealership(ctx, id, &dealershipReq)
+
+	if err != nil {
+		log.Println("Error Updating Dealership: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dealership)
+}
+
+func (h *DealershipHandler) DeleteDealership(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("DealershipHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteDealership-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	dealership, err := h.service.DeleteDealership(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Dealership: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dealership)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/NhutNam2904/carzone/middleware"
 	"github.com/NhutNam2904/carzone/models"
 	"github.com/NhutNam2904/carzone/service"
 	"github.com/gorilla/mux"
@@ -92,7 +93,7 @@ func (e *EngineHandler) CreateEngine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdengine, err := e.service.CreateEngine(ctx, &engine)
+	createdengine, err := e.service.CreateEngine(ctx, middleware.Actor(ctx), &engine)
 
 	if err != nil {
 		log.Println("Error Creating Engine: ", err)
@@ -143,7 +144,7 @@ func (e *EngineHandler) EngineUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatengine, err := e.service.EngineUpdate(ctx, id, &engine)
+	updatengine, err := e.service.EngineUpdate(ctx, middleware.Actor(ctx), id, &engine)
 
 	if err != nil {
 		log.Println("Error Updating Engine: ", err)
@@ -177,7 +178,7 @@ func (e *EngineHandler) DeleteEngine(w http.ResponseWriter, r *http.Request) {
 
 	id := params["id"]
 
-	enginedelete, err := e.service.DeleteEngine(ctx, id)
+	enginedelete, err := e.service.DeleteEngine(ctx, middleware.Actor(ctx), id)
 
 	if err != nil {
 		log.Println("Error Deleting Engine: ", err)
@@ -0,0 +1,86 @@
+package exchangerate
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"go.opentelemetry.io/otel"
+)
+
+type ExchangeRateHandler struct {
+	service service.ExchangeRateServiceInterface
+}
+
+func NewExchangeRateHandler(service service.ExchangeRateServiceInterface) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: service}
+}
+
+func (h *ExchangeRateHandler) GetRates(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ExchangeRateHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetRates-Handler")
+
+	defer span.End()
+
+	rates, err := h.service.GetRates(ctx)
+
+	if err != nil {
+		log.Println("Error Getting Exchange Rates: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	responseBody, err := json.Marshal(rates)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write(responseBody)
+}
+
+func (h *ExchangeRateHandler) UpsertRates(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ExchangeRateHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpsertRates-Handler")
+
+	defer span.End()
+
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		log.Println("Error Reading Request Body: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var rates []models.ExchangeRate
+
+	err = json.Unmarshal(body, &rates)
+
+	if err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.UpsertRates(ctx, rates)
+
+	if err != nil {
+		log.Println("Error Updating Exchange Rates: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	log.Printf("[INFO] Success: Updated %d Exchange Rates", len(rates))
+}
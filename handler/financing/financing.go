@@ -0,0 +1,202 @@
+package financing
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type FinancingHandler struct {
+	service service.FinancingServiceInterface
+}
+
+func NewFinancingHandler(service service.FinancingServiceInterface) *FinancingHandler {
+	return &FinancingHandler{service: service}
+}
+
+// GetLenderProducts lists the products on offer; ?all=true includes
+// inactive ones.
+func (h *FinancingHandler) GetLenderProducts(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("FinancingHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetLenderProducts-Handler")
+
+	defer span.End()
+
+	activeOnly := r.URL.Query().Get("all") != "true"
+
+	products, err := h.service.GetLenderProducts(ctx, activeOnly)
+
+	if err != nil {
+		log.Println("Error Getting Lender Products: ", err)
+		writeFinancingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, products)
+}
+
+func (h *FinancingHandler) GetLenderProductByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("FinancingHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetLenderProductByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	product, err := h.service.GetLenderProductById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Get Lender Product by ID: ", err)
+		writeFinancingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, product)
+}
+
+func (h *FinancingHandler) CreateLenderProduct(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("FinancingHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateLenderProduct-Handler")
+
+	defer span.End()
+
+	var productReq models.LenderProductRequest
+
+	if err := readJSON(r, &productReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.CreateLenderProduct(ctx, &productReq)
+
+	if err != nil {
+		log.Println("Error Creating Lender Product: ", err)
+		writeFinancingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, product)
+}
+
+func (h *FinancingHandler) UpdateLenderProduct(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("FinancingHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateLenderProduct-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var productReq models.LenderProductRequest
+
+	if err := readJSON(r, &productReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.UpdateLenderProduct(ctx, id, &productReq)
+
+	if err != nil {
+		log.Println("Error Updating Lender Product: ", err)
+		writeFinancingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, product)
+}
+
+func (h *FinancingHandler) DeleteLenderProduct(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("FinancingHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteLenderProduct-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	product, err := h.service.DeleteLenderProduct(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Lender Product: ", err)
+		writeFinancingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, product)
+}
+
+func (h *FinancingHandler) QuoteFinancing(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("FinancingHandler")
+
+	ctx, span := tracer.Start(r.Context(), "QuoteFinancing-Handler")
+
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	var quoteReq models.FinancingQuoteRequest
+
+	if err := readJSON(r, &quoteReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.service.QuoteFinancing(ctx, carID, &quoteReq)
+
+	if err != nil {
+		log.Println("Error Quoting Financing: ", err)
+		writeFinancingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quote)
+}
+
+// writeFinancingError answers 404 for unknown cars and products, 400 when
+// the quote request breaks a rule, and 500 otherwise.
+func writeFinancingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrCarNotFound),
+		errors.Is(err, models.ErrLenderProductNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrInvalidFinancing):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
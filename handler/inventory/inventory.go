@@ -0,0 +1,204 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type InventoryHandler struct {
+	service service.InventoryServiceInterface
+}
+
+func NewInventoryHandler(service service.InventoryServiceInterface) *InventoryHandler {
+	return &InventoryHandler{service: service}
+}
+
+func (h *InventoryHandler) GetUnitsByCar(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("InventoryHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetUnitsByCar-Handler")
+
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	units, err := h.service.GetUnitsByCar(ctx, carID)
+
+	if err != nil {
+		log.Println("Error Getting Inventory Units: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, units)
+}
+
+func (h *InventoryHandler) GetUnitByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("InventoryHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetUnitByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	unit, err := h.service.GetUnitById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Get Inventory Unit by ID: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		errorMessage := fmt.Sprintf("Error Get Inventory Unit by ID: %s", err)
+
+		_, _ = w.Write([]byte(errorMessage))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, unit)
+}
+
+func (h *InventoryHandler) CreateUnit(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("InventoryHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateUnit-Handler")
+
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	var unitReq models.InventoryUnitRequest
+
+	if err := readJSON(r, &unitReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	unit, err := h.service.CreateUnit(ctx, carID, &unitReq)
+
+	if err != nil {
+		log.Println("Error Creating Inventory Unit: ", err)
+		writeUnitError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, unit)
+}
+
+func (h *InventoryHandler) UpdateUnit(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("InventoryHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateUnit-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var unitReq models.InventoryUnitRequest
+
+	if err := readJSON(r, &unitReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	unit, err := h.service.UpdateUnit(ctx, id, &unitReq)
+
+	if err != nil {
+		log.Println("Error Updating Inventory Unit: ", err)
+		writeUnitError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, unit)
+}
+
+func (h *InventoryHandler) DeleteUnit(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("InventoryHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteUnit-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	unit, err := h.service.DeleteUnit(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Inventory Unit: ", err)
+		writeUnitError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, unit)
+}
+
+// DecodeVIN decodes a VIN offline so clients can pre-fill brand and year
+// before registering a unit.
+func (h *InventoryHandler) DecodeVIN(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("InventoryHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DecodeVIN-Handler")
+
+	defer span.End()
+
+	vin := mux.Vars(r)["vin"]
+
+	info, err := h.service.DecodeVIN(ctx, vin)
+
+	if err != nil {
+		log.Println("Error Decoding VIN: ", err)
+		writeUnitError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+// writeUnitError answers 400 for VIN problems and statuses the client can
+// fix, 409 for units held by a reservation or order and 500 for anything
+// else.
+func writeUnitError(w http.ResponseWriter, err error) {
+	if models.IsVINError(err) || errors.Is(err, models.ErrStockStatusManaged) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrUnitInUse) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
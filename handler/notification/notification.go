@@ -0,0 +1,213 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type NotificationHandler struct {
+	service service.NotificationServiceInterface
+}
+
+func NewNotificationHandler(service service.NotificationServiceInterface) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// GetInbox lists the caller's in-app notifications; ?unread=true leaves
+// out the ones already read.
+func (h *NotificationHandler) GetInbox(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("NotificationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetInbox-Handler")
+
+	defer span.End()
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	messages, err := h.service.GetInbox(ctx, middleware.Username(ctx), unreadOnly)
+
+	if err != nil {
+		log.Println("Error Getting Inbox: ", err)
+		writeNotificationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, messages)
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("NotificationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "MarkRead-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	message, err := h.service.MarkInboxMessageRead(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Marking Notification Read: ", err)
+		writeNotificationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, message)
+}
+
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("NotificationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetPreferences-Handler")
+
+	defer span.End()
+
+	preferences, err := h.service.GetPreferences(ctx, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Getting Notification Preferences: ", err)
+		writeNotificationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preferences)
+}
+
+// UpdatePreferences replaces the caller's email address and channel
+// choices. Kinds left out go back to the default channels.
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("NotificationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdatePreferences-Handler")
+
+	defer span.End()
+
+	var preferencesReq models.NotificationPreferencesRequest
+
+	if err := readJSON(r, &preferencesReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(ctx, middleware.Username(ctx), &preferencesReq)
+
+	if err != nil {
+		log.Println("Error Updating Notification Preferences: ", err)
+		writeNotificationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("NotificationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetTemplates-Handler")
+
+	defer span.End()
+
+	templates, err := h.service.GetTemplates(ctx)
+
+	if err != nil {
+		log.Println("Error Getting Notification Templates: ", err)
+		writeNotificationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, templates)
+}
+
+func (h *NotificationHandler) UpsertTemplate(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("NotificationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpsertTemplate-Handler")
+
+	defer span.End()
+
+	kind := models.NotificationKind(mux.Vars(r)["kind"])
+
+	var templateReq models.NotificationTemplateRequest
+
+	if err := readJSON(r, &templateReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	tmpl, err := h.service.UpsertTemplate(ctx, kind, &templateReq)
+
+	if err != nil {
+		log.Println("Error Saving Notification Template: ", err)
+		writeNotificationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tmpl)
+}
+
+// GetDeliveries lists recent deliveries across customers; ?status= keeps
+// only pending, sent or failed ones.
+func (h *NotificationHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("NotificationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetDeliveries-Handler")
+
+	defer span.End()
+
+	deliveries, err := h.service.GetDeliveries(ctx, r.URL.Query().Get("status"))
+
+	if err != nil {
+		log.Println("Error Getting Notification Deliveries: ", err)
+		writeNotificationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// writeNotificationError answers 404 for unknown messages and templates,
+// 400 for a bad status filter and 500 otherwise.
+func writeNotificationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInboxMessageNotFound),
+		errors.Is(err, models.ErrNotificationTemplateNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrInvalidDeliveryStatus):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
@@ -0,0 +1,216 @@
+package order
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/NhutNam2904/carzone/store/lock"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type OrderHandler struct {
+	service service.OrderServiceInterface
+}
+
+func NewOrderHandler(service service.OrderServiceInterface) *OrderHandler {
+	return &OrderHandler{service: service}
+}
+
+// All order routes sit behind AuthMiddleware; orders are placed for the
+// authenticated user.
+
+func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("OrderHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateOrder-Handler")
+
+	defer span.End()
+
+	var orderReq models.OrderRequest
+
+	if err := readJSON(r, &orderReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.CreateOrder(ctx, middleware.Username(ctx), &orderReq)
+
+	if err != nil {
+		log.Println("Error Creating Order: ", err)
+		writeOrderError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, order)
+}
+
+func (h *OrderHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("OrderHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetOrders-Handler")
+
+	defer span.End()
+
+	orders, err := h.service.GetOrdersByCustomer(ctx, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Getting Orders: ", err)
+		writeOrderError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (h *OrderHandler) GetOrdersByDealership(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("OrderHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetOrdersByDealership-Handler")
+
+	defer span.End()
+
+	dealershipID := mux.Vars(r)["id"]
+
+	orders, err := h.service.GetOrdersByDealership(ctx, dealershipID)
+
+	if err != nil {
+		log.Println("Error Getting Dealership Orders: ", err)
+		writeOrderError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("OrderHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetOrderByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	order, err := h.service.GetOrderById(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Get Order by ID: ", err)
+		writeOrderError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, order)
+}
+
+func (h *OrderHandler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("OrderHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateOrderStatus-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var statusReq models.OrderStatusRequest
+
+	if err := readJSON(r, &statusReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	staff := middleware.HasRole(middleware.Role(ctx), middleware.RoleStaff)
+
+	order, err := h.service.UpdateOrderStatus(ctx, id, middleware.Username(ctx), staff, &statusReq)
+
+	if err != nil {
+		log.Println("Error Updating Order Status: ", err)
+		writeOrderError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, order)
+}
+
+// SetOrderDiscount is mounted for staff only.
+func (h *OrderHandler) SetOrderDiscount(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("OrderHandler")
+
+	ctx, span := tracer.Start(r.Context(), "SetOrderDiscount-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var discountReq models.OrderDiscountRequest
+
+	if err := readJSON(r, &discountReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.SetOrderDiscount(ctx, id, &discountReq)
+
+	if err != nil {
+		log.Println("Error Setting Order Discount: ", err)
+		writeOrderError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, order)
+}
+
+// writeOrderError answers 400 for unknown tax regions or cars without a
+// tax rule, 403 for changes only staff may make, 404 for unknown orders,
+// 409 when the unit is taken or busy, the price moved or the status change
+// is not allowed, and 500 otherwise.
+func writeOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrTaxRegionNotFound),
+		errors.Is(err, models.ErrNoSCTRule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrOrderForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, models.ErrOrderNotFound),
+		errors.Is(err, models.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, lock.ErrLocked),
+		errors.Is(err, models.ErrUnitNotAvailable),
+		errors.Is(err, models.ErrOrderPriceChanged),
+		errors.Is(err, models.ErrInvalidOrderTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
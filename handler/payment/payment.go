@@ -0,0 +1,244 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/gateway"
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+// maxWebhookBody caps the size of a gateway callback.
+const maxWebhookBody = 1 << 20
+
+type PaymentHandler struct {
+	service service.PaymentServiceInterface
+}
+
+func NewPaymentHandler(service service.PaymentServiceInterface) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+// Everything except Webhook sits behind AuthMiddleware. Webhook is called
+// by the payment provider and is authenticated by its signature.
+
+func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PaymentHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreatePayment-Handler")
+
+	defer span.End()
+
+	orderID := mux.Vars(r)["id"]
+
+	var paymentReq models.PaymentRequest
+
+	if err := readJSON(r, &paymentReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.service.CreatePayment(ctx, orderID, middleware.Username(ctx), &paymentReq)
+
+	if err != nil {
+		log.Println("Error Creating Payment: ", err)
+		writePaymentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, payment)
+}
+
+func (h *PaymentHandler) GetPaymentsByOrder(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PaymentHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetPaymentsByOrder-Handler")
+
+	defer span.End()
+
+	orderID := mux.Vars(r)["id"]
+
+	payments, err := h.service.GetPaymentsByOrder(ctx, orderID, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Getting Payments: ", err)
+		writePaymentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payments)
+}
+
+func (h *PaymentHandler) GetPaymentByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PaymentHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetPaymentByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	payment, err := h.service.GetPaymentById(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Get Payment by ID: ", err)
+		writePaymentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) CapturePayment(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PaymentHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CapturePayment-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	amountReq, err := readAmount(r)
+	if err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	staff := middleware.HasRole(middleware.Role(ctx), middleware.RoleStaff)
+
+	payment, err := h.service.CapturePayment(ctx, id, middleware.Username(ctx), staff, &amountReq)
+
+	if err != nil {
+		log.Println("Error Capturing Payment: ", err)
+		writePaymentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PaymentHandler")
+
+	ctx, span := tracer.Start(r.Context(), "RefundPayment-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	amountReq, err := readAmount(r)
+	if err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	staff := middleware.HasRole(middleware.Role(ctx), middleware.RoleStaff)
+
+	payment, err := h.service.RefundPayment(ctx, id, middleware.Username(ctx), staff, &amountReq)
+
+	if err != nil {
+		log.Println("Error Refunding Payment: ", err)
+		writePaymentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payment)
+}
+
+// Webhook answers 2xx only once the event is applied (or was already), so
+// the provider keeps retrying anything else.
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PaymentHandler")
+
+	ctx, span := tracer.Start(r.Context(), "Webhook-Handler")
+
+	defer span.End()
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		log.Println("Error reading payment webhook: ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.HandleWebhook(ctx, payload, r.Header.Get(gateway.SignatureHeader))
+
+	if err != nil {
+		log.Println("Error Handling Payment Webhook: ", err)
+		writePaymentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readAmount allows an empty body, meaning the full amount.
+func readAmount(r *http.Request) (models.PaymentAmountRequest, error) {
+	var amountReq models.PaymentAmountRequest
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil || len(body) == 0 {
+		return amountReq, err
+	}
+
+	err = json.Unmarshal(body, &amountReq)
+	return amountReq, err
+}
+
+// writePaymentError answers 404 for unknown payments and orders, 400 for
+// bad amounts, 401 for bad webhook signatures, 402 when the provider
+// declines, 403 when a customer captures or refunds, 409 when the payment or order is in the wrong state, and 500
+// otherwise.
+func writePaymentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrPaymentNotFound),
+		errors.Is(err, models.ErrOrderNotFound),
+		errors.Is(err, gateway.ErrIntentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrInvalidPaymentAmount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrInvalidWebhookSignature):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, models.ErrPaymentForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, gateway.ErrDeclined):
+		http.Error(w, err.Error(), http.StatusPaymentRequired)
+	case errors.Is(err, models.ErrPaymentState),
+		errors.Is(err, models.ErrOrderNotPayable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
@@ -0,0 +1,170 @@
+package promotion
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type PromotionHandler struct {
+	service service.PromotionServiceInterface
+}
+
+func NewPromotionHandler(service service.PromotionServiceInterface) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// GetPromotions lists the promotions running now; ?all=true also returns
+// inactive, scheduled and expired ones.
+func (h *PromotionHandler) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PromotionHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetPromotions-Handler")
+
+	defer span.End()
+
+	all := r.URL.Query().Get("all") == "true"
+
+	promotions, err := h.service.GetPromotions(ctx, all)
+
+	if err != nil {
+		log.Println("Error Getting Promotions: ", err)
+		writePromotionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotions)
+}
+
+func (h *PromotionHandler) GetPromotionByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PromotionHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetPromotionByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	promotion, err := h.service.GetPromotionById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Get Promotion by ID: ", err)
+		writePromotionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotion)
+}
+
+func (h *PromotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PromotionHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreatePromotion-Handler")
+
+	defer span.End()
+
+	var promotionReq models.PromotionRequest
+
+	if err := readJSON(r, &promotionReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	promotion, err := h.service.CreatePromotion(ctx, &promotionReq)
+
+	if err != nil {
+		log.Println("Error Creating Promotion: ", err)
+		writePromotionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, promotion)
+}
+
+func (h *PromotionHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PromotionHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdatePromotion-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var promotionReq models.PromotionRequest
+
+	if err := readJSON(r, &promotionReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	promotion, err := h.service.UpdatePromotion(ctx, id, &promotionReq)
+
+	if err != nil {
+		log.Println("Error Updating Promotion: ", err)
+		writePromotionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotion)
+}
+
+func (h *PromotionHandler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("PromotionHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeletePromotion-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	promotion, err := h.service.DeletePromotion(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Promotion: ", err)
+		writePromotionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotion)
+}
+
+// writePromotionError answers 404 for unknown promotions and 500 otherwise.
+func writePromotionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrPromotionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
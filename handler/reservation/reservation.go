@@ -0,0 +1,148 @@
+package reservation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/NhutNam2904/carzone/store/lock"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type ReservationHandler struct {
+	service service.ReservationServiceInterface
+}
+
+func NewReservationHandler(service service.ReservationServiceInterface) *ReservationHandler {
+	return &ReservationHandler{service: service}
+}
+
+// All reservation routes sit behind AuthMiddleware; the customer is always
+// the authenticated user.
+
+func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ReservationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateReservation-Handler")
+
+	defer span.End()
+
+	var reservationReq models.ReservationRequest
+
+	if err := readJSON(r, &reservationReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	reservation, err := h.service.CreateReservation(ctx, middleware.Username(ctx), &reservationReq)
+
+	if err != nil {
+		log.Println("Error Creating Reservation: ", err)
+		writeReservationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reservation)
+}
+
+func (h *ReservationHandler) GetReservations(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ReservationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetReservations-Handler")
+
+	defer span.End()
+
+	reservations, err := h.service.GetReservationsByCustomer(ctx, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Getting Reservations: ", err)
+		writeReservationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reservations)
+}
+
+func (h *ReservationHandler) GetReservationByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ReservationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetReservationByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	reservation, err := h.service.GetReservationById(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Get Reservation by ID: ", err)
+		writeReservationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reservation)
+}
+
+func (h *ReservationHandler) CancelReservation(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ReservationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CancelReservation-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	reservation, err := h.service.CancelReservation(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Cancelling Reservation: ", err)
+		writeReservationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reservation)
+}
+
+// writeReservationError answers 404 for unknown reservations, 409 when the
+// unit is taken or busy, and 500 for anything else.
+func writeReservationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrReservationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, lock.ErrLocked),
+		errors.Is(err, models.ErrUnitNotAvailable),
+		errors.Is(err, models.ErrReservationInactive):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
@@ -0,0 +1,188 @@
+package savedsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type SavedSearchHandler struct {
+	service service.SavedSearchServiceInterface
+}
+
+func NewSavedSearchHandler(service service.SavedSearchServiceInterface) *SavedSearchHandler {
+	return &SavedSearchHandler{service: service}
+}
+
+func (h *SavedSearchHandler) GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("SavedSearchHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetSavedSearches-Handler")
+
+	defer span.End()
+
+	searches, err := h.service.GetSavedSearches(ctx, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Getting Saved Searches: ", err)
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, searches)
+}
+
+func (h *SavedSearchHandler) GetSavedSearchByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("SavedSearchHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetSavedSearchByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	search, err := h.service.GetSavedSearchById(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Get Saved Search by ID: ", err)
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("SavedSearchHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateSavedSearch-Handler")
+
+	defer span.End()
+
+	var searchReq models.SavedSearchRequest
+
+	if err := readJSON(r, &searchReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	search, err := h.service.CreateSavedSearch(ctx, middleware.Username(ctx), &searchReq)
+
+	if err != nil {
+		log.Println("Error Creating Saved Search: ", err)
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, search)
+}
+
+func (h *SavedSearchHandler) UpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("SavedSearchHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateSavedSearch-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var searchReq models.SavedSearchRequest
+
+	if err := readJSON(r, &searchReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	search, err := h.service.UpdateSavedSearch(ctx, id, middleware.Username(ctx), &searchReq)
+
+	if err != nil {
+		log.Println("Error Updating Saved Search: ", err)
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("SavedSearchHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteSavedSearch-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	search, err := h.service.DeleteSavedSearch(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Deleting Saved Search: ", err)
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) GetMatches(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("SavedSearchHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetMatches-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	matches, err := h.service.GetMatches(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Getting Saved Search Matches: ", err)
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, matches)
+}
+
+// writeSavedSearchError answers 404 for unknown searches and 500
+// otherwise.
+func writeSavedSearchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrSavedSearchNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
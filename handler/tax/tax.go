@@ -0,0 +1,235 @@
+package tax
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type TaxHandler struct {
+	service service.TaxServiceInterface
+}
+
+func NewTaxHandler(service service.TaxServiceInterface) *TaxHandler {
+	return &TaxHandler{service: service}
+}
+
+func (h *TaxHandler) GetTaxRegions(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TaxHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetTaxRegions-Handler")
+
+	defer span.End()
+
+	regions, err := h.service.GetTaxRegions(ctx)
+
+	if err != nil {
+		log.Println("Error Getting Tax Regions: ", err)
+		writeTaxError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, regions)
+}
+
+func (h *TaxHandler) GetTaxRegion(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TaxHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetTaxRegion-Handler")
+
+	defer span.End()
+
+	code := mux.Vars(r)["code"]
+
+	region, err := h.service.GetTaxRegion(ctx, code)
+
+	if err != nil {
+		log.Println("Error Getting Tax Region: ", err)
+		writeTaxError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, region)
+}
+
+// UpsertTaxRegion creates the region named in the path or replaces its
+// rates and fees.
+func (h *TaxHandler) UpsertTaxRegion(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TaxHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpsertTaxRegion-Handler")
+
+	defer span.End()
+
+	code := mux.Vars(r)["code"]
+
+	var regionReq models.TaxRegionRequest
+
+	if err := readJSON(r, &regionReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	region, err := h.service.UpsertTaxRegion(ctx, code, &regionReq)
+
+	if err != nil {
+		log.Println("Error Saving Tax Region: ", err)
+		writeTaxError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, region)
+}
+
+func (h *TaxHandler) DeleteTaxRegion(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TaxHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteTaxRegion-Handler")
+
+	defer span.End()
+
+	code := mux.Vars(r)["code"]
+
+	region, err := h.service.DeleteTaxRegion(ctx, code)
+
+	if err != nil {
+		log.Println("Error Deleting Tax Region: ", err)
+		writeTaxError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, region)
+}
+
+func (h *TaxHandler) GetSCTRules(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TaxHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetSCTRules-Handler")
+
+	defer span.End()
+
+	rules, err := h.service.GetSCTRules(ctx)
+
+	if err != nil {
+		log.Println("Error Getting Special Consumption Tax Rules: ", err)
+		writeTaxError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rules)
+}
+
+func (h *TaxHandler) CreateSCTRule(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TaxHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateSCTRule-Handler")
+
+	defer span.End()
+
+	var ruleReq models.SCTRuleRequest
+
+	if err := readJSON(r, &ruleReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.CreateSCTRule(ctx, &ruleReq)
+
+	if err != nil {
+		log.Println("Error Creating Special Consumption Tax Rule: ", err)
+		writeTaxError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+func (h *TaxHandler) UpdateSCTRule(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TaxHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateSCTRule-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var ruleReq models.SCTRuleRequest
+
+	if err := readJSON(r, &ruleReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.UpdateSCTRule(ctx, id, &ruleReq)
+
+	if err != nil {
+		log.Println("Error Updating Special Consumption Tax Rule: ", err)
+		writeTaxError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rule)
+}
+
+func (h *TaxHandler) DeleteSCTRule(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TaxHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteSCTRule-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	rule, err := h.service.DeleteSCTRule(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Special Consumption Tax Rule: ", err)
+		writeTaxError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// writeTaxError answers 404 for unknown regions and rules and 500
+// otherwise.
+func writeTaxError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrTaxRegionNotFound) || errors.Is(err, models.ErrSCTRuleNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
@@ -0,0 +1,239 @@
+package testdrive
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type TestDriveHandler struct {
+	service service.TestDriveServiceInterface
+}
+
+func NewTestDriveHandler(service service.TestDriveServiceInterface) *TestDriveHandler {
+	return &TestDriveHandler{service: service}
+}
+
+// GetSlots lists bookable slots at a dealership for ?carId= on ?date=
+// (YYYY-MM-DD, default today).
+func (h *TestDriveHandler) GetSlots(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TestDriveHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetSlots-Handler")
+
+	defer span.End()
+
+	dealershipID := mux.Vars(r)["id"]
+	carID := r.URL.Query().Get("carId")
+
+	date := time.Now()
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			http.Error(w, "date must be given as YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+
+	slots, err := h.service.GetSlots(ctx, dealershipID, carID, date)
+
+	if err != nil {
+		log.Println("Error Getting Test Drive Slots: ", err)
+		writeTestDriveError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, slots)
+}
+
+// The routes below sit behind AuthMiddleware; the customer is always the
+// authenticated user.
+
+func (h *TestDriveHandler) CreateTestDrive(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TestDriveHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateTestDrive-Handler")
+
+	defer span.End()
+
+	var testDriveReq models.TestDriveRequest
+
+	if err := readJSON(r, &testDriveReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	testDrive, err := h.service.CreateTestDrive(ctx, middleware.Username(ctx), &testDriveReq)
+
+	if err != nil {
+		log.Println("Error Creating Test Drive: ", err)
+		writeTestDriveError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, testDrive)
+}
+
+func (h *TestDriveHandler) GetTestDrives(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TestDriveHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetTestDrives-Handler")
+
+	defer span.End()
+
+	testDrives, err := h.service.GetTestDrivesByCustomer(ctx, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Getting Test Drives: ", err)
+		writeTestDriveError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, testDrives)
+}
+
+func (h *TestDriveHandler) GetTestDriveByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TestDriveHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetTestDriveByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	testDrive, err := h.service.GetTestDriveById(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Get Test Drive by ID: ", err)
+		writeTestDriveError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, testDrive)
+}
+
+func (h *TestDriveHandler) RescheduleTestDrive(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TestDriveHandler")
+
+	ctx, span := tracer.Start(r.Context(), "RescheduleTestDrive-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var rescheduleReq models.TestDriveRescheduleRequest
+
+	if err := readJSON(r, &rescheduleReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	testDrive, err := h.service.RescheduleTestDrive(ctx, id, middleware.Username(ctx), &rescheduleReq)
+
+	if err != nil {
+		log.Println("Error Rescheduling Test Drive: ", err)
+		writeTestDriveError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, testDrive)
+}
+
+func (h *TestDriveHandler) CancelTestDrive(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TestDriveHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CancelTestDrive-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	testDrive, err := h.service.CancelTestDrive(ctx, id, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Cancelling Test Drive: ", err)
+		writeTestDriveError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, testDrive)
+}
+
+func (h *TestDriveHandler) UpdateTestDriveStatus(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("TestDriveHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateTestDriveStatus-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var statusReq models.TestDriveStatusRequest
+
+	if err := readJSON(r, &statusReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	testDrive, err := h.service.UpdateTestDriveStatus(ctx, id, &statusReq)
+
+	if err != nil {
+		log.Println("Error Updating Test Drive Status: ", err)
+		writeTestDriveError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, testDrive)
+}
+
+// writeTestDriveError answers 404 for unknown bookings, 409 for taken slots
+// and disallowed status changes, 400 for slots outside opening hours and
+// 500 for anything else.
+func writeTestDriveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrTestDriveNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrSlotUnavailable),
+		errors.Is(err, models.ErrInvalidTestDriveTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, models.ErrOutsideOpeningHours):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
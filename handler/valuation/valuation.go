@@ -0,0 +1,198 @@
+package valuation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type ValuationHandler struct {
+	service service.ValuationServiceInterface
+}
+
+func NewValuationHandler(service service.ValuationServiceInterface) *ValuationHandler {
+	return &ValuationHandler{service: service}
+}
+
+func (h *ValuationHandler) ValueTradeIn(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ValuationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "ValueTradeIn-Handler")
+
+	defer span.End()
+
+	var tradeInReq models.TradeInRequest
+
+	if err := readJSON(r, &tradeInReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	valuation, err := h.service.ValueTradeIn(ctx, &tradeInReq)
+
+	if err != nil {
+		log.Println("Error Valuing Trade-in: ", err)
+		writeValuationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, valuation)
+}
+
+func (h *ValuationHandler) GetCurves(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ValuationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetCurves-Handler")
+
+	defer span.End()
+
+	curves, err := h.service.GetCurves(ctx)
+
+	if err != nil {
+		log.Println("Error Getting Depreciation Curves: ", err)
+		writeValuationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, curves)
+}
+
+func (h *ValuationHandler) GetCurveByID(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ValuationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetCurveByID-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	curve, err := h.service.GetCurveById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Get Depreciation Curve by ID: ", err)
+		writeValuationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, curve)
+}
+
+func (h *ValuationHandler) CreateCurve(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ValuationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateCurve-Handler")
+
+	defer span.End()
+
+	var curveReq models.DepreciationCurveRequest
+
+	if err := readJSON(r, &curveReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	curve, err := h.service.CreateCurve(ctx, &curveReq)
+
+	if err != nil {
+		log.Println("Error Creating Depreciation Curve: ", err)
+		writeValuationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, curve)
+}
+
+func (h *ValuationHandler) UpdateCurve(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ValuationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateCurve-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var curveReq models.DepreciationCurveRequest
+
+	if err := readJSON(r, &curveReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	curve, err := h.service.UpdateCurve(ctx, id, &curveReq)
+
+	if err != nil {
+		log.Println("Error Updating Depreciation Curve: ", err)
+		writeValuationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, curve)
+}
+
+func (h *ValuationHandler) DeleteCurve(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("ValuationHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteCurve-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	curve, err := h.service.DeleteCurve(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Depreciation Curve: ", err)
+		writeValuationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, curve)
+}
+
+// writeValuationError answers 404 for unknown curves, 400 for bad trade-in
+// requests, 422 when there are no listings to value the car against, and
+// 500 otherwise.
+func writeValuationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrDepreciationCurveNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrInvalidValuation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrNoValuationData):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
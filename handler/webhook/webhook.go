@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type WebhookHandler struct {
+	service service.WebhookServiceInterface
+}
+
+func NewWebhookHandler(service service.WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+func (h *WebhookHandler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WebhookHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetSubscriptions-Handler")
+
+	defer span.End()
+
+	subscriptions, err := h.service.GetSubscriptions(ctx)
+
+	if err != nil {
+		log.Println("Error Getting Webhook Subscriptions: ", err)
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) GetSubscriptionById(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WebhookHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetSubscriptionById-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	subscription, err := h.service.GetSubscriptionById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Getting Webhook Subscription: ", err)
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscription)
+}
+
+// CreateSubscription answers with the signing secret; it is not shown
+// again.
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WebhookHandler")
+
+	ctx, span := tracer.Start(r.Context(), "CreateSubscription-Handler")
+
+	defer span.End()
+
+	var subscriptionReq models.WebhookSubscriptionRequest
+
+	if err := readJSON(r, &subscriptionReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	subscription, err := h.service.CreateSubscription(ctx, &subscriptionReq)
+
+	if err != nil {
+		log.Println("Error Creating Webhook Subscription: ", err)
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WebhookHandler")
+
+	ctx, span := tracer.Start(r.Context(), "UpdateSubscription-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	var subscriptionReq models.WebhookSubscriptionRequest
+
+	if err := readJSON(r, &subscriptionReq); err != nil {
+		log.Println("Error while Unmarshalling Request body  ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	subscription, err := h.service.UpdateSubscription(ctx, id, &subscriptionReq)
+
+	if err != nil {
+		log.Println("Error Updating Webhook Subscription: ", err)
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WebhookHandler")
+
+	ctx, span := tracer.Start(r.Context(), "DeleteSubscription-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	subscription, err := h.service.DeleteSubscription(ctx, id)
+
+	if err != nil {
+		log.Println("Error Deleting Webhook Subscription: ", err)
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscription)
+}
+
+// GetDeliveries lists the subscription's recent deliveries; ?status= keeps
+// only pending, delivered or failed ones.
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WebhookHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetDeliveries-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	deliveries, err := h.service.GetDeliveries(ctx, id, r.URL.Query().Get("status"))
+
+	if err != nil {
+		log.Println("Error Getting Webhook Deliveries: ", err)
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// GetDeliveryById returns the delivery with the log of its attempts.
+func (h *WebhookHandler) GetDeliveryById(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WebhookHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetDeliveryById-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	delivery, err := h.service.GetDeliveryById(ctx, id)
+
+	if err != nil {
+		log.Println("Error Getting Webhook Delivery: ", err)
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, delivery)
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WebhookHandler")
+
+	ctx, span := tracer.Start(r.Context(), "Redeliver-Handler")
+
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	delivery, err := h.service.Redeliver(ctx, id)
+
+	if err != nil {
+		log.Println("Error Redelivering Webhook: ", err)
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, delivery)
+}
+
+// writeWebhookError answers 404 for unknown subscriptions and deliveries,
+// 400 for a bad status filter or a non-public URL and 500 otherwise.
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrWebhookSubscriptionNotFound),
+		errors.Is(err, models.ErrWebhookDeliveryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrInvalidWebhookStatus),
+		errors.Is(err, models.ErrWebhookTargetNotAllowed):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
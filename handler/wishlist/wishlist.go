@@ -0,0 +1,104 @@
+package wishlist
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type WishlistHandler struct {
+	service service.WishlistServiceInterface
+}
+
+func NewWishlistHandler(service service.WishlistServiceInterface) *WishlistHandler {
+	return &WishlistHandler{service: service}
+}
+
+func (h *WishlistHandler) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WishlistHandler")
+
+	ctx, span := tracer.Start(r.Context(), "GetWishlist-Handler")
+
+	defer span.End()
+
+	items, err := h.service.GetWishlist(ctx, middleware.Username(ctx))
+
+	if err != nil {
+		log.Println("Error Getting Wishlist: ", err)
+		writeWishlistError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+// AddToWishlist favorites the car in the path. Adding it again is a no-op.
+func (h *WishlistHandler) AddToWishlist(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WishlistHandler")
+
+	ctx, span := tracer.Start(r.Context(), "AddToWishlist-Handler")
+
+	defer span.End()
+
+	carID := mux.Vars(r)["carId"]
+
+	item, err := h.service.AddToWishlist(ctx, middleware.Username(ctx), carID)
+
+	if err != nil {
+		log.Println("Error Adding to Wishlist: ", err)
+		writeWishlistError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (h *WishlistHandler) RemoveFromWishlist(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WishlistHandler")
+
+	ctx, span := tracer.Start(r.Context(), "RemoveFromWishlist-Handler")
+
+	defer span.End()
+
+	carID := mux.Vars(r)["carId"]
+
+	if err := h.service.RemoveFromWishlist(ctx, middleware.Username(ctx), carID); err != nil {
+		log.Println("Error Removing from Wishlist: ", err)
+		writeWishlistError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeWishlistError answers 404 for unknown cars and cars not in the
+// wishlist and 500 otherwise.
+func writeWishlistError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrCarNotFound) || errors.Is(err, models.ErrWishlistItemNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBody, err := json.Marshal(v)
+
+	if err != nil {
+		log.Println("Error while marshalling: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(responseBody)
+}
@@ -8,20 +8,74 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/NhutNam2904/carzone/driver"
+	"github.com/NhutNam2904/carzone/gateway"
 	"github.com/gorilla/mux"
 
+	auditHandler "github.com/NhutNam2904/carzone/handler/audit"
+	brandHandler "github.com/NhutNam2904/carzone/handler/brand"
 	carHandler "github.com/NhutNam2904/carzone/handler/car"
+	dealershipHandler "github.com/NhutNam2904/carzone/handler/dealership"
 	engineHandler "github.com/NhutNam2904/carzone/handler/engine"
+	exchangeRateHandler "github.com/NhutNam2904/carzone/handler/exchangerate"
+	financingHandler "github.com/NhutNam2904/carzone/handler/financing"
+	inventoryHandler "github.com/NhutNam2904/carzone/handler/inventory"
+	notificationHandler "github.com/NhutNam2904/carzone/handler/notification"
+	orderHandler "github.com/NhutNam2904/carzone/handler/order"
+	paymentHandler "github.com/NhutNam2904/carzone/handler/payment"
+	promotionHandler "github.com/NhutNam2904/carzone/handler/promotion"
+	reservationHandler "github.com/NhutNam2904/carzone/handler/reservation"
+	savedSearchHandler "github.com/NhutNam2904/carzone/handler/savedsearch"
+	taxHandler "github.com/NhutNam2904/carzone/handler/tax"
+	testDriveHandler "github.com/NhutNam2904/carzone/handler/testdrive"
+	valuationHandler "github.com/NhutNam2904/carzone/handler/valuation"
+	webhookHandler "github.com/NhutNam2904/carzone/handler/webhook"
+	wishlistHandler "github.com/NhutNam2904/carzone/handler/wishlist"
 
 	//loginHandler "github.com/NhutNam2904/carzone/handler/login"
 
-	//middleware "github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/middleware"
+	"github.com/NhutNam2904/carzone/notify"
+	auditService "github.com/NhutNam2904/carzone/service/audit"
+	brandService "github.com/NhutNam2904/carzone/service/brand"
 	carService "github.com/NhutNam2904/carzone/service/car"
+	dealershipService "github.com/NhutNam2904/carzone/service/dealership"
 	engineService "github.com/NhutNam2904/carzone/service/engine"
+	exchangeRateService "github.com/NhutNam2904/carzone/service/exchangerate"
+	financingService "github.com/NhutNam2904/carzone/service/financing"
+	inventoryService "github.com/NhutNam2904/carzone/service/inventory"
+	notificationService "github.com/NhutNam2904/carzone/service/notification"
+	orderService "github.com/NhutNam2904/carzone/service/order"
+	paymentService "github.com/NhutNam2904/carzone/service/payment"
+	promotionService "github.com/NhutNam2904/carzone/service/promotion"
+	reservationService "github.com/NhutNam2904/carzone/service/reservation"
+	savedSearchService "github.com/NhutNam2904/carzone/service/savedsearch"
+	taxService "github.com/NhutNam2904/carzone/service/tax"
+	testDriveService "github.com/NhutNam2904/carzone/service/testdrive"
+	valuationService "github.com/NhutNam2904/carzone/service/valuation"
+	webhookService "github.com/NhutNam2904/carzone/service/webhook"
+	wishlistService "github.com/NhutNam2904/carzone/service/wishlist"
+	auditStore "github.com/NhutNam2904/carzone/store/audit"
+	brandStore "github.com/NhutNam2904/carzone/store/brand"
 	carStore "github.com/NhutNam2904/carzone/store/car"
+	dealershipStore "github.com/NhutNam2904/carzone/store/dealership"
 	engineStore "github.com/NhutNam2904/carzone/store/engine"
+	exchangeRateStore "github.com/NhutNam2904/carzone/store/exchangerate"
+	financingStore "github.com/NhutNam2904/carzone/store/financing"
+	inventoryStore "github.com/NhutNam2904/carzone/store/inventory"
+	notificationStore "github.com/NhutNam2904/carzone/store/notification"
+	orderStore "github.com/NhutNam2904/carzone/store/order"
+	paymentStore "github.com/NhutNam2904/carzone/store/payment"
+	promotionStore "github.com/NhutNam2904/carzone/store/promotion"
+	reservationStore "github.com/NhutNam2904/carzone/store/reservation"
+	savedSearchStore "github.com/NhutNam2904/carzone/store/savedsearch"
+	taxStore "github.com/NhutNam2904/carzone/store/tax"
+	testDriveStore "github.com/NhutNam2904/carzone/store/testdrive"
+	valuationStore "github.com/NhutNam2904/carzone/store/valuation"
+	webhookStore "github.com/NhutNam2904/carzone/store/webhook"
+	wishlistStore "github.com/NhutNam2904/carzone/store/wishlist"
 	"github.com/joho/godotenv"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
@@ -39,7 +93,7 @@ func main() {
 	traceProvider, err := startTracing()
 
 	if err != nil {
-		log.Fatal("Failed to Start Tracing : %v", err)
+		log.Fatalf("Failed to Start Tracing : %v", err)
 	}
 
 	defer func() {
@@ -59,19 +113,100 @@ func main() {
 	//loginStore := loginStore.New(db, rd)
 	//loginService := loginService.NewLoginService(loginStore)
 
+	exchangeRateStore := exchangeRateStore.New(db, rd)
+	exchangeRateService := exchangeRateService.NewExchangeRateService(exchangeRateStore)
+
 	carStore := carStore.New(db, rd)
-	carService := carService.NewCarService(carStore)
+
+	inventoryStore := inventoryStore.New(db)
+	inventoryService := inventoryService.NewInventoryService(inventoryStore, carStore)
+
+	promotionStore := promotionStore.New(db)
+	promotionService := promotionService.NewPromotionService(promotionStore)
+
+	taxStore := taxStore.New(db)
+	taxService := taxService.NewTaxService(taxStore, exchangeRateService)
+
+	carService := carService.NewCarService(carStore, exchangeRateService, inventoryService, promotionService, taxService)
+
+	reservationStore := reservationStore.New(db, rd)
+	reservationService := reservationService.NewReservationService(reservationStore)
+
+	orderStore := orderStore.New(db, rd)
+	orderService := orderService.NewOrderService(orderStore, carService, inventoryService, taxService)
+
+	// Only the in-memory fake gateway exists so far.
+	paymentGateway := gateway.NewFakeGateway(os.Getenv("PAYMENT_WEBHOOK_SECRET"), os.Getenv("PAYMENT_WEBHOOK_URL"))
+	paymentStore := paymentStore.New(db)
+	paymentService := paymentService.NewPaymentService(paymentStore, orderStore, paymentGateway)
+
+	financingStore := financingStore.New(db)
+	financingService := financingService.NewFinancingService(financingStore, carStore)
+
+	valuationStore := valuationStore.New(db)
+	valuationService := valuationService.NewValuationService(valuationStore, exchangeRateService)
 
 	engineStore := engineStore.New(db)
 	engineService := engineService.NewEngineService(engineStore)
 
+	brandStore := brandStore.New(db)
+	brandService := brandService.NewBrandService(brandStore, carStore)
+
+	dealershipStore := dealershipStore.New(db)
+	dealershipService := dealershipService.NewDealershipService(dealershipStore)
+
+	testDriveStore := testDriveStore.New(db)
+	testDriveService := testDriveService.NewTestDriveService(testDriveStore, dealershipStore)
+
+	wishlistStore := wishlistStore.New(db)
+	wishlistService := wishlistService.NewWishlistService(wishlistStore, carStore)
+
+	savedSearchStore := savedSearchStore.New(db)
+	savedSearchService := savedSearchService.NewSavedSearchService(savedSearchStore, carStore)
+
+	notificationStore := notificationStore.New(db)
+	notificationChannels := []notify.Channel{notify.NewInAppChannel(notificationStore)}
+	if smtpAddr := os.Getenv("SMTP_ADDR"); smtpAddr != "" {
+		smtpChannel, err := notify.NewSMTPChannel(smtpAddr, os.Getenv("SMTP_FROM"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+		if err != nil {
+			log.Fatalf("Invalid SMTP configuration: %v", err)
+		}
+		notificationChannels = append(notificationChannels, smtpChannel)
+	}
+	notificationService := notificationService.NewNotificationService(notificationStore, notificationChannels...)
+
+	webhookStore := webhookStore.New(db)
+	webhookService := webhookService.NewWebhookService(webhookStore)
+
+	auditStore := auditStore.New(db)
+	auditService := auditService.NewAuditService(auditStore)
+
 	carHandler := carHandler.NewCarHandler(carService)
 	engineHandler := engineHandler.NewEngineHandler(engineService)
+	brandHandler := brandHandler.NewBrandHandler(brandService)
+	dealershipHandler := dealershipHandler.NewDealershipHandler(dealershipService)
+	inventoryHandler := inventoryHandler.NewInventoryHandler(inventoryService)
+	reservationHandler := reservationHandler.NewReservationHandler(reservationService)
+	testDriveHandler := testDriveHandler.NewTestDriveHandler(testDriveService)
+	orderHandler := orderHandler.NewOrderHandler(orderService)
+	paymentHandler := paymentHandler.NewPaymentHandler(paymentService)
+	financingHandler := financingHandler.NewFinancingHandler(financingService)
+	promotionHandler := promotionHandler.NewPromotionHandler(promotionService)
+	valuationHandler := valuationHandler.NewValuationHandler(valuationService)
+	exchangeRateHandler := exchangeRateHandler.NewExchangeRateHandler(exchangeRateService)
+	taxHandler := taxHandler.NewTaxHandler(taxService)
+	wishlistHandler := wishlistHandler.NewWishlistHandler(wishlistService)
+	savedSearchHandler := savedSearchHandler.NewSavedSearchHandler(savedSearchService)
+	notificationHandler := notificationHandler.NewNotificationHandler(notificationService)
+	webhookHandler := webhookHandler.NewWebhookHandler(webhookService)
+	auditHandler := auditHandler.NewAuditHandler(auditService)
 	//loginHandler := loginHandler.NewLoginHandler(loginService)
 
 	router := mux.NewRouter()
 
 	router.Use(otelmux.Middleware("CarZone"))
+	router.Use(middleware.RequestIDMiddleware)
+	router.Use(middleware.OptionalAuthMiddleware)
 
 	schemaFile := "store/schema.sql"
 
@@ -79,22 +214,183 @@ func main() {
 		log.Fatal("Error while executing the schema file: ", err)
 	}
 
+	// The Redis suggest index is derived from the car table, which may have
+	// been seeded or changed while the service was down.
+	if err := carStore.RebuildSuggestIndex(context.Background()); err != nil {
+		log.Println("Error while rebuilding the suggest index: ", err)
+	}
+
+	if ratesFile := os.Getenv("EXCHANGE_RATES_FILE"); ratesFile != "" {
+		if err := exchangeRateService.LoadRatesFromFile(context.Background(), ratesFile); err != nil {
+			log.Println("Error while loading exchange rates: ", err)
+		}
+	}
+
+	go reservationService.RunExpiryWorker(context.Background(), time.Minute)
+	go paymentService.RunReconcileWorker(context.Background(), 5*time.Minute)
+	go savedSearchService.RunEvaluator(context.Background(), time.Minute)
+	go notificationService.RunScheduler(context.Background(), 5*time.Minute)
+	go notificationService.RunDeliveryWorker(context.Background(), 30*time.Second)
+	go webhookService.RunWorker(context.Background(), 15*time.Second)
+
 	//router.HandleFunc("/login", loginHandler.LoginHandlerUsernamePassowrd).Methods("POST")
 
 	//router := router.PathPrefix("/").Subrouter()
 	//router.Use(middleware.AuthMiddleware)
 
+	router.HandleFunc("/cars/search", carHandler.SearchCars).Methods("GET")
 	router.HandleFunc("/cars/{id}", carHandler.GetCarByID).Methods("GET")
 	router.HandleFunc("/cars", carHandler.GetCarByBrand).Methods("GET")
 	router.HandleFunc("/cars", carHandler.CreateCar).Methods("POST")
 	router.HandleFunc("/cars/{id}", carHandler.UpdateCar).Methods("PUT")
 	router.HandleFunc("/cars/{id}", carHandler.DeleteCar).Methods("DELETE")
+	router.HandleFunc("/cars/{id}/price-history", carHandler.GetPriceHistory).Methods("GET")
+	router.HandleFunc("/cars/{id}/financing-quote", financingHandler.QuoteFinancing).Methods("POST")
+	router.HandleFunc("/suggest", carHandler.Suggest).Methods("GET")
+
+	router.HandleFunc("/lender-products", financingHandler.GetLenderProducts).Methods("GET")
+	router.HandleFunc("/lender-products/{id}", financingHandler.GetLenderProductByID).Methods("GET")
 
 	router.HandleFunc("/engine/{id}", engineHandler.GetEngineByID).Methods("GET")
 	router.HandleFunc("/engine", engineHandler.CreateEngine).Methods("POST")
 	router.HandleFunc("/engine/{id}", engineHandler.EngineUpdate).Methods("PUT")
 	router.HandleFunc("/engine/{id}", engineHandler.DeleteEngine).Methods("DELETE")
 
+	router.HandleFunc("/brands", brandHandler.GetBrands).Methods("GET")
+	router.HandleFunc("/brands/{id}", brandHandler.GetBrandByID).Methods("GET")
+	router.HandleFunc("/brands", brandHandler.CreateBrand).Methods("POST")
+	router.HandleFunc("/brands/{id}", brandHandler.UpdateBrand).Methods("PUT")
+	router.HandleFunc("/brands/{id}", brandHandler.DeleteBrand).Methods("DELETE")
+	router.HandleFunc("/brands/{id}/models", brandHandler.GetModelsByBrand).Methods("GET")
+	router.HandleFunc("/brands/{id}/models", brandHandler.CreateModel).Methods("POST")
+
+	router.HandleFunc("/models/{id}", brandHandler.GetModelByID).Methods("GET")
+	router.HandleFunc("/models/{id}", brandHandler.UpdateModel).Methods("PUT")
+	router.HandleFunc("/models/{id}", brandHandler.DeleteModel).Methods("DELETE")
+
+	router.HandleFunc("/dealerships", dealershipHandler.GetDealerships).Methods("GET")
+	router.HandleFunc("/dealerships/{id}", dealershipHandler.GetDealershipByID).Methods("GET")
+	router.HandleFunc("/dealerships", dealershipHandler.CreateDealership).Methods("POST")
+	router.HandleFunc("/dealerships/{id}", dealershipHandler.UpdateDealership).Methods("PUT")
+	router.HandleFunc("/dealerships/{id}", dealershipHandler.DeleteDealership).Methods("DELETE")
+	router.HandleFunc("/dealerships/{id}/test-drive-slots", testDriveHandler.GetSlots).Methods("GET")
+
+	router.HandleFunc("/promotions", promotionHandler.GetPromotions).Methods("GET")
+	router.HandleFunc("/promotions/{id}", promotionHandler.GetPromotionByID).Methods("GET")
+
+	router.HandleFunc("/trade-in/valuation", valuationHandler.ValueTradeIn).Methods("POST")
+
+	router.HandleFunc("/depreciation-curves", valuationHandler.GetCurves).Methods("GET")
+	router.HandleFunc("/depreciation-curves/{id}", valuationHandler.GetCurveByID).Methods("GET")
+
+	// staffOnly guards routes outside the authenticated subrouters.
+	staffOnly := func(handler http.HandlerFunc) http.Handler {
+		return middleware.AuthMiddleware(middleware.RequireRole(middleware.RoleStaff)(handler))
+	}
+
+	router.HandleFunc("/cars/{id}/units", inventoryHandler.GetUnitsByCar).Methods("GET")
+	router.Handle("/cars/{id}/units", staffOnly(inventoryHandler.CreateUnit)).Methods("POST")
+	router.HandleFunc("/units/{id}", inventoryHandler.GetUnitByID).Methods("GET")
+	router.Handle("/units/{id}", staffOnly(inventoryHandler.UpdateUnit)).Methods("PUT")
+	router.Handle("/units/{id}", staffOnly(inventoryHandler.DeleteUnit)).Methods("DELETE")
+	router.HandleFunc("/vin/{vin}", inventoryHandler.DecodeVIN).Methods("GET")
+
+	reservationRouter := router.PathPrefix("/reservations").Subrouter()
+	reservationRouter.Use(middleware.AuthMiddleware)
+	reservationRouter.HandleFunc("", reservationHandler.CreateReservation).Methods("POST")
+	reservationRouter.HandleFunc("", reservationHandler.GetReservations).Methods("GET")
+	reservationRouter.HandleFunc("/{id}", reservationHandler.GetReservationByID).Methods("GET")
+	reservationRouter.HandleFunc("/{id}/cancel", reservationHandler.CancelReservation).Methods("POST")
+
+	testDriveRouter := router.PathPrefix("/test-drives").Subrouter()
+	testDriveRouter.Use(middleware.AuthMiddleware)
+	testDriveRouter.HandleFunc("", testDriveHandler.CreateTestDrive).Methods("POST")
+	testDriveRouter.HandleFunc("", testDriveHandler.GetTestDrives).Methods("GET")
+	testDriveRouter.HandleFunc("/{id}", testDriveHandler.GetTestDriveByID).Methods("GET")
+	testDriveRouter.HandleFunc("/{id}", testDriveHandler.RescheduleTestDrive).Methods("PUT")
+	testDriveRouter.HandleFunc("/{id}/cancel", testDriveHandler.CancelTestDrive).Methods("POST")
+	testDriveRouter.Handle("/{id}/status", middleware.RequireRole(middleware.RoleStaff)(http.HandlerFunc(testDriveHandler.UpdateTestDriveStatus))).Methods("PUT")
+
+	orderRouter := router.PathPrefix("/orders").Subrouter()
+	orderRouter.Use(middleware.AuthMiddleware)
+	orderRouter.HandleFunc("", orderHandler.CreateOrder).Methods("POST")
+	orderRouter.HandleFunc("", orderHandler.GetOrders).Methods("GET")
+	orderRouter.HandleFunc("/{id}", orderHandler.GetOrderByID).Methods("GET")
+	orderRouter.HandleFunc("/{id}/status", orderHandler.UpdateOrderStatus).Methods("PUT")
+	orderRouter.Handle("/{id}/discount", middleware.RequireRole(middleware.RoleStaff)(http.HandlerFunc(orderHandler.SetOrderDiscount))).Methods("PUT")
+	orderRouter.HandleFunc("/{id}/payments", paymentHandler.CreatePayment).Methods("POST")
+	orderRouter.HandleFunc("/{id}/payments", paymentHandler.GetPaymentsByOrder).Methods("GET")
+	router.Handle("/dealerships/{id}/orders", staffOnly(orderHandler.GetOrdersByDealership)).Methods("GET")
+
+	// The webhook is signed by the provider rather than authenticated, so
+	// it is registered before the authenticated /payments routes.
+	router.HandleFunc("/payments/webhook", paymentHandler.Webhook).Methods("POST")
+
+	paymentRouter := router.PathPrefix("/payments").Subrouter()
+	paymentRouter.Use(middleware.AuthMiddleware)
+	paymentRouter.HandleFunc("/{id}", paymentHandler.GetPaymentByID).Methods("GET")
+	paymentRouter.Handle("/{id}/capture", middleware.RequireRole(middleware.RoleStaff)(http.HandlerFunc(paymentHandler.CapturePayment))).Methods("POST")
+	paymentRouter.Handle("/{id}/refund", middleware.RequireRole(middleware.RoleStaff)(http.HandlerFunc(paymentHandler.RefundPayment))).Methods("POST")
+
+	wishlistRouter := router.PathPrefix("/wishlist").Subrouter()
+	wishlistRouter.Use(middleware.AuthMiddleware)
+	wishlistRouter.HandleFunc("", wishlistHandler.GetWishlist).Methods("GET")
+	wishlistRouter.HandleFunc("/{carId}", wishlistHandler.AddToWishlist).Methods("PUT")
+	wishlistRouter.HandleFunc("/{carId}", wishlistHandler.RemoveFromWishlist).Methods("DELETE")
+
+	savedSearchRouter := router.PathPrefix("/saved-searches").Subrouter()
+	savedSearchRouter.Use(middleware.AuthMiddleware)
+	savedSearchRouter.HandleFunc("", savedSearchHandler.CreateSavedSearch).Methods("POST")
+	savedSearchRouter.HandleFunc("", savedSearchHandler.GetSavedSearches).Methods("GET")
+	savedSearchRouter.HandleFunc("/{id}", savedSearchHandler.GetSavedSearchByID).Methods("GET")
+	savedSearchRouter.HandleFunc("/{id}", savedSearchHandler.UpdateSavedSearch).Methods("PUT")
+	savedSearchRouter.HandleFunc("/{id}", savedSearchHandler.DeleteSavedSearch).Methods("DELETE")
+	savedSearchRouter.HandleFunc("/{id}/matches", savedSearchHandler.GetMatches).Methods("GET")
+
+	notificationRouter := router.PathPrefix("/notifications").Subrouter()
+	notificationRouter.Use(middleware.AuthMiddleware)
+	notificationRouter.HandleFunc("", notificationHandler.GetInbox).Methods("GET")
+	notificationRouter.HandleFunc("/preferences", notificationHandler.GetPreferences).Methods("GET")
+	notificationRouter.HandleFunc("/preferences", notificationHandler.UpdatePreferences).Methods("PUT")
+	notificationRouter.HandleFunc("/{id}/read", notificationHandler.MarkRead).Methods("POST")
+
+	router.HandleFunc("/exchange-rates", exchangeRateHandler.GetRates).Methods("GET")
+
+	router.HandleFunc("/tax-regions", taxHandler.GetTaxRegions).Methods("GET")
+	router.HandleFunc("/tax-regions/{code}", taxHandler.GetTaxRegion).Methods("GET")
+	router.HandleFunc("/sct-rules", taxHandler.GetSCTRules).Methods("GET")
+
+	adminRouter := router.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(middleware.AuthMiddleware)
+	adminRouter.Use(middleware.RequireRole(middleware.RoleAdmin))
+	adminRouter.HandleFunc("/exchange-rates", exchangeRateHandler.UpsertRates).Methods("PUT")
+	adminRouter.HandleFunc("/audit", auditHandler.GetEntries).Methods("GET")
+	adminRouter.HandleFunc("/notification-templates", notificationHandler.GetTemplates).Methods("GET")
+	adminRouter.HandleFunc("/notification-templates/{kind}", notificationHandler.UpsertTemplate).Methods("PUT")
+	adminRouter.HandleFunc("/notification-deliveries", notificationHandler.GetDeliveries).Methods("GET")
+	adminRouter.HandleFunc("/tax-regions/{code}", taxHandler.UpsertTaxRegion).Methods("PUT")
+	adminRouter.HandleFunc("/tax-regions/{code}", taxHandler.DeleteTaxRegion).Methods("DELETE")
+	adminRouter.HandleFunc("/sct-rules", taxHandler.CreateSCTRule).Methods("POST")
+	adminRouter.HandleFunc("/sct-rules/{id}", taxHandler.UpdateSCTRule).Methods("PUT")
+	adminRouter.HandleFunc("/sct-rules/{id}", taxHandler.DeleteSCTRule).Methods("DELETE")
+	adminRouter.HandleFunc("/webhooks", webhookHandler.GetSubscriptions).Methods("GET")
+	adminRouter.HandleFunc("/webhooks", webhookHandler.CreateSubscription).Methods("POST")
+	adminRouter.HandleFunc("/webhooks/{id}", webhookHandler.GetSubscriptionById).Methods("GET")
+	adminRouter.HandleFunc("/webhooks/{id}", webhookHandler.UpdateSubscription).Methods("PUT")
+	adminRouter.HandleFunc("/webhooks/{id}", webhookHandler.DeleteSubscription).Methods("DELETE")
+	adminRouter.HandleFunc("/webhooks/{id}/deliveries", webhookHandler.GetDeliveries).Methods("GET")
+	adminRouter.HandleFunc("/webhook-deliveries/{id}", webhookHandler.GetDeliveryById).Methods("GET")
+	adminRouter.HandleFunc("/webhook-deliveries/{id}/redeliver", webhookHandler.Redeliver).Methods("POST")
+	adminRouter.HandleFunc("/lender-products", financingHandler.CreateLenderProduct).Methods("POST")
+	adminRouter.HandleFunc("/lender-products/{id}", financingHandler.UpdateLenderProduct).Methods("PUT")
+	adminRouter.HandleFunc("/lender-products/{id}", financingHandler.DeleteLenderProduct).Methods("DELETE")
+	adminRouter.HandleFunc("/depreciation-curves", valuationHandler.CreateCurve).Methods("POST")
+	adminRouter.HandleFunc("/depreciation-curves/{id}", valuationHandler.UpdateCurve).Methods("PUT")
+	adminRouter.HandleFunc("/depreciation-curves/{id}", valuationHandler.DeleteCurve).Methods("DELETE")
+	adminRouter.HandleFunc("/promotions", promotionHandler.CreatePromotion).Methods("POST")
+	adminRouter.HandleFunc("/promotions/{id}", promotionHandler.UpdatePromotion).Methods("PUT")
+	adminRouter.HandleFunc("/promotions/{id}", promotionHandler.DeletePromotion).Methods("DELETE")
+
 	//
 
 	port := os.Getenv("PORT")
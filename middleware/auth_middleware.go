@@ -9,11 +9,21 @@ import (
 	"github.com/dgrijalva/jwt-go"
 )
 
+// Claims is the JWT payload. Role is empty for customers and names the
+// staff role otherwise.
 type Claims struct {
 	UserName string `json:"username"`
+	Role     string `json:"role,omitempty"`
 	jwt.StandardClaims
 }
 
+type contextKey string
+
+const (
+	usernameKey contextKey = "username"
+	roleKey     contextKey = "role"
+)
+
 var jwtKey = []byte("some_value")
 
 func AuthMiddleware(next http.Handler) http.Handler {
@@ -25,21 +35,76 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer")
-		tokenString = strings.TrimSpace(tokenString)
+		claims, err := parseToken(authHeader)
+
+		if err != nil {
+			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			log.Println("Invalid or expired token: ", err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
+	})
+}
+
+// OptionalAuthMiddleware identifies the caller when a token is sent but lets
+// anonymous requests through, so open endpoints can still attribute changes
+// to a user. A token that is present but invalid is rejected.
+func OptionalAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
+		claims, err := parseToken(authHeader)
 
-		if err != nil || !token.Valid {
+		if err != nil {
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			log.Println("Invalid or expired token: ", err)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), "username", claims.UserName)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
 	})
 }
+
+// Username returns the authenticated user set by AuthMiddleware or
+// OptionalAuthMiddleware, or "" for anonymous requests.
+func Username(ctx context.Context) string {
+	username, _ := ctx.Value(usernameKey).(string)
+	return username
+}
+
+// Role returns the role of the authenticated user, or "" for customers and
+// anonymous requests.
+func Role(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey).(string)
+	return role
+}
+
+func withClaims(ctx context.Context, claims *Claims) context.Context {
+	ctx = context.WithValue(ctx, usernameKey, claims.UserName)
+	return context.WithValue(ctx, roleKey, claims.Role)
+}
+
+func parseToken(authHeader string) (*Claims, error) {
+	tokenString := strings.TrimPrefix(authHeader, "Bearer")
+	tokenString = strings.TrimSpace(tokenString)
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return jwtKey, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.NewValidationError("token is not valid", jwt.ValidationErrorNotValidYet)
+	}
+
+	return claims, nil
+}
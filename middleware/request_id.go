@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+)
+
+const requestIDKey contextKey = "request_id"
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware tags every request with an ID, reusing the caller's
+// X-Request-ID when sent, and echoes it on the response.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := context.WithValue(r.Context(), requestIDKey, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// Actor is the user and request ID that audited changes are attributed to.
+func Actor(ctx context.Context) models.AuditActor {
+	return models.AuditActor{Username: Username(ctx), RequestID: RequestID(ctx)}
+}
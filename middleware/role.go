@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// Roles carried in the token of staff users. Customers have no role.
+const (
+	RoleAdmin = "admin"
+	RoleStaff = "staff"
+)
+
+// HasRole reports whether role is one of roles. Admins pass every check.
+func HasRole(role string, roles ...string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole lets a request through only when its user has one of the
+// roles. It expects AuthMiddleware to run first.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			if Username(r.Context()) == "" {
+				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				return
+			}
+
+			if !HasRole(Role(r.Context()), roles...) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrBrandNameTaken is returned when another brand already has the name,
+// compared case-insensitively.
+var ErrBrandNameTaken = errors.New("a brand with this name already exists")
+
+type Brand struct {
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	CountryOfOrigin string    `json:"country_of_origin"`
+	LogoURL         string    `json:"logo_url"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type BrandRequest struct {
+	Name            string `json:"name"`
+	CountryOfOrigin string `json:"country_of_origin"`
+	LogoURL         string `json:"logo_url"`
+}
+
+type CarModel struct {
+	ID        uuid.UUID `json:"id"`
+	BrandID   uuid.UUID `json:"brand_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CarModelRequest struct {
+	Name string `json:"name"`
+}
+
+// NormalizeBrandName trims and collapses whitespace so "Toyota " and
+// " Toyota" resolve to the same catalog entry. Case is kept as entered;
+// uniqueness is enforced case-insensitively by the database.
+func NormalizeBrandName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// ModelNameFromCarName strips a leading brand name from a car name, so
+// "Honda Civic" under brand "Honda" becomes the model "Civic".
+func ModelNameFromCarName(carName, brandName string) string {
+	carName = NormalizeBrandName(carName)
+	brandName = NormalizeBrandName(brandName)
+
+	if brandName != "" && len(carName) > len(brandName) &&
+		strings.EqualFold(carName[:len(brandName)+1], brandName+" ") {
+		return carName[len(brandName)+1:]
+	}
+	return carName
+}
+
+func ValidateBrandRequest(brandRequest BrandRequest) error {
+	if NormalizeBrandName(brandRequest.Name) == "" {
+		return errors.New("Brand name is Required")
+	}
+	return nil
+}
+
+func ValidateCarModelRequest(modelRequest CarModelRequest) error {
+	if NormalizeBrandName(modelRequest.Name) == "" {
+		return errors.New("Model name is Required")
+	}
+	return nil
+}
@@ -2,31 +2,62 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrCarNotFound is returned by services that need an existing car.
+var ErrCarNotFound = errors.New("car not found")
+
 type Car struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Year      string    `json:"year"`
-	Brand     string    `json:"brand"`
-	FuelType  string    `json:"fuel_type"`
-	Engine    Engine    `json:"engine"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Year    string    `json:"year"`
+	Brand   string    `json:"brand"`
+	BrandID uuid.UUID `json:"brand_id"`
+	ModelID uuid.UUID `json:"model_id"`
+
+	DealershipID *uuid.UUID `json:"dealership_id,omitempty"`
+	FuelType     FuelType   `json:"fuel_type"`
+	Engine       Engine     `json:"engine"`
+	Price        Money      `json:"price"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	DisplayPrice *ConvertedPrice `json:"display_price,omitempty"`
+
+	// DistanceKm is set on listings filtered with ?near=.
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+
+	Availability *Availability `json:"availability,omitempty"`
+
+	// EffectivePrice is Price after the promotions listed in Promotions.
+	EffectivePrice *Money             `json:"effective_price,omitempty"`
+	Promotions     []AppliedPromotion `json:"promotions,omitempty"`
+
+	// OnRoadPrices is set on car detail, one per tax region.
+	OnRoadPrices []OnRoadPrice `json:"on_road_prices,omitempty"`
+}
+
+type CarSearchResult struct {
+	Car
+	Rank float64 `json:"rank"`
 }
 
 type CarRequest struct {
-	Name     string  `json:"name"`
-	Year     string  `json:"year"`
-	Brand    string  `json:"brand"`
-	FuelType string  `json:"fuel_type"`
-	Engine   Engine  `json:"engine"`
-	Price    float64 `json:"price"`
+	Name    string     `json:"name"`
+	Year    string     `json:"year"`
+	Brand   string     `json:"brand"`
+	BrandID *uuid.UUID `json:"brand_id,omitempty"`
+	ModelID *uuid.UUID `json:"model_id,omitempty"`
+
+	DealershipID *uuid.UUID `json:"dealership_id,omitempty"`
+	FuelType     FuelType   `json:"fuel_type"`
+	Engine       Engine     `json:"engine"`
+	Price        Money      `json:"price"`
 }
 
 func ValidateCarRequest(carRequest CarRequest) error {
@@ -35,15 +66,17 @@ func ValidateCarRequest(carRequest CarRequest) error {
 		return err
 	}
 
-	if err := validateBranch(carRequest.Brand); err != nil {
-		return err
+	if carRequest.BrandID == nil {
+		if err := validateBranch(carRequest.Brand); err != nil {
+			return err
+		}
 	}
 	if err := validateYear(carRequest.Year); err != nil {
 		return err
 	}
-	//if err := validateFueltype(carRequest.FuelType); err != nil {
-	//	return err
-	//}
+	if err := validateFueltype(carRequest.FuelType); err != nil {
+		return err
+	}
 	if err := validateEngine(carRequest.Engine); err != nil {
 		return err
 	}
@@ -81,50 +114,53 @@ func validateYear(year string) error {
 
 func validateBranch(branch string) error {
 
-	if branch == "" {
+	if NormalizeBrandName(branch) == "" {
 		return errors.New("Branch is Required")
 	}
 	return nil
 
 }
 
-func validateFueltype(fueltype string) error {
+func validateFueltype(fueltype FuelType) error {
 
-	fueltypes := []string{"Persol", "Diesel", "Electric", "Hybrid"}
+	if fueltype == "" {
+		return errors.New("FuelType is Required")
+	}
 
-	for _, a := range fueltypes {
-		if fueltype == a {
-			return nil
-		}
+	if !fueltype.IsValid() {
+		return errors.New("FuelType in: " + fuelTypeList())
 	}
 
-	return errors.New("FuelType in: Persol, Diesel, Electric, Hybrid")
+	return nil
 }
 
+// validateEngine only checks that an engine is referenced. The engine specs
+// in a request are not trusted; the store checks the stored engine with
+// ValidateCarEngine.
 func validateEngine(engine Engine) error {
 	if engine.EngineID == uuid.Nil {
 		return errors.New("EngineID is Required")
 	}
-	if engine.Displacement <= 0 {
-		return errors.New("Displacement must be greater than zero")
-	}
+	return nil
+}
 
-	if engine.NoOfCyclinders <= 0 {
-		return errors.New("noOfCyclinders must be greater than zero")
-	}
+// ValidateCarEngine checks a stored engine against the powertrain the car's
+// fuel type calls for: an electric car needs a battery and no displacement, a
+// petrol car the reverse, and so on.
+func ValidateCarEngine(engine Engine, fuelType FuelType) error {
+	expected := fuelType.Powertrain()
 
-	if engine.CarRange <= 0 {
-		return errors.New("carRange must be greater than zero")
+	if engine.Powertrain != expected {
+		return fmt.Errorf("Powertrain %s does not match fuel type %s, expected %s", engine.Powertrain, fuelType, expected)
 	}
 
-	return nil
-
+	return validatePowertrain(engine, fuelType == FuelTypePlugInHybrid)
 }
 
-func validateCarprice(carprice float64) error {
-	if carprice <= 0 {
+func validateCarprice(carprice Money) error {
+	if !carprice.IsPositive() {
 		return errors.New("carPrice is  unvalid")
 	}
-	return nil
+	return ValidateMoney(carprice)
 
 }
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// DefaultSearchRadiusKm applies when ?near= is given without ?radiusKm=.
+const DefaultSearchRadiusKm = 50
+
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// CarFilter holds the listing filters accepted on GET /cars.
+type CarFilter struct {
+	Brand    string
+	IsEngine bool
+
+	// DealershipID keeps only cars assigned to the given dealership.
+	DealershipID string
+
+	// Near and RadiusKm keep only cars at a dealership within RadiusKm of
+	// Near; results are then sorted nearest-first.
+	Near     *GeoPoint
+	RadiusKm float64
+
+	// ReducedWithinDays keeps only cars whose price was lowered in the
+	// last given number of days.
+	ReducedWithinDays int
+
+	// UpdatedSince keeps only cars created or changed after the given time.
+	UpdatedSince *time.Time
+}
+
+// IsBrandOnly reports whether the filter can be served by the cached
+// brand lookup.
+func (f CarFilter) IsBrandOnly() bool {
+	return f.ReducedWithinDays == 0 && f.DealershipID == "" && f.Near == nil && f.UpdatedSince == nil
+}
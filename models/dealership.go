@@ -0,0 +1,191 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Dealership struct {
+	ID           uuid.UUID    `json:"id"`
+	Name         string       `json:"name"`
+	Address      string       `json:"address"`
+	Latitude     float64      `json:"latitude"`
+	Longitude    float64      `json:"longitude"`
+	OpeningHours OpeningHours `json:"opening_hours"`
+	Phone        string       `json:"phone"`
+	Email        string       `json:"email"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+type DealershipRequest struct {
+	Name         string       `json:"name"`
+	Address      string       `json:"address"`
+	Latitude     float64      `json:"latitude"`
+	Longitude    float64      `json:"longitude"`
+	OpeningHours OpeningHours `json:"opening_hours"`
+	Phone        string       `json:"phone"`
+	Email        string       `json:"email"`
+}
+
+// DailyHours is the opening window of a dealership on one weekday, in
+// local "HH:MM" time. Days without an entry are closed.
+type DailyHours struct {
+	Day   string `json:"day"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// OpeningHours is stored as a JSONB column on dealership.
+type OpeningHours []DailyHours
+
+func (h OpeningHours) Value() (driver.Value, error) {
+	if h == nil {
+		h = OpeningHours{}
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (h *OpeningHours) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*h = OpeningHours{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, h)
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	default:
+		return fmt.Errorf("cannot scan %T into OpeningHours", src)
+	}
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+func ValidateDealershipRequest(dealershipRequest DealershipRequest) error {
+	if strings.TrimSpace(dealershipRequest.Name) == "" {
+		return errors.New("Dealership name is Required")
+	}
+	if strings.TrimSpace(dealershipRequest.Address) == "" {
+		return errors.New("Dealership address is Required")
+	}
+	if err := ValidateCoordinates(dealershipRequest.Latitude, dealershipRequest.Longitude); err != nil {
+		return err
+	}
+	if err := validateOpeningHours(dealershipRequest.OpeningHours); err != nil {
+		return err
+	}
+	if dealershipRequest.Phone == "" && dealershipRequest.Email == "" {
+		return errors.New("Dealership needs a phone or email contact")
+	}
+	if dealershipRequest.Email != "" && !strings.Contains(dealershipRequest.Email, "@") {
+		return errors.New("Dealership email is invalid")
+	}
+	return nil
+}
+
+func ValidateCoordinates(latitude, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return errors.New("Latitude must be between -90 and 90")
+	}
+	if longitude < -180 || longitude > 180 {
+		return errors.New("Longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+func validateOpeningHours(hours OpeningHours) error {
+	seen := make(map[string]bool)
+
+	for _, entry := range hours {
+		day := strings.ToLower(entry.Day)
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("Invalid opening day %q", entry.Day)
+		}
+		if seen[day] {
+			return fmt.Errorf("Opening hours for %s given twice", day)
+		}
+		seen[day] = true
+
+		open, err := time.Parse("15:04", entry.Open)
+		if err != nil {
+			return fmt.Errorf("Invalid opening time %q for %s", entry.Open, day)
+		}
+		closing, err := time.Parse("15:04", entry.Close)
+		if err != nil {
+			return fmt.Errorf("Invalid closing time %q for %s", entry.Close, day)
+		}
+		if !closing.After(open) {
+			return fmt.Errorf("Closing time must be after opening time for %s", day)
+		}
+	}
+	return nil
+}
+
+// NormalizeOpeningHours lowercases day names so lookups by weekday match.
+func NormalizeOpeningHours(hours OpeningHours) OpeningHours {
+	normalized := make(OpeningHours, 0, len(hours))
+	for _, entry := range hours {
+		entry.Day = strings.ToLower(entry.Day)
+		normalized = append(normalized, entry)
+	}
+	return normalized
+}
+
+// For returns the opening window on the given weekday, if the dealership
+// opens that day.
+func (h OpeningHours) For(day time.Weekday) (DailyHours, bool) {
+	for _, entry := range h {
+		if weekdays[strings.ToLower(entry.Day)] == day {
+			return entry, true
+		}
+	}
+	return DailyHours{}, false
+}
+
+// Slots splits the opening window of the given date into back-to-back slots
+// of the given length, in the location of date. Slots that would run past
+// closing time are dropped.
+func (h OpeningHours) Slots(date time.Time, length time.Duration) []TestDriveSlot {
+	entry, ok := h.For(date.Weekday())
+	if !ok {
+		return nil
+	}
+
+	open, err := time.Parse("15:04", entry.Open)
+	if err != nil {
+		return nil
+	}
+	closing, err := time.Parse("15:04", entry.Close)
+	if err != nil {
+		return nil
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	start := day.Add(time.Duration(open.Hour())*time.Hour + time.Duration(open.Minute())*time.Minute)
+	end := day.Add(time.Duration(closing.Hour())*time.Hour + time.Duration(closing.Minute())*time.Minute)
+
+	var slots []TestDriveSlot
+	for slotStart := start; !slotStart.Add(length).After(end); slotStart = slotStart.Add(length) {
+		slots = append(slots, TestDriveSlot{StartsAt: slotStart, EndsAt: slotStart.Add(length)})
+	}
+	return slots
+}
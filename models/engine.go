@@ -2,38 +2,227 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
 type Engine struct {
-	EngineID       uuid.UUID `json:"engine_id"`
-	Displacement   int64     `json:"displacement"`
-	NoOfCyclinders int64     `json:"noOfCyclinders"`
-	CarRange       int64     `json:"carRange"`
+	EngineID          uuid.UUID    `json:"engine_id"`
+	Displacement      int64        `json:"displacement"`
+	NoOfCyclinders    int64        `json:"noOfCyclinders"`
+	CarRange          int64        `json:"carRange"`
+	Powertrain        Powertrain   `json:"powertrain"`
+	BatteryKWh        float64      `json:"battery_kwh,omitempty"`
+	MotorKW           float64      `json:"motor_kw,omitempty"`
+	ChargingStandards []string     `json:"charging_standards,omitempty"`
+	WLTPRangeKm       int64        `json:"wltp_range_km,omitempty"`
+	PowerKW           float64      `json:"power_kw"`
+	TorqueNm          float64      `json:"torque_nm"`
+	Transmission      Transmission `json:"transmission"`
+	CO2GPerKm         float64      `json:"co2_g_per_km"`
 }
 
 type EngineRequest struct {
-	Displacement   int64 `json:"displacement"`
-	NoOfCyclinders int64 `json:"noOfCyclinders"`
-	CarRange       int64 `json:"carRange"`
+	Displacement      int64        `json:"displacement"`
+	NoOfCyclinders    int64        `json:"noOfCyclinders"`
+	CarRange          int64        `json:"carRange"`
+	Powertrain        Powertrain   `json:"powertrain"`
+	BatteryKWh        float64      `json:"battery_kwh"`
+	MotorKW           float64      `json:"motor_kw"`
+	ChargingStandards []string     `json:"charging_standards"`
+	WLTPRangeKm       int64        `json:"wltp_range_km"`
+	Power             Quantity     `json:"power"`
+	Torque            Quantity     `json:"torque"`
+	Transmission      Transmission `json:"transmission"`
+	CO2GPerKm         float64      `json:"co2_g_per_km"`
 }
 
 func ValidateEngineRequest(enginerequest EngineRequest) error {
 
-	if err := validateDisplacement(enginerequest.Displacement); err != nil {
+	if enginerequest.Powertrain == "" {
+		enginerequest.Powertrain = PowertrainCombustion
+	}
+
+	if err := validatePowertrain(enginerequest.spec(), false); err != nil {
+		return err
+	}
+
+	return validatePerformance(enginerequest)
+
+}
+
+// spec returns the request as an Engine so engine and car validation can
+// share the same powertrain rules.
+func (enginerequest EngineRequest) spec() Engine {
+	return Engine{
+		Displacement:      enginerequest.Displacement,
+		NoOfCyclinders:    enginerequest.NoOfCyclinders,
+		CarRange:          enginerequest.CarRange,
+		Powertrain:        enginerequest.Powertrain,
+		BatteryKWh:        enginerequest.BatteryKWh,
+		MotorKW:           enginerequest.MotorKW,
+		ChargingStandards: enginerequest.ChargingStandards,
+		WLTPRangeKm:       enginerequest.WLTPRangeKm,
+	}
+}
+
+// NormalizeChargingStandards lowercases the connector names and drops
+// duplicates so they can be compared against ChargingStandardsSupported.
+func NormalizeChargingStandards(standards []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+
+	for _, standard := range standards {
+		standard = strings.ToLower(strings.TrimSpace(standard))
+		if standard == "" || seen[standard] {
+			continue
+		}
+		seen[standard] = true
+		normalized = append(normalized, standard)
+	}
+	return normalized
+}
+
+// validatePowertrain checks the fields each powertrain needs. Plug-in
+// hybrids share the hybrid powertrain but must also be chargeable, which
+// only the car's fuel type can tell, hence pluggable.
+func validatePowertrain(engine Engine, pluggable bool) error {
+
+	if !engine.Powertrain.IsValid() {
+		return errors.New("Powertrain in: combustion, electric, hybrid, fuel_cell")
+	}
+
+	if err := validateCarRange(engine.CarRange); err != nil {
 		return err
 	}
 
-	if err := validateCarRange(enginerequest.CarRange); err != nil {
+	switch engine.Powertrain {
+	case PowertrainCombustion:
+		if err := validateCombustion(engine); err != nil {
+			return err
+		}
+	case PowertrainElectric:
+		if err := validateNoCombustion(engine); err != nil {
+			return err
+		}
+		if err := validateElectricMotor(engine); err != nil {
+			return err
+		}
+		if err := validateCharging(engine); err != nil {
+			return err
+		}
+	case PowertrainHybrid:
+		if err := validateCombustion(engine); err != nil {
+			return err
+		}
+		if err := validateElectricMotor(engine); err != nil {
+			return err
+		}
+		if pluggable {
+			if err := validateCharging(engine); err != nil {
+				return err
+			}
+		}
+	case PowertrainFuelCell:
+		if err := validateNoCombustion(engine); err != nil {
+			return err
+		}
+		if err := validateElectricMotor(engine); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maxPerformanceFigure bounds power in kW and torque in Nm to what the
+// NUMERIC(7, 2) power_kw and torque_nm columns hold.
+const maxPerformanceFigure = 100000
+
+// validatePerformance checks the optional power, torque, gearbox and
+// emissions figures. Zero means unknown; units must still be recognised.
+func validatePerformance(enginerequest EngineRequest) error {
+	powerKW, err := enginerequest.Power.KW()
+	if err != nil {
 		return err
 	}
+	if powerKW < 0 {
+		return errors.New("Power must not be negative")
+	}
+	if powerKW >= maxPerformanceFigure {
+		return fmt.Errorf("Power must be below %d kW", maxPerformanceFigure)
+	}
 
-	if err := validateNoOfCyclinders(enginerequest.NoOfCyclinders); err != nil {
+	torqueNm, err := enginerequest.Torque.Nm()
+	if err != nil {
 		return err
 	}
+	if torqueNm < 0 {
+		return errors.New("Torque must not be negative")
+	}
+	if torqueNm >= maxPerformanceFigure {
+		return fmt.Errorf("Torque must be below %d Nm", maxPerformanceFigure)
+	}
+
+	if enginerequest.Transmission != "" && !enginerequest.Transmission.IsValid() {
+		return errors.New("Transmission in: manual, automatic, cvt, dct, amt, single_speed")
+	}
+
+	if enginerequest.CO2GPerKm < 0 {
+		return errors.New("co2_g_per_km must not be negative")
+	}
+
+	if enginerequest.Powertrain == PowertrainElectric || enginerequest.Powertrain == PowertrainFuelCell {
+		if enginerequest.CO2GPerKm != 0 {
+			return errors.New("co2_g_per_km must be zero without a combustion engine")
+		}
+	}
+
 	return nil
+}
 
+func validateCombustion(engine Engine) error {
+	if err := validateDisplacement(engine.Displacement); err != nil {
+		return err
+	}
+	return validateNoOfCyclinders(engine.NoOfCyclinders)
+}
+
+func validateNoCombustion(engine Engine) error {
+	if engine.Displacement != 0 || engine.NoOfCyclinders != 0 {
+		return errors.New("Displacement and noOfCyclinders must be zero without a combustion engine")
+	}
+	return nil
+}
+
+func validateElectricMotor(engine Engine) error {
+	if engine.BatteryKWh <= 0 {
+		return errors.New("battery_kwh must be greater than zero")
+	}
+	if engine.MotorKW <= 0 {
+		return errors.New("motor_kw must be greater than zero")
+	}
+	return nil
+}
+
+func validateCharging(engine Engine) error {
+	if engine.WLTPRangeKm <= 0 {
+		return errors.New("wltp_range_km must be greater than zero")
+	}
+
+	standards := NormalizeChargingStandards(engine.ChargingStandards)
+	if len(standards) == 0 {
+		return errors.New("At least one charging standard is Required")
+	}
+
+	for _, standard := range standards {
+		if !isSupportedChargingStandard(standard) {
+			return errors.New("Charging standard in: " + strings.Join(ChargingStandardsSupported, ", "))
+		}
+	}
+	return nil
 }
 
 func validateDisplacement(displacement int64) error {
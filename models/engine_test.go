@@ -0,0 +1,40 @@
+package models
+
+import "testing"
+
+func TestValidateEngineRequestPerformance(t *testing.T) {
+	tests := []struct {
+		name    string
+		power   Quantity
+		torque  Quantity
+		wantErr bool
+	}{
+		{"unknown figures", Quantity{}, Quantity{}, false},
+		{"typical", Quantity{Value: 150, Unit: "hp"}, Quantity{Value: 250, Unit: "Nm"}, false},
+		{"largest power stored", Quantity{Value: 99999.99, Unit: "kW"}, Quantity{}, false},
+		{"power rounds up to the limit", Quantity{Value: 99999.996, Unit: "kW"}, Quantity{}, true},
+		{"power over the column", Quantity{Value: 100000, Unit: "kW"}, Quantity{}, true},
+		{"power in hp over the column", Quantity{Value: 140000, Unit: "hp"}, Quantity{}, true},
+		{"negative power", Quantity{Value: -1}, Quantity{}, true},
+		{"largest torque stored", Quantity{}, Quantity{Value: 99999.99, Unit: "Nm"}, false},
+		{"torque over the column", Quantity{}, Quantity{Value: 1e6, Unit: "Nm"}, true},
+		{"torque in kgf·m over the column", Quantity{}, Quantity{Value: 11000, Unit: "kgfm"}, true},
+		{"negative torque", Quantity{}, Quantity{Value: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := EngineRequest{
+				Displacement:   1998,
+				NoOfCyclinders: 4,
+				CarRange:       600,
+				Powertrain:     PowertrainCombustion,
+				Power:          tt.power,
+				Torque:         tt.torque,
+			}
+			if err := ValidateEngineRequest(request); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEngineRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
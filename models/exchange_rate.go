@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	ErrUnsupportedCurrency  = errors.New("currency is not supported")
+)
+
+// ExchangeRate converts one unit of Base into Rate units of Quote.
+type ExchangeRate struct {
+	Base      string          `json:"base"`
+	Quote     string          `json:"quote"`
+	Rate      decimal.Decimal `json:"rate"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// inverseRatePrecision is the number of decimal places kept when a rate is
+// derived by inverting a stored one.
+const inverseRatePrecision = 10
+
+// Convert converts money in the base currency into the quote currency,
+// rounded to the quote currency's minor unit.
+func (r ExchangeRate) Convert(money Money) (Money, error) {
+	if money.Currency != r.Base {
+		return Money{}, fmt.Errorf("Currency mismatch: %s and %s", money.Currency, r.Base)
+	}
+	return NewMoney(money.Amount.Mul(r.Rate), r.Quote).Round(), nil
+}
+
+// Inverse is the quote to base rate.
+func (r ExchangeRate) Inverse() ExchangeRate {
+	return ExchangeRate{
+		Base:      r.Quote,
+		Quote:     r.Base,
+		Rate:      decimal.NewFromInt(1).DivRound(r.Rate, inverseRatePrecision),
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// Cross chains this rate with one from its quote currency, e.g. EUR to USD
+// and USD to VND into EUR to VND. The result is as old as the older rate.
+func (r ExchangeRate) Cross(next ExchangeRate) (ExchangeRate, error) {
+	if r.Quote != next.Base {
+		return ExchangeRate{}, fmt.Errorf("Cannot cross %s/%s with %s/%s", r.Base, r.Quote, next.Base, next.Quote)
+	}
+
+	updatedAt := r.UpdatedAt
+	if next.UpdatedAt.Before(updatedAt) {
+		updatedAt = next.UpdatedAt
+	}
+
+	return ExchangeRate{
+		Base:      r.Base,
+		Quote:     next.Quote,
+		Rate:      r.Rate.Mul(next.Rate),
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+// ConvertedPrice is a car price shown in another currency together with the
+// rate used and when that rate was last updated.
+type ConvertedPrice struct {
+	Price         Money           `json:"price"`
+	Rate          decimal.Decimal `json:"rate"`
+	RateUpdatedAt time.Time       `json:"rate_updated_at"`
+}
+
+func ValidateExchangeRate(rate ExchangeRate) error {
+	if !IsValidCurrency(rate.Base) || !IsValidCurrency(rate.Quote) {
+		return errors.New("Exchange rate currencies must be supported ISO 4217 codes")
+	}
+	if rate.Base == rate.Quote {
+		return errors.New("Exchange rate base and quote must differ")
+	}
+	if !rate.Rate.IsPositive() {
+		return errors.New("Exchange rate must be greater than zero")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestExchangeRateConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    ExchangeRate
+		money   Money
+		want    Money
+		wantErr bool
+	}{
+		{
+			name:  "USD to VND rounds to whole dong",
+			rate:  ExchangeRate{Base: "USD", Quote: "VND", Rate: decimal.RequireFromString("25400")},
+			money: NewMoney(decimal.RequireFromString("19999.99"), "USD"),
+			want:  NewMoney(decimal.RequireFromString("507999746"), "VND"),
+		},
+		{
+			name:  "USD to EUR rounds to cents",
+			rate:  ExchangeRate{Base: "USD", Quote: "EUR", Rate: decimal.RequireFromString("0.92")},
+			money: NewMoney(decimal.RequireFromString("10.55"), "USD"),
+			want:  NewMoney(decimal.RequireFromString("9.71"), "EUR"),
+		},
+		{
+			name:    "wrong base currency",
+			rate:    ExchangeRate{Base: "USD", Quote: "EUR", Rate: decimal.RequireFromString("0.92")},
+			money:   NewMoney(decimal.RequireFromString("10"), "GBP"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rate.Convert(tt.money)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Amount.Equal(tt.want.Amount) || got.Currency != tt.want.Currency {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeRateInverse(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		rate string
+		want string
+	}{
+		{"25400", "0.0000393701"},
+		{"0.8", "1.25"},
+		{"150.5", "0.0066445183"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rate, func(t *testing.T) {
+			rate := ExchangeRate{Base: "USD", Quote: "XXX", Rate: decimal.RequireFromString(tt.rate), UpdatedAt: updatedAt}
+
+			got := rate.Inverse()
+			if got.Base != "XXX" || got.Quote != "USD" {
+				t.Errorf("Inverse() pair = %s/%s, want XXX/USD", got.Base, got.Quote)
+			}
+			if !got.Rate.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Inverse() rate = %s, want %s", got.Rate, tt.want)
+			}
+			if !got.UpdatedAt.Equal(updatedAt) {
+				t.Errorf("Inverse() updated_at = %s, want %s", got.UpdatedAt, updatedAt)
+			}
+		})
+	}
+}
+
+func TestExchangeRateCross(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	eurUSD := ExchangeRate{Base: "EUR", Quote: "USD", Rate: decimal.RequireFromString("1.25"), UpdatedAt: newer}
+	usdVND := ExchangeRate{Base: "USD", Quote: "VND", Rate: decimal.RequireFromString("25400"), UpdatedAt: older}
+
+	got, err := eurUSD.Cross(usdVND)
+	if err != nil {
+		t.Fatalf("Cross() error = %v", err)
+	}
+	if got.Base != "EUR" || got.Quote != "VND" {
+		t.Errorf("Cross() pair = %s/%s, want EUR/VND", got.Base, got.Quote)
+	}
+	if !got.Rate.Equal(decimal.RequireFromString("31750")) {
+		t.Errorf("Cross() rate = %s, want 31750", got.Rate)
+	}
+	if !got.UpdatedAt.Equal(older) {
+		t.Errorf("Cross() updated_at = %s, want the older %s", got.UpdatedAt, older)
+	}
+
+	if _, err := usdVND.Cross(eurUSD); err == nil {
+		t.Error("Cross() of unchained rates should fail")
+	}
+}
+
+func TestValidateExchangeRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    ExchangeRate
+		wantErr bool
+	}{
+		{"valid", ExchangeRate{Base: "USD", Quote: "VND", Rate: decimal.RequireFromString("25400")}, false},
+		{"unsupported currency", ExchangeRate{Base: "USD", Quote: "XXX", Rate: decimal.RequireFromString("1")}, true},
+		{"same currency", ExchangeRate{Base: "USD", Quote: "USD", Rate: decimal.RequireFromString("1")}, true},
+		{"zero rate", ExchangeRate{Base: "USD", Quote: "EUR", Rate: decimal.Zero}, true},
+		{"negative rate", ExchangeRate{Base: "USD", Quote: "EUR", Rate: decimal.RequireFromString("-0.92")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateExchangeRate(tt.rate); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateExchangeRate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
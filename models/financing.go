@@ -0,0 +1,236 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	MaxFinancingTermMonths = 120
+
+	// financingRatePlaces is the precision kept for the monthly rate and
+	// compounding factor. Only the money amounts are rounded to the
+	// currency's minor unit.
+	financingRatePlaces = 20
+)
+
+var (
+	ErrLenderProductNotFound = errors.New("lender product not found")
+	ErrInvalidFinancing      = errors.New("invalid financing request")
+)
+
+var (
+	hundred       = decimal.NewFromInt(100)
+	monthsPerYear = decimal.NewFromInt(12)
+)
+
+// LenderProduct is a loan offered by a lender. APR and MinDownPaymentPercent
+// are percentages, e.g. 7.5 for 7.5%.
+type LenderProduct struct {
+	ID                    uuid.UUID       `json:"id"`
+	Name                  string          `json:"name"`
+	Lender                string          `json:"lender"`
+	APR                   decimal.Decimal `json:"apr"`
+	MinTermMonths         int             `json:"min_term_months"`
+	MaxTermMonths         int             `json:"max_term_months"`
+	MinDownPaymentPercent decimal.Decimal `json:"min_down_payment_percent"`
+	Active                bool            `json:"active"`
+	CreatedAt             time.Time       `json:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at"`
+}
+
+type LenderProductRequest struct {
+	Name                  string          `json:"name"`
+	Lender                string          `json:"lender"`
+	APR                   decimal.Decimal `json:"apr"`
+	MinTermMonths         int             `json:"min_term_months"`
+	MaxTermMonths         int             `json:"max_term_months"`
+	MinDownPaymentPercent decimal.Decimal `json:"min_down_payment_percent"`
+	Active                bool            `json:"active"`
+}
+
+func ValidateLenderProductRequest(productRequest LenderProductRequest) error {
+	if strings.TrimSpace(productRequest.Name) == "" {
+		return errors.New("Name is Required")
+	}
+	if strings.TrimSpace(productRequest.Lender) == "" {
+		return errors.New("Lender is Required")
+	}
+	if err := validateAPR(productRequest.APR); err != nil {
+		return err
+	}
+	if productRequest.MinTermMonths < 1 || productRequest.MaxTermMonths > MaxFinancingTermMonths {
+		return fmt.Errorf("Terms must be between 1 and %d months", MaxFinancingTermMonths)
+	}
+	if productRequest.MinTermMonths > productRequest.MaxTermMonths {
+		return errors.New("Minimum term must not exceed the maximum term")
+	}
+	if productRequest.MinDownPaymentPercent.IsNegative() || productRequest.MinDownPaymentPercent.GreaterThanOrEqual(hundred) {
+		return errors.New("Minimum down payment must be at least 0% and below 100%")
+	}
+	return nil
+}
+
+// FinancingQuoteRequest asks for a quote either at a given APR or under a
+// lender product, whose APR and limits then apply. DownPayment is in the
+// car's currency.
+type FinancingQuoteRequest struct {
+	DownPayment decimal.Decimal  `json:"down_payment"`
+	TermMonths  int              `json:"term_months"`
+	APR         *decimal.Decimal `json:"apr,omitempty"`
+	ProductID   *uuid.UUID       `json:"product_id,omitempty"`
+}
+
+func ValidateFinancingQuoteRequest(quoteRequest FinancingQuoteRequest) error {
+	if quoteRequest.TermMonths < 1 || quoteRequest.TermMonths > MaxFinancingTermMonths {
+		return fmt.Errorf("%w: term must be between 1 and %d months", ErrInvalidFinancing, MaxFinancingTermMonths)
+	}
+	if quoteRequest.DownPayment.IsNegative() {
+		return fmt.Errorf("%w: down payment must not be negative", ErrInvalidFinancing)
+	}
+	if (quoteRequest.APR == nil) == (quoteRequest.ProductID == nil) {
+		return fmt.Errorf("%w: exactly one of apr and product_id is Required", ErrInvalidFinancing)
+	}
+	if quoteRequest.APR != nil {
+		if err := validateAPR(*quoteRequest.APR); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFinancing, err)
+		}
+	}
+	return nil
+}
+
+// CheckQuote enforces the product's term and down payment limits for a
+// car at price.
+func (p LenderProduct) CheckQuote(price Money, quoteRequest FinancingQuoteRequest) error {
+	if !p.Active {
+		return fmt.Errorf("%w: product %s is not offered", ErrInvalidFinancing, p.Name)
+	}
+	if quoteRequest.TermMonths < p.MinTermMonths || quoteRequest.TermMonths > p.MaxTermMonths {
+		return fmt.Errorf("%w: %s requires a term of %d to %d months", ErrInvalidFinancing, p.Name, p.MinTermMonths, p.MaxTermMonths)
+	}
+
+	minDown := price.Mul(p.MinDownPaymentPercent.Div(hundred)).Round()
+	if quoteRequest.DownPayment.LessThan(minDown.Amount) {
+		return fmt.Errorf("%w: %s requires a down payment of at least %s", ErrInvalidFinancing, p.Name, minDown)
+	}
+	return nil
+}
+
+func validateAPR(apr decimal.Decimal) error {
+	if apr.IsNegative() || apr.GreaterThan(hundred) {
+		return errors.New("APR must be between 0 and 100")
+	}
+	return nil
+}
+
+// AmortizationRow is one monthly installment. Balance is what is still owed
+// after it.
+type AmortizationRow struct {
+	Month     int   `json:"month"`
+	Payment   Money `json:"payment"`
+	Principal Money `json:"principal"`
+	Interest  Money `json:"interest"`
+	Balance   Money `json:"balance"`
+}
+
+type FinancingQuote struct {
+	CarID          uuid.UUID         `json:"car_id"`
+	ProductID      *uuid.UUID        `json:"product_id,omitempty"`
+	Price          Money             `json:"price"`
+	DownPayment    Money             `json:"down_payment"`
+	Principal      Money             `json:"principal"`
+	TermMonths     int               `json:"term_months"`
+	APR            decimal.Decimal   `json:"apr"`
+	MonthlyPayment Money             `json:"monthly_payment"`
+	TotalInterest  Money             `json:"total_interest"`
+	TotalPaid      Money             `json:"total_paid"`
+	Schedule       []AmortizationRow `json:"schedule"`
+}
+
+// NewFinancingQuote builds a fixed-rate amortization schedule with interest
+// compounded monthly. Every installment is rounded to the currency's minor
+// unit and the last one absorbs the rounding so the balance ends at zero.
+// When rounding pays the loan off early the schedule stops there, so it
+// can be shorter than TermMonths but never lists empty installments.
+func NewFinancingQuote(price Money, downPayment decimal.Decimal, termMonths int, apr decimal.Decimal) (FinancingQuote, error) {
+	down := NewMoney(downPayment, price.Currency)
+	if err := ValidateMoney(down); err != nil {
+		return FinancingQuote{}, fmt.Errorf("%w: %v", ErrInvalidFinancing, err)
+	}
+	if !down.Amount.LessThan(price.Amount) {
+		return FinancingQuote{}, fmt.Errorf("%w: down payment must be below the price", ErrInvalidFinancing)
+	}
+
+	principal, err := price.Sub(down)
+	if err != nil {
+		return FinancingQuote{}, err
+	}
+
+	rate := apr.DivRound(hundred.Mul(monthsPerYear), financingRatePlaces)
+	payment := monthlyPayment(principal, rate, termMonths)
+
+	zero := NewMoney(decimal.Zero, price.Currency)
+	quote := FinancingQuote{
+		Price:          price,
+		DownPayment:    down,
+		Principal:      principal,
+		TermMonths:     termMonths,
+		APR:            apr,
+		MonthlyPayment: payment,
+		TotalInterest:  zero,
+		TotalPaid:      zero,
+		Schedule:       make([]AmortizationRow, 0, termMonths),
+	}
+
+	balance := principal
+	for month := 1; month <= termMonths && balance.IsPositive(); month++ {
+		interest := balance.Mul(rate).Round()
+		principalPart := Money{Amount: payment.Amount.Sub(interest.Amount), Currency: price.Currency}
+
+		if month == termMonths || principalPart.Amount.GreaterThan(balance.Amount) {
+			principalPart = balance
+		}
+
+		balance.Amount = balance.Amount.Sub(principalPart.Amount)
+		installment := Money{Amount: principalPart.Amount.Add(interest.Amount), Currency: price.Currency}
+
+		quote.Schedule = append(quote.Schedule, AmortizationRow{
+			Month:     month,
+			Payment:   installment,
+			Principal: principalPart,
+			Interest:  interest,
+			Balance:   balance,
+		})
+
+		quote.TotalInterest.Amount = quote.TotalInterest.Amount.Add(interest.Amount)
+		quote.TotalPaid.Amount = quote.TotalPaid.Amount.Add(installment.Amount)
+	}
+
+	return quote, nil
+}
+
+// monthlyPayment is the annuity installment P*r / (1 - (1+r)^-n), or an
+// even split of the principal when the rate is zero.
+func monthlyPayment(principal Money, rate decimal.Decimal, termMonths int) Money {
+	n := decimal.NewFromInt(int64(termMonths))
+
+	if rate.IsZero() {
+		return Money{Amount: principal.Amount.DivRound(n, financingRatePlaces), Currency: principal.Currency}.Round()
+	}
+
+	// (1+r)^n by repeated multiplication, rounding each step so the
+	// precision stays bounded.
+	growth := decimal.NewFromInt(1).Add(rate)
+	factor := decimal.NewFromInt(1)
+	for i := 0; i < termMonths; i++ {
+		factor = factor.Mul(growth).Round(financingRatePlaces)
+	}
+
+	amount := principal.Amount.Mul(rate).Mul(factor).DivRound(factor.Sub(decimal.NewFromInt(1)), financingRatePlaces)
+	return Money{Amount: amount, Currency: principal.Currency}.Round()
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNewFinancingQuote(t *testing.T) {
+	tests := []struct {
+		name          string
+		price         Money
+		downPayment   string
+		termMonths    int
+		apr           string
+		wantMonthly   string
+		wantInterest  string
+		wantTotalPaid string
+		wantRows      int
+		wantLast      string
+	}{
+		{"12% over a year", NewMoney(decimal.RequireFromString("10000"), "USD"), "0", 12, "12", "888.49", "661.86", "10661.86", 12, "888.47"},
+		{"with a down payment", NewMoney(decimal.RequireFromString("25000"), "USD"), "5000", 60, "6.5", "391.32", "3479.43", "23479.43", 60, "391.55"},
+		{"interest free", NewMoney(decimal.RequireFromString("1200"), "USD"), "0", 12, "0", "100", "0", "1200", 12, "100"},
+		{"whole dong", NewMoney(decimal.RequireFromString("500000000"), "VND"), "100000000", 36, "9", "12719893", "57916148", "457916148", 36, "12719893"},
+		{"rounding pays off early", NewMoney(decimal.RequireFromString("0.10"), "USD"), "0", 12, "0", "0.01", "0", "0.10", 10, "0.01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := NewFinancingQuote(tt.price, decimal.RequireFromString(tt.downPayment), tt.termMonths, decimal.RequireFromString(tt.apr))
+			if err != nil {
+				t.Fatalf("NewFinancingQuote() error = %v", err)
+			}
+
+			if !quote.MonthlyPayment.Amount.Equal(decimal.RequireFromString(tt.wantMonthly)) {
+				t.Errorf("MonthlyPayment = %s, want %s", quote.MonthlyPayment, tt.wantMonthly)
+			}
+			if !quote.TotalInterest.Amount.Equal(decimal.RequireFromString(tt.wantInterest)) {
+				t.Errorf("TotalInterest = %s, want %s", quote.TotalInterest, tt.wantInterest)
+			}
+			if !quote.TotalPaid.Amount.Equal(decimal.RequireFromString(tt.wantTotalPaid)) {
+				t.Errorf("TotalPaid = %s, want %s", quote.TotalPaid, tt.wantTotalPaid)
+			}
+			if len(quote.Schedule) != tt.wantRows {
+				t.Fatalf("len(Schedule) = %d, want %d", len(quote.Schedule), tt.wantRows)
+			}
+
+			principalPaid := decimal.Zero
+			for _, row := range quote.Schedule {
+				if !row.Payment.IsPositive() {
+					t.Errorf("month %d has installment %s, want a positive one", row.Month, row.Payment)
+				}
+				principalPaid = principalPaid.Add(row.Principal.Amount)
+			}
+			if !principalPaid.Equal(quote.Principal.Amount) {
+				t.Errorf("principal paid = %s, want %s", principalPaid, quote.Principal.Amount)
+			}
+
+			last := quote.Schedule[len(quote.Schedule)-1]
+			if !last.Payment.Amount.Equal(decimal.RequireFromString(tt.wantLast)) {
+				t.Errorf("last installment = %s, want %s", last.Payment, tt.wantLast)
+			}
+			if !last.Balance.IsZero() {
+				t.Errorf("final balance = %s, want zero", last.Balance)
+			}
+		})
+	}
+}
+
+func TestNewFinancingQuoteInvalid(t *testing.T) {
+	price := NewMoney(decimal.RequireFromString("10000"), "USD")
+
+	tests := []struct {
+		name        string
+		downPayment string
+	}{
+		{"down payment equals price", "10000"},
+		{"down payment above price", "12000"},
+		{"down payment with fractions of a cent", "100.005"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFinancingQuote(price, decimal.RequireFromString(tt.downPayment), 12, decimal.NewFromInt(5)); err == nil {
+				t.Error("NewFinancingQuote() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+type FuelType string
+
+const (
+	FuelTypePetrol       FuelType = "petrol"
+	FuelTypeDiesel       FuelType = "diesel"
+	FuelTypeElectric     FuelType = "electric"
+	FuelTypeHybrid       FuelType = "hybrid"
+	FuelTypePlugInHybrid FuelType = "plug_in_hybrid"
+	FuelTypeHydrogen     FuelType = "hydrogen"
+	FuelTypeLPG          FuelType = "lpg"
+)
+
+// FuelTypes lists every accepted fuel type. Keep it in sync with the
+// chk_car_fuel_type constraint in store/schema.sql.
+var FuelTypes = []FuelType{
+	FuelTypePetrol,
+	FuelTypeDiesel,
+	FuelTypeElectric,
+	FuelTypeHybrid,
+	FuelTypePlugInHybrid,
+	FuelTypeHydrogen,
+	FuelTypeLPG,
+}
+
+// fuelTypeAliases maps the spellings seen in existing data and client
+// requests to their canonical fuel type.
+var fuelTypeAliases = map[string]FuelType{
+	"petrol":         FuelTypePetrol,
+	"gasoline":       FuelTypePetrol,
+	"gas":            FuelTypePetrol,
+	"persol":         FuelTypePetrol,
+	"diesel":         FuelTypeDiesel,
+	"electric":       FuelTypeElectric,
+	"ev":             FuelTypeElectric,
+	"bev":            FuelTypeElectric,
+	"hybrid":         FuelTypeHybrid,
+	"hev":            FuelTypeHybrid,
+	"plug_in_hybrid": FuelTypePlugInHybrid,
+	"plug-in hybrid": FuelTypePlugInHybrid,
+	"plug in hybrid": FuelTypePlugInHybrid,
+	"plugin hybrid":  FuelTypePlugInHybrid,
+	"phev":           FuelTypePlugInHybrid,
+	"hydrogen":       FuelTypeHydrogen,
+	"fuel cell":      FuelTypeHydrogen,
+	"fcev":           FuelTypeHydrogen,
+	"lpg":            FuelTypeLPG,
+	"autogas":        FuelTypeLPG,
+}
+
+func ParseFuelType(value string) (FuelType, error) {
+	key := strings.ToLower(strings.Join(strings.Fields(value), " "))
+
+	if fuelType, ok := fuelTypeAliases[key]; ok {
+		return fuelType, nil
+	}
+
+	return FuelType(value), errors.New("FuelType in: " + fuelTypeList())
+}
+
+func (f FuelType) IsValid() bool {
+	for _, fuelType := range FuelTypes {
+		if f == fuelType {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON accepts any known alias and stores the canonical value.
+// Unknown values are kept as-is so ValidateCarRequest can report them.
+func (f *FuelType) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	*f, _ = ParseFuelType(value)
+	return nil
+}
+
+func fuelTypeList() string {
+	names := make([]string, len(FuelTypes))
+	for i, fuelType := range FuelTypes {
+		names[i] = string(fuelType)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockStatus string
+
+const (
+	StockStatusAvailable StockStatus = "available"
+	StockStatusReserved  StockStatus = "reserved"
+	StockStatusSold      StockStatus = "sold"
+	StockStatusInTransit StockStatus = "in_transit"
+)
+
+var (
+	// ErrStockStatusManaged is returned when a unit is created or edited as
+	// reserved or sold; only reservations and orders set those.
+	ErrStockStatusManaged = errors.New("status must be available or in_transit; reserved and sold are set by reservations and orders")
+	// ErrUnitInUse is returned when a reserved or sold unit, or one with an
+	// order, would change status or be deleted.
+	ErrUnitInUse = errors.New("inventory unit is held by a reservation or order")
+)
+
+func (s StockStatus) IsValid() bool {
+	switch s {
+	case StockStatusAvailable, StockStatusReserved, StockStatusSold, StockStatusInTransit:
+		return true
+	}
+	return false
+}
+
+// IsManual reports whether staff may set the status directly.
+func (s StockStatus) IsManual() bool {
+	return s == StockStatusAvailable || s == StockStatusInTransit
+}
+
+// InventoryUnit is one physical vehicle of a car listing.
+type InventoryUnit struct {
+	ID           uuid.UUID   `json:"id"`
+	CarID        uuid.UUID   `json:"car_id"`
+	VIN          string      `json:"vin"`
+	Color        string      `json:"color"`
+	MileageKm    int         `json:"mileage_km"`
+	Status       StockStatus `json:"status"`
+	DealershipID *uuid.UUID  `json:"dealership_id,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// InventoryUnitRequest creates or updates a unit. A unit without a
+// dealership takes the dealership of its car. Status may only be available
+// or in_transit; a new unit without one is available and an update without
+// one keeps the unit's status.
+type InventoryUnitRequest struct {
+	VIN          string      `json:"vin"`
+	Color        string      `json:"color"`
+	MileageKm    int         `json:"mileage_km"`
+	Status       StockStatus `json:"status"`
+	DealershipID *uuid.UUID  `json:"dealership_id,omitempty"`
+}
+
+// Availability counts the inventory units of a car by stock status.
+type Availability struct {
+	Available int `json:"available"`
+	Reserved  int `json:"reserved"`
+	Sold      int `json:"sold"`
+	InTransit int `json:"in_transit"`
+}
+
+// Add counts n units with the given status.
+func (a *Availability) Add(status StockStatus, n int) {
+	switch status {
+	case StockStatusAvailable:
+		a.Available += n
+	case StockStatusReserved:
+		a.Reserved += n
+	case StockStatusSold:
+		a.Sold += n
+	case StockStatusInTransit:
+		a.InTransit += n
+	}
+}
+
+// NormalizeVIN uppercases a VIN and strips spaces and dashes.
+func NormalizeVIN(vin string) string {
+	vin = strings.ToUpper(vin)
+	return strings.NewReplacer(" ", "", "-", "").Replace(vin)
+}
+
+func ValidateInventoryUnitRequest(unitRequest InventoryUnitRequest) error {
+	if err := validateVINFormat(NormalizeVIN(unitRequest.VIN)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidVIN, err)
+	}
+	if strings.TrimSpace(unitRequest.Color) == "" {
+		return errors.New("Color is Required")
+	}
+	if unitRequest.MileageKm < 0 {
+		return errors.New("Mileage must not be negative")
+	}
+	if unitRequest.Status != "" && !unitRequest.Status.IsValid() {
+		return fmt.Errorf("Invalid stock status %q", unitRequest.Status)
+	}
+	if unitRequest.Status != "" && !unitRequest.Status.IsManual() {
+		return ErrStockStatusManaged
+	}
+	return nil
+}
+
+// validateVINFormat checks the shape of a normalized VIN: 17 characters,
+// digits and capital letters except I, O and Q.
+func validateVINFormat(vin string) error {
+	if len(vin) != 17 {
+		return errors.New("VIN must be 17 characters")
+	}
+	for _, r := range vin {
+		if !(r >= '0' && r <= '9' || r >= 'A' && r <= 'Z') || r == 'I' || r == 'O' || r == 'Q' {
+			return fmt.Errorf("VIN contains invalid character %q", r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateInventoryUnitRequestStatus(t *testing.T) {
+	tests := []struct {
+		status  StockStatus
+		wantErr error
+	}{
+		{"", nil},
+		{StockStatusAvailable, nil},
+		{StockStatusInTransit, nil},
+		{StockStatusReserved, ErrStockStatusManaged},
+		{StockStatusSold, ErrStockStatusManaged},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			request := InventoryUnitRequest{VIN: "1HGCM82633A004352", Color: "White", Status: tt.status}
+			if err := ValidateInventoryUnitRequest(request); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateInventoryUnitRequest(%q) error = %v, want %v", tt.status, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := ValidateInventoryUnitRequest(InventoryUnitRequest{VIN: "1HGCM82633A004352", Color: "White", Status: "lost"}); err == nil {
+		t.Error("ValidateInventoryUnitRequest(lost) error = nil, want an error")
+	}
+}
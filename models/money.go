@@ -0,0 +1,163 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCurrency is assumed for prices sent as a bare number, which is how
+// clients sent them before prices carried a currency.
+const DefaultCurrency = "USD"
+
+// currencyMinorUnits holds the ISO 4217 codes we accept and how many decimal
+// places each one uses.
+var currencyMinorUnits = map[string]int32{
+	"AUD": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"MYR": 2,
+	"PHP": 2,
+	"SGD": 2,
+	"THB": 2,
+	"USD": 2,
+	"VND": 0,
+}
+
+// Money is an exact decimal amount in a single currency. It encodes as
+// {"amount": "25000.00", "currency": "USD"}, padded to the currency's minor
+// unit, so no precision is lost in JSON.
+type Money struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+func NewMoney(amount decimal.Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(strings.TrimSpace(currency))}
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if len(data) > 0 && data[0] != '{' {
+		var amount decimal.Decimal
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return err
+		}
+		*m = Money{Amount: amount, Currency: DefaultCurrency}
+		return nil
+	}
+
+	type money Money
+	var raw money
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = NewMoney(raw.Amount, raw.Currency)
+	return nil
+}
+
+// MarshalJSON pads the amount to the currency's minor unit. Amounts with
+// more decimal places, such as unrounded intermediate results, keep them.
+func (m Money) MarshalJSON() ([]byte, error) {
+	places := minorUnits(m.Currency)
+	if trimmed := m.Amount.String(); strings.Contains(trimmed, ".") {
+		if decimals := int32(len(trimmed) - strings.Index(trimmed, ".") - 1); decimals > places {
+			places = decimals
+		}
+	}
+	amount := m.Amount.StringFixed(places)
+
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{amount, m.Currency})
+}
+
+func (m Money) String() string {
+	return m.Amount.StringFixed(minorUnits(m.Currency)) + " " + m.Currency
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount.IsPositive()
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.Currency}, nil
+}
+
+func (m Money) Mul(factor decimal.Decimal) Money {
+	return Money{Amount: m.Amount.Mul(factor), Currency: m.Currency}
+}
+
+// Cmp compares two amounts in the same currency and returns -1, 0 or 1.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	return m.Amount.Cmp(other.Amount), nil
+}
+
+// Round rounds half away from zero to the currency's minor unit, e.g. cents
+// for USD and whole dong for VND.
+func (m Money) Round() Money {
+	return Money{Amount: m.Amount.Round(minorUnits(m.Currency)), Currency: m.Currency}
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("Currency mismatch: %s and %s", m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func IsValidCurrency(currency string) bool {
+	_, ok := currencyMinorUnits[currency]
+	return ok
+}
+
+func minorUnits(currency string) int32 {
+	if units, ok := currencyMinorUnits[currency]; ok {
+		return units
+	}
+	return 2
+}
+
+func ValidateMoney(money Money) error {
+	if money.Currency == "" {
+		return errors.New("Currency is Required")
+	}
+	if !IsValidCurrency(money.Currency) {
+		return fmt.Errorf("Currency %s is not supported", money.Currency)
+	}
+	if !money.Amount.Equal(money.Amount.Round(minorUnits(money.Currency))) {
+		return fmt.Errorf("Amount has more decimal places than %s allows", money.Currency)
+	}
+	return nil
+}
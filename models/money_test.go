@@ -0,0 +1,141 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{"pads whole USD", NewMoney(decimal.RequireFromString("25000"), "USD"), `{"amount":"25000.00","currency":"USD"}`},
+		{"pads one decimal", NewMoney(decimal.RequireFromString("19.5"), "EUR"), `{"amount":"19.50","currency":"EUR"}`},
+		{"keeps cents", NewMoney(decimal.RequireFromString("19.99"), "USD"), `{"amount":"19.99","currency":"USD"}`},
+		{"whole dong", NewMoney(decimal.RequireFromString("808480000"), "VND"), `{"amount":"808480000","currency":"VND"}`},
+		{"keeps extra precision", NewMoney(decimal.RequireFromString("10.125"), "USD"), `{"amount":"10.125","currency":"USD"}`},
+		{"negative", NewMoney(decimal.RequireFromString("-5"), "USD"), `{"amount":"-5.00","currency":"USD"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.money)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{"bare number is USD", `25000`, NewMoney(decimal.RequireFromString("25000"), "USD"), false},
+		{"object", `{"amount": "19.99", "currency": "eur "}`, NewMoney(decimal.RequireFromString("19.99"), "EUR"), false},
+		{"numeric amount", `{"amount": 500000000, "currency": "VND"}`, NewMoney(decimal.RequireFromString("500000000"), "VND"), false},
+		{"invalid amount", `{"amount": "abc", "currency": "USD"}`, Money{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Amount.Equal(tt.want.Amount) || got.Currency != tt.want.Currency {
+				t.Errorf("Unmarshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	usd := func(amount string) Money { return NewMoney(decimal.RequireFromString(amount), "USD") }
+	eur := NewMoney(decimal.RequireFromString("1"), "EUR")
+
+	sum, err := usd("19.99").Add(usd("0.01"))
+	if err != nil || !sum.Amount.Equal(decimal.RequireFromString("20")) {
+		t.Errorf("Add() = %v, %v, want 20.00 USD", sum, err)
+	}
+
+	difference, err := usd("20").Sub(usd("0.01"))
+	if err != nil || !difference.Amount.Equal(decimal.RequireFromString("19.99")) {
+		t.Errorf("Sub() = %v, %v, want 19.99 USD", difference, err)
+	}
+
+	if _, err := usd("1").Add(eur); err == nil {
+		t.Error("Add() across currencies should fail")
+	}
+	if _, err := usd("1").Sub(eur); err == nil {
+		t.Error("Sub() across currencies should fail")
+	}
+	if _, err := usd("1").Cmp(eur); err == nil {
+		t.Error("Cmp() across currencies should fail")
+	}
+
+	if cmp, err := usd("10").Cmp(usd("9.99")); err != nil || cmp != 1 {
+		t.Errorf("Cmp() = %d, %v, want 1", cmp, err)
+	}
+}
+
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{"10.125", "USD", "10.13"},
+		{"10.124", "USD", "10.12"},
+		{"-10.125", "USD", "-10.13"},
+		{"1500.5", "JPY", "1501"},
+		{"808479999.5", "VND", "808480000"},
+		{"3.14159", "XXX", "3.14"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			got := NewMoney(decimal.RequireFromString(tt.amount), tt.currency).Round()
+			if !got.Amount.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Round() = %s, want %s", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		wantErr bool
+	}{
+		{"valid USD", NewMoney(decimal.RequireFromString("19.99"), "USD"), false},
+		{"valid VND", NewMoney(decimal.RequireFromString("500000000"), "VND"), false},
+		{"missing currency", Money{Amount: decimal.RequireFromString("1")}, true},
+		{"unsupported currency", NewMoney(decimal.RequireFromString("1"), "XXX"), true},
+		{"too many decimals for USD", NewMoney(decimal.RequireFromString("1.001"), "USD"), true},
+		{"decimals for JPY", NewMoney(decimal.RequireFromString("1.5"), "JPY"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateMoney(tt.money); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMoney() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
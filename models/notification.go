@@ -0,0 +1,261 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationKind string
+
+const (
+	// NotificationPriceDrop tells customers a car on their wishlist got
+	// cheaper.
+	NotificationPriceDrop NotificationKind = "price_drop"
+	// NotificationReservationExpiring warns that a hold runs out soon.
+	NotificationReservationExpiring NotificationKind = "reservation_expiring"
+	// NotificationReservationExpired tells the customer the hold lapsed.
+	NotificationReservationExpired NotificationKind = "reservation_expired"
+	// NotificationTestDriveReminder reminds of an upcoming test drive.
+	NotificationTestDriveReminder NotificationKind = "test_drive_reminder"
+)
+
+var notificationKinds = []NotificationKind{
+	NotificationPriceDrop,
+	NotificationReservationExpiring,
+	NotificationReservationExpired,
+	NotificationTestDriveReminder,
+}
+
+func (k NotificationKind) IsValid() bool {
+	for _, kind := range notificationKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelInApp NotificationChannel = "in_app"
+)
+
+// DefaultNotificationChannels are used for kinds a customer has not set
+// preferences for.
+var DefaultNotificationChannels = []NotificationChannel{NotificationChannelInApp, NotificationChannelEmail}
+
+func (c NotificationChannel) IsValid() bool {
+	return c == NotificationChannelEmail || c == NotificationChannelInApp
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "pending"
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+)
+
+func (s DeliveryStatus) IsValid() bool {
+	return s == DeliveryStatusPending || s == DeliveryStatusSent || s == DeliveryStatusFailed
+}
+
+const (
+	// MaxDeliveryAttempts is how often a delivery is tried before it is
+	// marked failed.
+	MaxDeliveryAttempts = 5
+
+	deliveryRetryBase = time.Minute
+)
+
+var (
+	ErrNotificationTemplateNotFound = errors.New("notification template not found")
+	ErrInboxMessageNotFound         = errors.New("inbox message not found")
+	ErrInvalidDeliveryStatus        = errors.New("status must be pending, sent or failed")
+)
+
+// NotificationTemplate renders one kind of notification. Subject and Body
+// are text/template sources executed with the event's data.
+type NotificationTemplate struct {
+	Kind      NotificationKind `json:"kind"`
+	Subject   string           `json:"subject"`
+	Body      string           `json:"body"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+type NotificationTemplateRequest struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+func ValidateNotificationTemplateRequest(kind NotificationKind, templateRequest NotificationTemplateRequest) error {
+	if !kind.IsValid() {
+		return fmt.Errorf("Unknown notification kind %s", kind)
+	}
+	if strings.TrimSpace(templateRequest.Subject) == "" {
+		return errors.New("Subject is Required")
+	}
+	if strings.TrimSpace(templateRequest.Body) == "" {
+		return errors.New("Body is Required")
+	}
+	if _, err := template.New("subject").Parse(templateRequest.Subject); err != nil {
+		return fmt.Errorf("Subject is not a valid template: %v", err)
+	}
+	if _, err := template.New("body").Parse(templateRequest.Body); err != nil {
+		return fmt.Errorf("Body is not a valid template: %v", err)
+	}
+	return nil
+}
+
+// Render executes the subject and body with data. Missing keys are errors
+// rather than "<no value>" in a customer's inbox.
+func (t NotificationTemplate) Render(data map[string]interface{}) (string, string, error) {
+	subject, err := renderTemplate("subject", t.Subject, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	body, err := renderTemplate("body", t.Body, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subject), body, nil
+}
+
+func renderTemplate(name, source string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// NotificationChannels lists the channels enabled per kind. Stored as
+// JSONB.
+type NotificationChannels map[NotificationKind][]NotificationChannel
+
+func (c NotificationChannels) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *NotificationChannels) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// NotificationPreferences are a customer's contact details and channel
+// choices. Email notifications need an Email address.
+type NotificationPreferences struct {
+	Customer  string               `json:"customer"`
+	Email     *string              `json:"email,omitempty"`
+	Channels  NotificationChannels `json:"channels"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// ChannelsFor lists the channels the customer wants for kind.
+func (p NotificationPreferences) ChannelsFor(kind NotificationKind) []NotificationChannel {
+	if channels, ok := p.Channels[kind]; ok {
+		return channels
+	}
+	return DefaultNotificationChannels
+}
+
+type NotificationPreferencesRequest struct {
+	Email    *string              `json:"email,omitempty"`
+	Channels NotificationChannels `json:"channels"`
+}
+
+func ValidateNotificationPreferencesRequest(preferencesRequest NotificationPreferencesRequest) error {
+	if preferencesRequest.Email != nil {
+		if _, err := mail.ParseAddress(*preferencesRequest.Email); err != nil {
+			return errors.New("Email is not a valid address")
+		}
+	}
+
+	for kind, channels := range preferencesRequest.Channels {
+		if !kind.IsValid() {
+			return fmt.Errorf("Unknown notification kind %s", kind)
+		}
+		for _, channel := range channels {
+			if !channel.IsValid() {
+				return fmt.Errorf("Channel must be email or in_app, got %s", channel)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NotificationEvent is something a customer should hear about. DedupeKey
+// identifies the event, so it is delivered once per channel however often
+// it is raised.
+type NotificationEvent struct {
+	Customer  string
+	Kind      NotificationKind
+	DedupeKey string
+	Data      map[string]interface{}
+}
+
+// NotificationDelivery is one rendered notification queued for one channel.
+// Recipient is the email address for email and the customer for in-app.
+type NotificationDelivery struct {
+	ID            uuid.UUID           `json:"id"`
+	Customer      string              `json:"customer"`
+	Kind          NotificationKind    `json:"kind"`
+	Channel       NotificationChannel `json:"channel"`
+	DedupeKey     string              `json:"dedupe_key"`
+	Recipient     string              `json:"recipient"`
+	Subject       string              `json:"subject"`
+	Body          string              `json:"body"`
+	Status        DeliveryStatus      `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	NextAttemptAt time.Time           `json:"next_attempt_at"`
+	LastError     *string             `json:"last_error,omitempty"`
+	SentAt        *time.Time          `json:"sent_at,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// NextDeliveryAttempt is when a delivery that has failed attempts times
+// should be retried: after 1, 2, 4 and 8 minutes. It reports false once
+// MaxDeliveryAttempts is reached.
+func NextDeliveryAttempt(attempts int, now time.Time) (time.Time, bool) {
+	if attempts >= MaxDeliveryAttempts {
+		return time.Time{}, false
+	}
+	if attempts < 1 {
+		attempts = 1
+	}
+	return now.Add(deliveryRetryBase << (attempts - 1)), true
+}
+
+// InboxMessage is a notification delivered to the in-app inbox.
+type InboxMessage struct {
+	ID        uuid.UUID        `json:"id"`
+	Customer  string           `json:"customer"`
+	Kind      NotificationKind `json:"kind"`
+	Subject   string           `json:"subject"`
+	Body      string           `json:"body"`
+	ReadAt    *time.Time       `json:"read_at,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
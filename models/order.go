@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusDraft     OrderStatus = "draft"
+	OrderStatusConfirmed OrderStatus = "confirmed"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+var (
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrInvalidOrderTransition = errors.New("order status change not allowed")
+	ErrOrderForbidden         = errors.New("only staff may make this change to an order")
+	ErrOrderPriceChanged      = errors.New("car price changed while the order was priced")
+)
+
+// orderTransitions lists the statuses each status may move to. Delivered
+// and cancelled are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusDraft:     {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusDelivered, OrderStatusCancelled},
+}
+
+func CanTransitionOrder(from, to OrderStatus) error {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidOrderTransition, from, to)
+}
+
+// HoldsUnit reports whether an order in this status keeps its unit off
+// sale. Confirmed and paid orders hold the unit as reserved; delivery
+// marks it sold.
+func (s OrderStatus) HoldsUnit() bool {
+	return s == OrderStatusConfirmed || s == OrderStatusPaid
+}
+
+// Order is the sale of one inventory unit to a customer. Total is
+// Price - Discount + Tax, all in the car's currency. Tax is worked out for
+// TaxRegion. AmountPaid is the sum captured by payments, less refunds.
+type Order struct {
+	ID           uuid.UUID   `json:"id"`
+	Customer     string      `json:"customer"`
+	UnitID       uuid.UUID   `json:"unit_id"`
+	CarID        uuid.UUID   `json:"car_id"`
+	DealershipID *uuid.UUID  `json:"dealership_id,omitempty"`
+	TaxRegion    string      `json:"tax_region"`
+	Price        Money       `json:"price"`
+	Discount     Money       `json:"discount"`
+	Tax          Money       `json:"tax"`
+	Total        Money       `json:"total"`
+	AmountPaid   Money       `json:"amount_paid"`
+	Status       OrderStatus `json:"status"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// OrderRequest creates a draft order. Region is the tax region the car
+// will be registered in. The discount comes from live promotions and the
+// tax from the region's rates; neither is taken from the customer.
+type OrderRequest struct {
+	UnitID uuid.UUID `json:"unit_id"`
+	Region string    `json:"region"`
+}
+
+// OrderDiscountRequest sets the discount staff grant on a draft order. It
+// replaces the promotional discount and is an amount in the car's currency.
+type OrderDiscountRequest struct {
+	Discount decimal.Decimal `json:"discount"`
+}
+
+type OrderStatusRequest struct {
+	Status OrderStatus `json:"status"`
+}
+
+func ValidateOrderRequest(orderRequest OrderRequest) error {
+	if orderRequest.UnitID == uuid.Nil {
+		return errors.New("Unit ID is Required")
+	}
+	if orderRequest.Region == "" {
+		return errors.New("Region is Required")
+	}
+	return nil
+}
+
+// CanSetOrderStatus checks who may move an order to the status. Staff may
+// confirm, deliver or cancel; a customer may only cancel their own order.
+// Orders become paid only when payments settle them.
+func CanSetOrderStatus(status OrderStatus, staff bool) error {
+	if status == OrderStatusPaid {
+		return fmt.Errorf("%w: orders are marked paid by their payments", ErrInvalidOrderTransition)
+	}
+	if !staff && status != OrderStatusCancelled {
+		return ErrOrderForbidden
+	}
+	return nil
+}
+
+// NewOrderAmounts prices an order from the car price and discount, with tax
+// worked out on the price after the discount for the region and the car's
+// special consumption tax rule. The discount may not exceed the price.
+func NewOrderAmounts(price, discount Money, region TaxRegion, rule SCTRule) (Money, Money, Money, error) {
+	discount = discount.Round()
+
+	if discount.Amount.IsNegative() {
+		return Money{}, Money{}, Money{}, errors.New("Discount must not be negative")
+	}
+	if discount.Amount.GreaterThan(price.Amount) {
+		return Money{}, Money{}, Money{}, errors.New("Discount must not exceed the price")
+	}
+
+	taxable, err := price.Sub(discount)
+	if err != nil {
+		return Money{}, Money{}, Money{}, err
+	}
+
+	tax := SalesTax(taxable, region, rule)
+
+	total, err := taxable.Add(tax)
+	if err != nil {
+		return Money{}, Money{}, Money{}, err
+	}
+
+	return discount, tax, total.Round(), nil
+}
+
+// Balance is what is left to pay on the order.
+func (o Order) Balance() Money {
+	balance, err := o.Total.Sub(o.AmountPaid)
+	if err != nil {
+		return o.Total
+	}
+	return balance
+}
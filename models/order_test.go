@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCanSetOrderStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  OrderStatus
+		staff   bool
+		wantErr error
+	}{
+		{"staff confirms", OrderStatusConfirmed, true, nil},
+		{"staff delivers", OrderStatusDelivered, true, nil},
+		{"staff cancels", OrderStatusCancelled, true, nil},
+		{"staff cannot mark paid", OrderStatusPaid, true, ErrInvalidOrderTransition},
+		{"customer cancels", OrderStatusCancelled, false, nil},
+		{"customer cannot confirm", OrderStatusConfirmed, false, ErrOrderForbidden},
+		{"customer cannot deliver", OrderStatusDelivered, false, ErrOrderForbidden},
+		{"customer cannot mark paid", OrderStatusPaid, false, ErrInvalidOrderTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CanSetOrderStatus(tt.status, tt.staff)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CanSetOrderStatus(%s, %v) error = %v, want %v", tt.status, tt.staff, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewOrderAmounts(t *testing.T) {
+	vnd := func(amount string) Money { return NewMoney(decimal.RequireFromString(amount), "VND") }
+
+	region := TaxRegion{Code: "HN", VATPercent: decimal.NewFromInt(10)}
+	rule := SCTRule{RatePercent: decimal.NewFromInt(15)}
+
+	tests := []struct {
+		name         string
+		price        Money
+		discount     Money
+		wantDiscount Money
+		wantTax      Money
+		wantTotal    Money
+		wantErr      bool
+	}{
+		{
+			name:         "no discount",
+			price:        vnd("500000000"),
+			discount:     vnd("0"),
+			wantDiscount: vnd("0"),
+			wantTax:      vnd("132500000"),
+			wantTotal:    vnd("632500000"),
+		},
+		{
+			name:         "tax on the discounted price",
+			price:        vnd("500000000"),
+			discount:     vnd("20000000"),
+			wantDiscount: vnd("20000000"),
+			wantTax:      vnd("127200000"),
+			wantTotal:    vnd("607200000"),
+		},
+		{
+			name:         "discount rounded to the currency",
+			price:        vnd("500000000"),
+			discount:     vnd("0.6"),
+			wantDiscount: vnd("1"),
+			wantTax:      vnd("132500000"),
+			wantTotal:    vnd("632499999"),
+		},
+		{name: "negative discount", price: vnd("500000000"), discount: vnd("-1"), wantErr: true},
+		{name: "discount above price", price: vnd("500000000"), discount: vnd("500000001"), wantErr: true},
+		{name: "discount in another currency", price: vnd("500000000"), discount: NewMoney(decimal.NewFromInt(10), "USD"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discount, tax, total, err := NewOrderAmounts(tt.price, tt.discount, region, rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewOrderAmounts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !discount.Amount.Equal(tt.wantDiscount.Amount) || !tax.Amount.Equal(tt.wantTax.Amount) || !total.Amount.Equal(tt.wantTotal.Amount) {
+				t.Errorf("NewOrderAmounts() = %s, %s, %s, want %s, %s, %s", discount, tax, total, tt.wantDiscount, tt.wantTax, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type PaymentKind string
+
+const (
+	PaymentKindDeposit PaymentKind = "deposit"
+	PaymentKindBalance PaymentKind = "balance"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending    PaymentStatus = "pending"
+	PaymentStatusAuthorized PaymentStatus = "authorized"
+	PaymentStatusCaptured   PaymentStatus = "captured"
+	PaymentStatusRefunded   PaymentStatus = "refunded"
+	PaymentStatusFailed     PaymentStatus = "failed"
+	PaymentStatusCancelled  PaymentStatus = "cancelled"
+)
+
+var (
+	ErrPaymentNotFound          = errors.New("payment not found")
+	ErrPaymentState             = errors.New("payment cannot be changed in its current status")
+	ErrPaymentForbidden         = errors.New("only staff may capture or refund payments")
+	ErrInvalidPaymentAmount     = errors.New("invalid payment amount")
+	ErrInvalidWebhookSignature  = errors.New("invalid webhook signature")
+	ErrOrderNotPayable          = errors.New("order does not accept payments")
+	ErrDuplicateWebhookDelivery = errors.New("webhook event already processed")
+)
+
+// IsOpen reports whether the provider may still change the payment without
+// us asking, e.g. a pending payment being authorized or failing.
+func (s PaymentStatus) IsOpen() bool {
+	return s == PaymentStatusPending || s == PaymentStatusAuthorized
+}
+
+// paymentStatusRank orders statuses so that a late or replayed provider
+// update never moves a payment backwards.
+var paymentStatusRank = map[PaymentStatus]int{
+	PaymentStatusPending:    0,
+	PaymentStatusAuthorized: 1,
+	PaymentStatusFailed:     2,
+	PaymentStatusCancelled:  2,
+	PaymentStatusCaptured:   3,
+	PaymentStatusRefunded:   4,
+}
+
+// Payment is one payment intent against an order. Amount is what was
+// authorized; Captured and Refunded track what actually moved. All amounts
+// are in the order's currency.
+type Payment struct {
+	ID          uuid.UUID     `json:"id"`
+	OrderID     uuid.UUID     `json:"order_id"`
+	Customer    string        `json:"customer"`
+	Kind        PaymentKind   `json:"kind"`
+	Amount      Money         `json:"amount"`
+	Captured    Money         `json:"captured"`
+	Refunded    Money         `json:"refunded"`
+	Status      PaymentStatus `json:"status"`
+	Provider    string        `json:"provider"`
+	ProviderRef string        `json:"provider_ref"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// Net is the amount the payment contributes to its order.
+func (p Payment) Net() Money {
+	return Money{Amount: p.Captured.Amount.Sub(p.Refunded.Amount), Currency: p.Captured.Currency}
+}
+
+// Apply merges the provider's view of the payment. Amounts only grow and
+// the status only moves forward, so replayed or out-of-order webhooks are
+// harmless. It reports whether anything changed.
+func (p *Payment) Apply(snapshot PaymentSnapshot) bool {
+	changed := false
+
+	if snapshot.Captured.GreaterThan(p.Captured.Amount) {
+		p.Captured.Amount = snapshot.Captured
+		changed = true
+	}
+	if snapshot.Refunded.GreaterThan(p.Refunded.Amount) {
+		p.Refunded.Amount = snapshot.Refunded
+		changed = true
+	}
+
+	status := p.Status
+	if rank, ok := paymentStatusRank[snapshot.Status]; ok && rank > paymentStatusRank[status] {
+		status = snapshot.Status
+	}
+
+	// The amounts are the source of truth once money has moved.
+	switch {
+	case p.Captured.Amount.IsPositive() && p.Refunded.Amount.GreaterThanOrEqual(p.Captured.Amount):
+		status = PaymentStatusRefunded
+	case p.Captured.Amount.IsPositive():
+		status = PaymentStatusCaptured
+	}
+
+	if status != p.Status {
+		p.Status = status
+		changed = true
+	}
+
+	return changed
+}
+
+// PaymentSnapshot is a payment intent as the gateway sees it. Captured and
+// Refunded are running totals, not the amount of the latest operation.
+type PaymentSnapshot struct {
+	ProviderRef string          `json:"provider_ref"`
+	Status      PaymentStatus   `json:"status"`
+	Amount      decimal.Decimal `json:"amount"`
+	Captured    decimal.Decimal `json:"captured"`
+	Refunded    decimal.Decimal `json:"refunded"`
+	Currency    string          `json:"currency"`
+}
+
+// PaymentEvent is a webhook callback from a gateway. ID is unique per
+// event and is used to drop redeliveries.
+type PaymentEvent struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payment PaymentSnapshot `json:"payment"`
+}
+
+// PaymentRequest starts a payment on an order. Amount is required for a
+// deposit; a balance payment defaults to the outstanding balance.
+type PaymentRequest struct {
+	Kind   PaymentKind      `json:"kind"`
+	Amount *decimal.Decimal `json:"amount,omitempty"`
+}
+
+// PaymentAmountRequest is the body of capture and refund calls. Without an
+// amount the whole authorized (or captured) amount is used.
+type PaymentAmountRequest struct {
+	Amount *decimal.Decimal `json:"amount,omitempty"`
+}
+
+func ValidatePaymentRequest(paymentRequest PaymentRequest) error {
+	switch paymentRequest.Kind {
+	case PaymentKindDeposit:
+		if paymentRequest.Amount == nil {
+			return fmt.Errorf("%w: deposit amount is Required", ErrInvalidPaymentAmount)
+		}
+	case PaymentKindBalance:
+	default:
+		return fmt.Errorf("%w: kind must be deposit or balance", ErrInvalidPaymentAmount)
+	}
+	return nil
+}
+
+// PaymentAmount resolves an optional requested amount against the most
+// that may be charged (or refunded) and rounds it to the currency.
+func PaymentAmount(requested *decimal.Decimal, limit Money) (Money, error) {
+	amount := limit
+	if requested != nil {
+		amount = NewMoney(*requested, limit.Currency)
+	}
+
+	if err := ValidateMoney(amount); err != nil {
+		return Money{}, fmt.Errorf("%w: %v", ErrInvalidPaymentAmount, err)
+	}
+	if !amount.IsPositive() {
+		return Money{}, fmt.Errorf("%w: amount must be positive", ErrInvalidPaymentAmount)
+	}
+	if amount.Amount.GreaterThan(limit.Amount) {
+		return Money{}, fmt.Errorf("%w: amount exceeds %s", ErrInvalidPaymentAmount, limit)
+	}
+	return amount, nil
+}
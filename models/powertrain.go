@@ -0,0 +1,64 @@
+package models
+
+type Powertrain string
+
+const (
+	PowertrainCombustion Powertrain = "combustion"
+	PowertrainElectric   Powertrain = "electric"
+	PowertrainHybrid     Powertrain = "hybrid"
+	PowertrainFuelCell   Powertrain = "fuel_cell"
+)
+
+// ChargingStandardsSupported lists the connector names accepted in
+// Engine.ChargingStandards, lowercase.
+var ChargingStandardsSupported = []string{"type1", "type2", "ccs1", "ccs2", "chademo", "gb_t", "nacs"}
+
+func (p Powertrain) IsValid() bool {
+	switch p {
+	case PowertrainCombustion, PowertrainElectric, PowertrainHybrid, PowertrainFuelCell:
+		return true
+	}
+	return false
+}
+
+// Powertrain returns the powertrain a car with this fuel type must have.
+func (f FuelType) Powertrain() Powertrain {
+	switch f {
+	case FuelTypeElectric:
+		return PowertrainElectric
+	case FuelTypeHybrid, FuelTypePlugInHybrid:
+		return PowertrainHybrid
+	case FuelTypeHydrogen:
+		return PowertrainFuelCell
+	default:
+		return PowertrainCombustion
+	}
+}
+
+func isSupportedChargingStandard(standard string) bool {
+	for _, supported := range ChargingStandardsSupported {
+		if standard == supported {
+			return true
+		}
+	}
+	return false
+}
+
+type Transmission string
+
+const (
+	TransmissionManual      Transmission = "manual"
+	TransmissionAutomatic   Transmission = "automatic"
+	TransmissionCVT         Transmission = "cvt"
+	TransmissionDCT         Transmission = "dct"
+	TransmissionAMT         Transmission = "amt"
+	TransmissionSingleSpeed Transmission = "single_speed"
+)
+
+func (t Transmission) IsValid() bool {
+	switch t {
+	case TransmissionManual, TransmissionAutomatic, TransmissionCVT, TransmissionDCT, TransmissionAMT, TransmissionSingleSpeed:
+		return true
+	}
+	return false
+}
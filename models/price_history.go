@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PriceChange struct {
+	ID        uuid.UUID `json:"id"`
+	CarID     uuid.UUID `json:"car_id"`
+	OldPrice  Money     `json:"old_price"`
+	NewPrice  Money     `json:"new_price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
@@ -0,0 +1,257 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type PromotionKind string
+
+const (
+	// PromotionPercentOff takes Value percent off the running price.
+	PromotionPercentOff PromotionKind = "percent_off"
+	// PromotionAmountOff takes Value off, in Currency.
+	PromotionAmountOff PromotionKind = "amount_off"
+	// PromotionGift adds Gift (e.g. a free accessory) without changing the
+	// price.
+	PromotionGift PromotionKind = "gift"
+)
+
+var ErrPromotionNotFound = errors.New("promotion not found")
+
+// Promotion is a marketing rule. Every condition that is set must match
+// the car; an unset condition matches everything. Price bounds are in
+// Currency and only match cars priced in that currency.
+//
+// When several promotions match they are applied in descending Priority.
+// A promotion that is not Stackable only applies if nothing has been
+// applied before it, and nothing applies after it.
+type Promotion struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Kind        PromotionKind   `json:"kind"`
+	Value       decimal.Decimal `json:"value"`
+	Currency    *string         `json:"currency,omitempty"`
+	Gift        *string         `json:"gift,omitempty"`
+
+	Brand    *string          `json:"brand,omitempty"`
+	FuelType *FuelType        `json:"fuel_type,omitempty"`
+	YearFrom *int             `json:"year_from,omitempty"`
+	YearTo   *int             `json:"year_to,omitempty"`
+	MinPrice *decimal.Decimal `json:"min_price,omitempty"`
+	MaxPrice *decimal.Decimal `json:"max_price,omitempty"`
+
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Stackable bool       `json:"stackable"`
+	Priority  int        `json:"priority"`
+	Active    bool       `json:"active"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PromotionRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Kind        PromotionKind   `json:"kind"`
+	Value       decimal.Decimal `json:"value"`
+	Currency    *string         `json:"currency,omitempty"`
+	Gift        *string         `json:"gift,omitempty"`
+
+	Brand    *string          `json:"brand,omitempty"`
+	FuelType *FuelType        `json:"fuel_type,omitempty"`
+	YearFrom *int             `json:"year_from,omitempty"`
+	YearTo   *int             `json:"year_to,omitempty"`
+	MinPrice *decimal.Decimal `json:"min_price,omitempty"`
+	MaxPrice *decimal.Decimal `json:"max_price,omitempty"`
+
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Stackable bool       `json:"stackable"`
+	Priority  int        `json:"priority"`
+	Active    bool       `json:"active"`
+}
+
+func ValidatePromotionRequest(promotionRequest PromotionRequest) error {
+	if strings.TrimSpace(promotionRequest.Name) == "" {
+		return errors.New("Name is Required")
+	}
+
+	if promotionRequest.Currency != nil && !IsValidCurrency(*promotionRequest.Currency) {
+		return fmt.Errorf("Currency %s is not supported", *promotionRequest.Currency)
+	}
+
+	switch promotionRequest.Kind {
+	case PromotionPercentOff:
+		if !promotionRequest.Value.IsPositive() || promotionRequest.Value.GreaterThan(hundred) {
+			return errors.New("Percent off must be above 0 and at most 100")
+		}
+	case PromotionAmountOff:
+		if !promotionRequest.Value.IsPositive() {
+			return errors.New("Amount off must be positive")
+		}
+		if promotionRequest.Currency == nil {
+			return errors.New("Currency is Required for an amount off")
+		}
+	case PromotionGift:
+		if promotionRequest.Gift == nil || strings.TrimSpace(*promotionRequest.Gift) == "" {
+			return errors.New("Gift is Required for a gift promotion")
+		}
+		if !promotionRequest.Value.IsZero() {
+			return errors.New("Gift promotions have no value")
+		}
+	default:
+		return errors.New("Kind must be percent_off, amount_off or gift")
+	}
+
+	if promotionRequest.FuelType != nil && !promotionRequest.FuelType.IsValid() {
+		return errors.New("FuelType in: " + fuelTypeList())
+	}
+
+	if promotionRequest.YearFrom != nil && promotionRequest.YearTo != nil && *promotionRequest.YearFrom > *promotionRequest.YearTo {
+		return errors.New("Year from must not be after year to")
+	}
+
+	if promotionRequest.MinPrice != nil || promotionRequest.MaxPrice != nil {
+		if promotionRequest.Currency == nil {
+			return errors.New("Currency is Required for a price range")
+		}
+		if promotionRequest.MinPrice != nil && promotionRequest.MaxPrice != nil && promotionRequest.MinPrice.GreaterThan(*promotionRequest.MaxPrice) {
+			return errors.New("Min price must not exceed max price")
+		}
+	}
+
+	if promotionRequest.StartsAt != nil && promotionRequest.EndsAt != nil && !promotionRequest.EndsAt.After(*promotionRequest.StartsAt) {
+		return errors.New("Promotion must end after it starts")
+	}
+
+	return nil
+}
+
+// IsLive reports whether the promotion is switched on and within its
+// validity window at the given time. EndsAt is exclusive.
+func (p Promotion) IsLive(at time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !at.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Matches reports whether every condition of the promotion holds for the
+// car.
+func (p Promotion) Matches(car Car) bool {
+	if p.Brand != nil && !strings.EqualFold(*p.Brand, car.Brand) {
+		return false
+	}
+	if p.FuelType != nil && *p.FuelType != car.FuelType {
+		return false
+	}
+
+	if p.YearFrom != nil || p.YearTo != nil {
+		year, err := strconv.Atoi(car.Year)
+		if err != nil {
+			return false
+		}
+		if p.YearFrom != nil && year < *p.YearFrom {
+			return false
+		}
+		if p.YearTo != nil && year > *p.YearTo {
+			return false
+		}
+	}
+
+	if p.MinPrice != nil || p.MaxPrice != nil {
+		if p.Currency == nil || *p.Currency != car.Price.Currency {
+			return false
+		}
+		if p.MinPrice != nil && car.Price.Amount.LessThan(*p.MinPrice) {
+			return false
+		}
+		if p.MaxPrice != nil && car.Price.Amount.GreaterThan(*p.MaxPrice) {
+			return false
+		}
+	}
+
+	// An amount off in another currency cannot be applied.
+	if p.Kind == PromotionAmountOff && (p.Currency == nil || *p.Currency != car.Price.Currency) {
+		return false
+	}
+
+	return true
+}
+
+// AppliedPromotion is a promotion that changed a car's effective price or
+// added a gift.
+type AppliedPromotion struct {
+	ID       uuid.UUID     `json:"id"`
+	Name     string        `json:"name"`
+	Kind     PromotionKind `json:"kind"`
+	Discount Money         `json:"discount"`
+	Gift     *string       `json:"gift,omitempty"`
+}
+
+// ApplyPromotions works out the car's effective price from the promotions
+// live at the given time. Percentages apply to the price left after
+// earlier promotions; the price never goes below zero.
+func ApplyPromotions(car Car, promotions []Promotion, at time.Time) (Money, []AppliedPromotion) {
+	matching := make([]Promotion, 0, len(promotions))
+	for _, promotion := range promotions {
+		if promotion.IsLive(at) && promotion.Matches(car) {
+			matching = append(matching, promotion)
+		}
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		if matching[i].Priority != matching[j].Priority {
+			return matching[i].Priority > matching[j].Priority
+		}
+		return matching[i].ID.String() < matching[j].ID.String()
+	})
+
+	price := car.Price
+	applied := []AppliedPromotion{}
+
+	for _, promotion := range matching {
+		if !promotion.Stackable && len(applied) > 0 {
+			continue
+		}
+
+		discount := NewMoney(decimal.Zero, price.Currency)
+		switch promotion.Kind {
+		case PromotionPercentOff:
+			discount = price.Mul(promotion.Value.Div(hundred)).Round()
+		case PromotionAmountOff:
+			discount = NewMoney(decimal.Min(promotion.Value, price.Amount), price.Currency).Round()
+		}
+
+		price.Amount = price.Amount.Sub(discount.Amount)
+		applied = append(applied, AppliedPromotion{
+			ID:       promotion.ID,
+			Name:     promotion.Name,
+			Kind:     promotion.Kind,
+			Discount: discount,
+			Gift:     promotion.Gift,
+		})
+
+		if !promotion.Stackable {
+			break
+		}
+	}
+
+	return price, applied
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func promotionID(n int) uuid.UUID {
+	return uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", n))
+}
+
+func TestApplyPromotions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour)
+	usd := "USD"
+	eur := "EUR"
+	toyota := "Toyota"
+	mats := "Floor mats"
+
+	car := Car{Brand: "Honda", Year: "2023", FuelType: FuelTypePetrol, Price: NewMoney(decimal.RequireFromString("20000"), "USD")}
+
+	promotion := func(id int, kind PromotionKind, value string, priority int, stackable bool) Promotion {
+		return Promotion{
+			ID:        promotionID(id),
+			Name:      fmt.Sprintf("promotion %d", id),
+			Kind:      kind,
+			Value:     decimal.RequireFromString(value),
+			Currency:  &usd,
+			Priority:  priority,
+			Stackable: stackable,
+			Active:    true,
+		}
+	}
+	with := func(p Promotion, change func(*Promotion)) Promotion {
+		change(&p)
+		return p
+	}
+
+	tests := []struct {
+		name       string
+		promotions []Promotion
+		wantPrice  string
+		wantIDs    []int
+	}{
+		{
+			name:      "no promotions",
+			wantPrice: "20000",
+		},
+		{
+			name: "higher priority applies first",
+			promotions: []Promotion{
+				promotion(1, PromotionPercentOff, "10", 1, true),
+				promotion(2, PromotionAmountOff, "1000", 5, true),
+			},
+			wantPrice: "17100",
+			wantIDs:   []int{2, 1},
+		},
+		{
+			name: "equal priority ordered by ID",
+			promotions: []Promotion{
+				promotion(2, PromotionAmountOff, "1000", 1, true),
+				promotion(1, PromotionPercentOff, "10", 1, true),
+			},
+			wantPrice: "17000",
+			wantIDs:   []int{1, 2},
+		},
+		{
+			name: "percentages compound on the remaining price",
+			promotions: []Promotion{
+				promotion(1, PromotionPercentOff, "10", 2, true),
+				promotion(2, PromotionPercentOff, "10", 1, true),
+			},
+			wantPrice: "16200",
+			wantIDs:   []int{1, 2},
+		},
+		{
+			name: "non-stackable first stops the rest",
+			promotions: []Promotion{
+				promotion(1, PromotionPercentOff, "15", 5, false),
+				promotion(2, PromotionAmountOff, "1000", 1, true),
+			},
+			wantPrice: "17000",
+			wantIDs:   []int{1},
+		},
+		{
+			name: "non-stackable skipped once something applied",
+			promotions: []Promotion{
+				promotion(1, PromotionAmountOff, "1000", 5, true),
+				promotion(2, PromotionPercentOff, "50", 3, false),
+				promotion(3, PromotionAmountOff, "500", 1, true),
+			},
+			wantPrice: "18500",
+			wantIDs:   []int{1, 3},
+		},
+		{
+			name: "amount off capped at the price",
+			promotions: []Promotion{
+				promotion(1, PromotionAmountOff, "15000", 2, true),
+				promotion(2, PromotionAmountOff, "15000", 1, true),
+			},
+			wantPrice: "0",
+			wantIDs:   []int{1, 2},
+		},
+		{
+			name: "amount off in another currency ignored",
+			promotions: []Promotion{
+				with(promotion(1, PromotionAmountOff, "1000", 1, true), func(p *Promotion) { p.Currency = &eur }),
+			},
+			wantPrice: "20000",
+		},
+		{
+			name: "gift keeps the price",
+			promotions: []Promotion{
+				with(promotion(1, PromotionGift, "0", 1, true), func(p *Promotion) { p.Gift = &mats }),
+			},
+			wantPrice: "20000",
+			wantIDs:   []int{1},
+		},
+		{
+			name: "inactive, expired and non-matching ignored",
+			promotions: []Promotion{
+				with(promotion(1, PromotionPercentOff, "10", 1, true), func(p *Promotion) { p.Active = false }),
+				with(promotion(2, PromotionPercentOff, "10", 1, true), func(p *Promotion) { p.EndsAt = &yesterday }),
+				with(promotion(3, PromotionPercentOff, "10", 1, true), func(p *Promotion) { p.Brand = &toyota }),
+			},
+			wantPrice: "20000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, applied := ApplyPromotions(car, tt.promotions, now)
+			if !price.Amount.Equal(decimal.RequireFromString(tt.wantPrice)) || price.Currency != "USD" {
+				t.Errorf("ApplyPromotions() price = %s, want %s USD", price, tt.wantPrice)
+			}
+
+			if len(applied) != len(tt.wantIDs) {
+				t.Fatalf("ApplyPromotions() applied %d promotions, want %d", len(applied), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if want := promotionID(id); applied[i].ID != want {
+					t.Errorf("applied[%d] = %s, want %s", i, applied[i].ID, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "active"
+	ReservationStatusCancelled ReservationStatus = "cancelled"
+	ReservationStatusExpired   ReservationStatus = "expired"
+
+	// ReservationStatusConverted marks a hold that became a confirmed order.
+	ReservationStatusConverted ReservationStatus = "converted"
+)
+
+const (
+	DefaultReservationHoldHours = 48
+	MaxReservationHoldHours     = 7 * 24
+)
+
+var (
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrReservationInactive = errors.New("reservation is no longer active")
+	ErrUnitNotAvailable    = errors.New("inventory unit is not available")
+)
+
+// Reservation is a time-limited hold by a customer on one inventory unit.
+// While it is active the unit's stock status is reserved.
+type Reservation struct {
+	ID        uuid.UUID         `json:"id"`
+	UnitID    uuid.UUID         `json:"unit_id"`
+	Customer  string            `json:"customer"`
+	Status    ReservationStatus `json:"status"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type ReservationRequest struct {
+	UnitID    uuid.UUID `json:"unit_id"`
+	HoldHours int       `json:"hold_hours"`
+}
+
+func ValidateReservationRequest(reservationRequest ReservationRequest) error {
+	if reservationRequest.UnitID == uuid.Nil {
+		return errors.New("Unit ID is Required")
+	}
+	if reservationRequest.HoldHours < 0 || reservationRequest.HoldHours > MaxReservationHoldHours {
+		return errors.New("Hold must be between 1 and 168 hours")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// SavedSearchCriteria are the filters of a saved search. Every criterion
+// that is set must match a car; Query matches when each of its words
+// appears in the car's name or brand. Price bounds are in Currency and only
+// match cars priced in that currency. Stored as JSONB.
+type SavedSearchCriteria struct {
+	Query        string           `json:"query,omitempty"`
+	Brand        *string          `json:"brand,omitempty"`
+	FuelType     *FuelType        `json:"fuel_type,omitempty"`
+	YearFrom     *int             `json:"year_from,omitempty"`
+	YearTo       *int             `json:"year_to,omitempty"`
+	MinPrice     *decimal.Decimal `json:"min_price,omitempty"`
+	MaxPrice     *decimal.Decimal `json:"max_price,omitempty"`
+	Currency     *string          `json:"currency,omitempty"`
+	DealershipID *uuid.UUID       `json:"dealership_id,omitempty"`
+}
+
+func (c SavedSearchCriteria) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *SavedSearchCriteria) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// Matches reports whether the car meets every criterion that is set.
+func (c SavedSearchCriteria) Matches(car Car) bool {
+	if c.Query != "" {
+		haystack := strings.ToLower(car.Name + " " + car.Brand)
+		for _, word := range strings.Fields(strings.ToLower(c.Query)) {
+			if !strings.Contains(haystack, word) {
+				return false
+			}
+		}
+	}
+
+	if c.Brand != nil && !strings.EqualFold(*c.Brand, car.Brand) {
+		return false
+	}
+	if c.FuelType != nil && *c.FuelType != car.FuelType {
+		return false
+	}
+	if c.DealershipID != nil && (car.DealershipID == nil || *car.DealershipID != *c.DealershipID) {
+		return false
+	}
+
+	if c.YearFrom != nil || c.YearTo != nil {
+		year, err := strconv.Atoi(car.Year)
+		if err != nil {
+			return false
+		}
+		if c.YearFrom != nil && year < *c.YearFrom {
+			return false
+		}
+		if c.YearTo != nil && year > *c.YearTo {
+			return false
+		}
+	}
+
+	if c.MinPrice != nil || c.MaxPrice != nil {
+		if c.Currency == nil || *c.Currency != car.Price.Currency {
+			return false
+		}
+		if c.MinPrice != nil && car.Price.Amount.LessThan(*c.MinPrice) {
+			return false
+		}
+		if c.MaxPrice != nil && car.Price.Amount.GreaterThan(*c.MaxPrice) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SavedSearch is a customer's stored filter. Cars created or updated after
+// LastEvaluatedAt have not been checked against it yet.
+type SavedSearch struct {
+	ID              uuid.UUID           `json:"id"`
+	Customer        string              `json:"customer"`
+	Name            string              `json:"name"`
+	Criteria        SavedSearchCriteria `json:"criteria"`
+	LastEvaluatedAt time.Time           `json:"last_evaluated_at"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+type SavedSearchRequest struct {
+	Name     string              `json:"name"`
+	Criteria SavedSearchCriteria `json:"criteria"`
+}
+
+func ValidateSavedSearchRequest(searchRequest SavedSearchRequest) error {
+	if strings.TrimSpace(searchRequest.Name) == "" {
+		return errors.New("Name is Required")
+	}
+
+	criteria := searchRequest.Criteria
+
+	// Currency only qualifies the price bounds and filters nothing alone.
+	filters := criteria
+	filters.Currency = nil
+	if filters == (SavedSearchCriteria{}) {
+		return errors.New("At least one search criterion is Required")
+	}
+
+	if criteria.FuelType != nil && !criteria.FuelType.IsValid() {
+		return errors.New("FuelType in: " + fuelTypeList())
+	}
+
+	if criteria.YearFrom != nil && criteria.YearTo != nil && *criteria.YearFrom > *criteria.YearTo {
+		return errors.New("Year from must not be after year to")
+	}
+
+	if criteria.Currency != nil && !IsValidCurrency(*criteria.Currency) {
+		return fmt.Errorf("Currency %s is not supported", *criteria.Currency)
+	}
+
+	if criteria.MinPrice != nil || criteria.MaxPrice != nil {
+		if criteria.Currency == nil {
+			return errors.New("Currency is Required for a price range")
+		}
+		if criteria.MinPrice != nil && criteria.MaxPrice != nil && criteria.MinPrice.GreaterThan(*criteria.MaxPrice) {
+			return errors.New("Min price must not exceed max price")
+		}
+	}
+
+	return nil
+}
+
+// SavedSearchMatch is a car that met a saved search when it was created or
+// last updated. Car is filled in when matches are listed.
+type SavedSearchMatch struct {
+	SearchID  uuid.UUID `json:"search_id"`
+	CarID     uuid.UUID `json:"car_id"`
+	MatchedAt time.Time `json:"matched_at"`
+	Car       *Car      `json:"car,omitempty"`
+}
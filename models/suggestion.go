@@ -0,0 +1,11 @@
+package models
+
+type Suggestion struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type Suggestions struct {
+	Brands []Suggestion `json:"brands"`
+	Models []Suggestion `json:"models"`
+}
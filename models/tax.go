@@ -0,0 +1,238 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TaxCurrency is the currency Vietnamese taxes and fees are set in. Car
+// prices in other currencies are converted before on-road prices are
+// worked out.
+const TaxCurrency = "VND"
+
+var (
+	ErrTaxRegionNotFound = errors.New("tax region not found")
+	ErrSCTRuleNotFound   = errors.New("special consumption tax rule not found")
+	ErrNoSCTRule         = errors.New("no special consumption tax rule matches the car")
+)
+
+var regionCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{1,16}$`)
+
+// TaxRegion holds the registration charges of one province or city.
+// Percentages are e.g. 12 for 12%; fees are amounts in TaxCurrency.
+// Electric cars pay EVRegistrationFeePercent instead of
+// RegistrationFeePercent.
+type TaxRegion struct {
+	Code                     string          `json:"code"`
+	Name                     string          `json:"name"`
+	VATPercent               decimal.Decimal `json:"vat_percent"`
+	RegistrationFeePercent   decimal.Decimal `json:"registration_fee_percent"`
+	EVRegistrationFeePercent decimal.Decimal `json:"ev_registration_fee_percent"`
+	PlateFee                 decimal.Decimal `json:"plate_fee"`
+	RoadMaintenanceFee       decimal.Decimal `json:"road_maintenance_fee"`
+	InsuranceFee             decimal.Decimal `json:"insurance_fee"`
+	InspectionFee            decimal.Decimal `json:"inspection_fee"`
+	CreatedAt                time.Time       `json:"created_at"`
+	UpdatedAt                time.Time       `json:"updated_at"`
+}
+
+type TaxRegionRequest struct {
+	Name                     string          `json:"name"`
+	VATPercent               decimal.Decimal `json:"vat_percent"`
+	RegistrationFeePercent   decimal.Decimal `json:"registration_fee_percent"`
+	EVRegistrationFeePercent decimal.Decimal `json:"ev_registration_fee_percent"`
+	PlateFee                 decimal.Decimal `json:"plate_fee"`
+	RoadMaintenanceFee       decimal.Decimal `json:"road_maintenance_fee"`
+	InsuranceFee             decimal.Decimal `json:"insurance_fee"`
+	InspectionFee            decimal.Decimal `json:"inspection_fee"`
+}
+
+// NormalizeRegionCode upper-cases and trims a region code, e.g. "hn" to
+// "HN".
+func NormalizeRegionCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func ValidateTaxRegionRequest(code string, regionRequest TaxRegionRequest) error {
+	if !regionCodePattern.MatchString(code) {
+		return errors.New("Region code must be 1-16 letters, digits, '-' or '_'")
+	}
+	if strings.TrimSpace(regionRequest.Name) == "" {
+		return errors.New("Name is Required")
+	}
+
+	percents := map[string]decimal.Decimal{
+		"VAT":                 regionRequest.VATPercent,
+		"Registration fee":    regionRequest.RegistrationFeePercent,
+		"EV registration fee": regionRequest.EVRegistrationFeePercent,
+	}
+	for name, percent := range percents {
+		if percent.IsNegative() || percent.GreaterThan(hundred) {
+			return fmt.Errorf("%s must be between 0 and 100 percent", name)
+		}
+	}
+
+	fees := map[string]decimal.Decimal{
+		"Plate fee":            regionRequest.PlateFee,
+		"Road maintenance fee": regionRequest.RoadMaintenanceFee,
+		"Insurance fee":        regionRequest.InsuranceFee,
+		"Inspection fee":       regionRequest.InspectionFee,
+	}
+	for name, fee := range fees {
+		if err := ValidateMoney(NewMoney(fee, TaxCurrency)); err != nil || fee.IsNegative() {
+			return fmt.Errorf("%s must be a non-negative whole %s amount", name, TaxCurrency)
+		}
+	}
+
+	return nil
+}
+
+// SCTRule sets the special consumption tax for engines above
+// MinDisplacementCC up to and including MaxDisplacementCC (no upper bound
+// when nil). A rule without a fuel type applies to any fuel that has no
+// rule of its own.
+type SCTRule struct {
+	ID                uuid.UUID       `json:"id"`
+	FuelType          *FuelType       `json:"fuel_type,omitempty"`
+	MinDisplacementCC int64           `json:"min_displacement_cc"`
+	MaxDisplacementCC *int64          `json:"max_displacement_cc,omitempty"`
+	RatePercent       decimal.Decimal `json:"rate_percent"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+type SCTRuleRequest struct {
+	FuelType          *FuelType       `json:"fuel_type,omitempty"`
+	MinDisplacementCC int64           `json:"min_displacement_cc"`
+	MaxDisplacementCC *int64          `json:"max_displacement_cc,omitempty"`
+	RatePercent       decimal.Decimal `json:"rate_percent"`
+}
+
+func ValidateSCTRuleRequest(ruleRequest SCTRuleRequest) error {
+	if ruleRequest.FuelType != nil && !ruleRequest.FuelType.IsValid() {
+		return errors.New("FuelType in: " + fuelTypeList())
+	}
+	if ruleRequest.MinDisplacementCC < 0 {
+		return errors.New("Min displacement must not be negative")
+	}
+	if ruleRequest.MaxDisplacementCC != nil && *ruleRequest.MaxDisplacementCC <= ruleRequest.MinDisplacementCC {
+		return errors.New("Max displacement must be above min displacement")
+	}
+	if ruleRequest.RatePercent.IsNegative() || ruleRequest.RatePercent.GreaterThan(decimal.NewFromInt(1000)) {
+		return errors.New("Rate must be between 0 and 1000 percent")
+	}
+	return nil
+}
+
+func (r SCTRule) covers(displacementCC int64) bool {
+	if displacementCC < r.MinDisplacementCC {
+		return false
+	}
+	// The lower bound is exclusive except for a rule starting at zero, so
+	// engines without a displacement (electric) are covered.
+	if displacementCC == r.MinDisplacementCC && r.MinDisplacementCC != 0 {
+		return false
+	}
+	return r.MaxDisplacementCC == nil || displacementCC <= *r.MaxDisplacementCC
+}
+
+// FindSCTRule picks the rule for the fuel type and displacement, preferring
+// rules written for the fuel type over generic ones.
+func FindSCTRule(rules []SCTRule, fuelType FuelType, displacementCC int64) (SCTRule, error) {
+	var generic *SCTRule
+
+	for i, rule := range rules {
+		if !rule.covers(displacementCC) {
+			continue
+		}
+		if rule.FuelType != nil && *rule.FuelType == fuelType {
+			return rule, nil
+		}
+		if rule.FuelType == nil && generic == nil {
+			generic = &rules[i]
+		}
+	}
+
+	if generic != nil {
+		return *generic, nil
+	}
+	return SCTRule{}, fmt.Errorf("%w: %s, %d cc", ErrNoSCTRule, fuelType, displacementCC)
+}
+
+// PriceLine is one component of an on-road price. RatePercent is set for
+// components worked out as a percentage.
+type PriceLine struct {
+	Name        string           `json:"name"`
+	RatePercent *decimal.Decimal `json:"rate_percent,omitempty"`
+	Amount      Money            `json:"amount"`
+}
+
+// OnRoadPrice is what it costs to drive the car away in a region.
+type OnRoadPrice struct {
+	Region     string      `json:"region"`
+	RegionName string      `json:"region_name"`
+	Lines      []PriceLine `json:"lines"`
+	Total      Money       `json:"total"`
+}
+
+// SalesTax is the special consumption tax and VAT on a pre-tax price, in the
+// price's currency, worked out the same way as in NewOnRoadPrice.
+func SalesTax(price Money, region TaxRegion, rule SCTRule) Money {
+	price = price.Round()
+	sct := price.Mul(rule.RatePercent.Div(hundred)).Round()
+	vat := NewMoney(price.Amount.Add(sct.Amount), price.Currency).Mul(region.VATPercent.Div(hundred)).Round()
+	return NewMoney(sct.Amount.Add(vat.Amount), price.Currency)
+}
+
+// NewOnRoadPrice builds the breakdown for a pre-tax price in TaxCurrency:
+//
+//	special consumption tax = price * SCT rate
+//	VAT                     = (price + SCT) * VAT rate
+//	registration fee        = (price + SCT + VAT) * registration rate
+//
+// followed by the region's flat fees for plates, one year of road
+// maintenance, compulsory insurance and the first inspection.
+func NewOnRoadPrice(price Money, region TaxRegion, rule SCTRule, fuelType FuelType) OnRoadPrice {
+	onRoad := OnRoadPrice{Region: region.Code, RegionName: region.Name}
+	total := price.Round()
+
+	add := func(name string, rate *decimal.Decimal, amount Money) {
+		amount = amount.Round()
+		onRoad.Lines = append(onRoad.Lines, PriceLine{Name: name, RatePercent: rate, Amount: amount})
+		total.Amount = total.Amount.Add(amount.Amount)
+	}
+	percentOf := func(base Money, rate decimal.Decimal) Money {
+		return base.Mul(rate.Div(hundred))
+	}
+	flat := func(fee decimal.Decimal) Money {
+		return NewMoney(fee, price.Currency)
+	}
+
+	onRoad.Lines = append(onRoad.Lines, PriceLine{Name: "price", Amount: total})
+
+	sctRate := rule.RatePercent
+	add("special consumption tax", &sctRate, percentOf(total, sctRate))
+
+	vatRate := region.VATPercent
+	add("VAT", &vatRate, percentOf(total, vatRate))
+
+	registrationRate := region.RegistrationFeePercent
+	if fuelType == FuelTypeElectric {
+		registrationRate = region.EVRegistrationFeePercent
+	}
+	add("registration fee", &registrationRate, percentOf(total, registrationRate))
+
+	add("plate fee", nil, flat(region.PlateFee))
+	add("road maintenance fee", nil, flat(region.RoadMaintenanceFee))
+	add("compulsory insurance", nil, flat(region.InsuranceFee))
+	add("inspection fee", nil, flat(region.InspectionFee))
+
+	onRoad.Total = total
+	return onRoad
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// hanoi mirrors the HN row seeded in store/schema.sql.
+var hanoi = TaxRegion{
+	Code:                     "HN",
+	Name:                     "Hà Nội",
+	VATPercent:               decimal.NewFromInt(10),
+	RegistrationFeePercent:   decimal.NewFromInt(12),
+	EVRegistrationFeePercent: decimal.Zero,
+	PlateFee:                 decimal.NewFromInt(20000000),
+	RoadMaintenanceFee:       decimal.NewFromInt(1560000),
+	InsuranceFee:             decimal.NewFromInt(480700),
+	InspectionFee:            decimal.NewFromInt(340000),
+}
+
+func TestFindSCTRule(t *testing.T) {
+	hybrid := FuelTypeHybrid
+	electric := FuelTypeElectric
+	upTo := func(cc int64) *int64 { return &cc }
+
+	rules := []SCTRule{
+		{ID: uuid.New(), MinDisplacementCC: 0, MaxDisplacementCC: upTo(1500), RatePercent: decimal.NewFromInt(35)},
+		{ID: uuid.New(), MinDisplacementCC: 1500, MaxDisplacementCC: upTo(2000), RatePercent: decimal.NewFromInt(40)},
+		{ID: uuid.New(), MinDisplacementCC: 2000, RatePercent: decimal.NewFromInt(50)},
+		{ID: uuid.New(), FuelType: &hybrid, MinDisplacementCC: 1500, MaxDisplacementCC: upTo(2000), RatePercent: decimal.NewFromInt(28)},
+		{ID: uuid.New(), FuelType: &electric, MinDisplacementCC: 0, RatePercent: decimal.NewFromInt(3)},
+	}
+
+	tests := []struct {
+		name           string
+		fuelType       FuelType
+		displacementCC int64
+		wantRate       string
+		wantErr        error
+	}{
+		{"petrol in the first band", FuelTypePetrol, 1200, "35", nil},
+		{"upper bound is inclusive", FuelTypePetrol, 1500, "35", nil},
+		{"lower bound is exclusive", FuelTypePetrol, 1501, "40", nil},
+		{"open-ended band", FuelTypeDiesel, 6500, "50", nil},
+		{"fuel rule preferred over generic", FuelTypeHybrid, 1800, "28", nil},
+		{"generic rule when the fuel has none for the band", FuelTypeHybrid, 1200, "35", nil},
+		{"electric without displacement", FuelTypeElectric, 0, "3", nil},
+		{"no rule", FuelTypePetrol, -1, "", ErrNoSCTRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := FindSCTRule(rules, tt.fuelType, tt.displacementCC)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindSCTRule(%s, %d) error = %v, want %v", tt.fuelType, tt.displacementCC, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !rule.RatePercent.Equal(decimal.RequireFromString(tt.wantRate)) {
+				t.Errorf("FindSCTRule(%s, %d) rate = %s, want %s", tt.fuelType, tt.displacementCC, rule.RatePercent, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestSalesTax(t *testing.T) {
+	tests := []struct {
+		name  string
+		price string
+		rate  string
+		want  string
+	}{
+		{"hybrid 2.0", "500000000", "28", "204000000"},
+		{"electric", "1000000000", "3", "133000000"},
+		{"no special consumption tax", "300000000", "0", "30000000"},
+		{"rounded to whole dong", "123456789", "35", "59876543"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := SCTRule{RatePercent: decimal.RequireFromString(tt.rate)}
+			got := SalesTax(NewMoney(decimal.RequireFromString(tt.price), TaxCurrency), hanoi, rule)
+			if !got.Amount.Equal(decimal.RequireFromString(tt.want)) || got.Currency != TaxCurrency {
+				t.Errorf("SalesTax() = %s, want %s %s", got, tt.want, TaxCurrency)
+			}
+		})
+	}
+}
+
+func TestNewOnRoadPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		price     string
+		rate      string
+		fuelType  FuelType
+		wantLines map[string]string
+		wantTotal string
+	}{
+		{
+			name:     "hybrid 2.0 in Hanoi",
+			price:    "500000000",
+			rate:     "28",
+			fuelType: FuelTypeHybrid,
+			wantLines: map[string]string{
+				"price":                   "500000000",
+				"special consumption tax": "140000000",
+				"VAT":                     "64000000",
+				"registration fee":        "84480000",
+				"plate fee":               "20000000",
+			},
+			wantTotal: "810860700",
+		},
+		{
+			name:     "electric pays the EV registration rate",
+			price:    "1000000000",
+			rate:     "3",
+			fuelType: FuelTypeElectric,
+			wantLines: map[string]string{
+				"special consumption tax": "30000000",
+				"VAT":                     "103000000",
+				"registration fee":        "0",
+			},
+			wantTotal: "1155380700",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := NewMoney(decimal.RequireFromString(tt.price), TaxCurrency)
+			rule := SCTRule{RatePercent: decimal.RequireFromString(tt.rate)}
+
+			onRoad := NewOnRoadPrice(price, hanoi, rule, tt.fuelType)
+			if onRoad.Region != "HN" || onRoad.RegionName != hanoi.Name {
+				t.Errorf("NewOnRoadPrice() region = %s %s, want HN %s", onRoad.Region, onRoad.RegionName, hanoi.Name)
+			}
+
+			sum := decimal.Zero
+			lines := map[string]Money{}
+			for _, line := range onRoad.Lines {
+				lines[line.Name] = line.Amount
+				sum = sum.Add(line.Amount.Amount)
+			}
+			for name, want := range tt.wantLines {
+				if got, ok := lines[name]; !ok || !got.Amount.Equal(decimal.RequireFromString(want)) {
+					t.Errorf("line %q = %s, want %s", name, got, want)
+				}
+			}
+
+			if !onRoad.Total.Amount.Equal(decimal.RequireFromString(tt.wantTotal)) {
+				t.Errorf("Total = %s, want %s", onRoad.Total, tt.wantTotal)
+			}
+			if !sum.Equal(onRoad.Total.Amount) {
+				t.Errorf("lines add up to %s, want the total %s", sum, onRoad.Total)
+			}
+
+			// The taxes in the breakdown are what SalesTax charges on an order.
+			taxes := lines["special consumption tax"].Amount.Add(lines["VAT"].Amount)
+			if salesTax := SalesTax(price, hanoi, rule); !salesTax.Amount.Equal(taxes) {
+				t.Errorf("SalesTax() = %s, want the breakdown's %s", salesTax, taxes)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TestDriveStatus string
+
+const (
+	TestDriveStatusScheduled TestDriveStatus = "scheduled"
+	TestDriveStatusConfirmed TestDriveStatus = "confirmed"
+	TestDriveStatusCompleted TestDriveStatus = "completed"
+	TestDriveStatusCancelled TestDriveStatus = "cancelled"
+	TestDriveStatusNoShow    TestDriveStatus = "no_show"
+)
+
+// TestDriveSlotDuration is the length of one bookable test drive.
+const TestDriveSlotDuration = time.Hour
+
+var (
+	ErrTestDriveNotFound          = errors.New("test drive not found")
+	ErrSlotUnavailable            = errors.New("no unit is free for this slot")
+	ErrOutsideOpeningHours        = errors.New("slot is outside the dealership's opening hours")
+	ErrInvalidTestDriveTransition = errors.New("test drive status change not allowed")
+)
+
+// testDriveTransitions lists the statuses each status may move to.
+// Completed, cancelled and no-show are final.
+var testDriveTransitions = map[TestDriveStatus][]TestDriveStatus{
+	TestDriveStatusScheduled: {TestDriveStatusConfirmed, TestDriveStatusCancelled},
+	TestDriveStatusConfirmed: {TestDriveStatusCompleted, TestDriveStatusCancelled, TestDriveStatusNoShow},
+}
+
+// IsActive reports whether the booking still occupies its unit.
+func (s TestDriveStatus) IsActive() bool {
+	return s == TestDriveStatusScheduled || s == TestDriveStatusConfirmed
+}
+
+func CanTransitionTestDrive(from, to TestDriveStatus) error {
+	for _, next := range testDriveTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTestDriveTransition, from, to)
+}
+
+// TestDrive is a customer's booking of one inventory unit at a dealership.
+type TestDrive struct {
+	ID           uuid.UUID       `json:"id"`
+	CarID        uuid.UUID       `json:"car_id"`
+	UnitID       uuid.UUID       `json:"unit_id"`
+	DealershipID uuid.UUID       `json:"dealership_id"`
+	Customer     string          `json:"customer"`
+	Status       TestDriveStatus `json:"status"`
+	StartsAt     time.Time       `json:"starts_at"`
+	EndsAt       time.Time       `json:"ends_at"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type TestDriveRequest struct {
+	CarID        uuid.UUID `json:"car_id"`
+	DealershipID uuid.UUID `json:"dealership_id"`
+	StartsAt     time.Time `json:"starts_at"`
+}
+
+type TestDriveRescheduleRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+}
+
+type TestDriveStatusRequest struct {
+	Status TestDriveStatus `json:"status"`
+}
+
+// TestDriveSlot is a bookable window and how many units are free in it.
+type TestDriveSlot struct {
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+	AvailableUnits int       `json:"available_units"`
+}
+
+func ValidateTestDriveRequest(testDriveRequest TestDriveRequest) error {
+	if testDriveRequest.CarID == uuid.Nil {
+		return errors.New("Car ID is Required")
+	}
+	if testDriveRequest.DealershipID == uuid.Nil {
+		return errors.New("Dealership ID is Required")
+	}
+	if testDriveRequest.StartsAt.IsZero() {
+		return errors.New("Start time is Required")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+)
+
+// Quantity is a measured value with its unit as entered by the client, for
+// example {"value": 150, "unit": "hp"}. A bare number is accepted too and is
+// taken to be in the SI unit.
+type Quantity struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+const (
+	kwPerHP       = 0.745699872
+	kwPerPS       = 0.73549875
+	nmPerLbFt     = 1.3558179483
+	nmPerKgfMetre = 9.80665
+)
+
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	var value float64
+	if err := json.Unmarshal(data, &value); err == nil {
+		*q = Quantity{Value: value}
+		return nil
+	}
+
+	type quantity Quantity
+	var raw quantity
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*q = Quantity(raw)
+	return nil
+}
+
+// KW returns the power in kilowatts, rounded to two decimals.
+func (q Quantity) KW() (float64, error) {
+	switch normalizeUnit(q.Unit) {
+	case "", "kw":
+		return roundTo2(q.Value), nil
+	case "hp", "bhp":
+		return roundTo2(q.Value * kwPerHP), nil
+	case "ps", "cv":
+		return roundTo2(q.Value * kwPerPS), nil
+	}
+	return 0, errors.New("Power unit in: kW, hp, PS")
+}
+
+// Nm returns the torque in newton metres, rounded to two decimals.
+func (q Quantity) Nm() (float64, error) {
+	switch normalizeUnit(q.Unit) {
+	case "", "nm":
+		return roundTo2(q.Value), nil
+	case "lbft", "ftlb", "lbfft":
+		return roundTo2(q.Value * nmPerLbFt), nil
+	case "kgm", "kgfm":
+		return roundTo2(q.Value * nmPerKgfMetre), nil
+	}
+	return 0, errors.New("Torque unit in: Nm, lb-ft, kgm")
+}
+
+// normalizeUnit lowercases a unit and strips separators so "lb-ft",
+// "lb·ft" and "lb ft" compare equal.
+func normalizeUnit(unit string) string {
+	unit = strings.ToLower(unit)
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '·', '_', '/':
+			return -1
+		}
+		return r
+	}, unit)
+}
+
+func roundTo2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQuantityKW(t *testing.T) {
+	tests := []struct {
+		name    string
+		q       Quantity
+		want    float64
+		wantErr bool
+	}{
+		{"bare number is kW", Quantity{Value: 100}, 100, false},
+		{"kW", Quantity{Value: 110.456, Unit: "kW"}, 110.46, false},
+		{"hp", Quantity{Value: 150, Unit: "hp"}, 111.85, false},
+		{"bhp upper case", Quantity{Value: 200, Unit: "BHP"}, 149.14, false},
+		{"PS", Quantity{Value: 100, Unit: "PS"}, 73.55, false},
+		{"cv", Quantity{Value: 136, Unit: "cv"}, 100.03, false},
+		{"unknown unit", Quantity{Value: 100, Unit: "mph"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.KW()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KW() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("KW() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantityNm(t *testing.T) {
+	tests := []struct {
+		name    string
+		q       Quantity
+		want    float64
+		wantErr bool
+	}{
+		{"bare number is Nm", Quantity{Value: 300}, 300, false},
+		{"Nm", Quantity{Value: 250, Unit: "N·m"}, 250, false},
+		{"lb-ft", Quantity{Value: 100, Unit: "lb-ft"}, 135.58, false},
+		{"lb ft with space", Quantity{Value: 100, Unit: "lb ft"}, 135.58, false},
+		{"ft-lb", Quantity{Value: 100, Unit: "ft-lb"}, 135.58, false},
+		{"kgm", Quantity{Value: 30, Unit: "kgm"}, 294.2, false},
+		{"kgf.m", Quantity{Value: 10, Unit: "kgf.m"}, 98.07, false},
+		{"unknown unit", Quantity{Value: 100, Unit: "psi"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.Nm()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Nm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Nm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantityUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Quantity
+		wantErr bool
+	}{
+		{"bare number", `150`, Quantity{Value: 150}, false},
+		{"value and unit", `{"value": 150, "unit": "hp"}`, Quantity{Value: 150, Unit: "hp"}, false},
+		{"string", `"150 hp"`, Quantity{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Quantity
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type VehicleCondition string
+
+const (
+	ConditionExcellent VehicleCondition = "excellent"
+	ConditionGood      VehicleCondition = "good"
+	ConditionFair      VehicleCondition = "fair"
+	ConditionPoor      VehicleCondition = "poor"
+)
+
+var vehicleConditions = []VehicleCondition{ConditionExcellent, ConditionGood, ConditionFair, ConditionPoor}
+
+const (
+	// MinCalibrationSamples is how many past sales are needed before they
+	// are trusted to adjust valuations.
+	MinCalibrationSamples = 3
+
+	// CalibrationWindow is how far back past sales are considered.
+	CalibrationWindow = 365 * 24 * time.Hour
+)
+
+var (
+	ErrDepreciationCurveNotFound = errors.New("depreciation curve not found")
+	ErrInvalidValuation          = errors.New("invalid valuation request")
+	ErrNoValuationData           = errors.New("no listings to value this car against")
+)
+
+var (
+	// Calibration can move a valuation by at most half either way, so a
+	// few odd sales cannot swamp the curve.
+	minCalibration = decimal.RequireFromString("0.5")
+	maxCalibration = decimal.RequireFromString("1.5")
+
+	one = decimal.NewFromInt(1)
+)
+
+// CurvePoint is the share of the new price a car keeps at AgeYears.
+type CurvePoint struct {
+	AgeYears  int             `json:"age_years"`
+	Retention decimal.Decimal `json:"retention"`
+}
+
+// CurvePoints is stored as JSONB, ordered by age.
+type CurvePoints []CurvePoint
+
+func (p CurvePoints) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (p *CurvePoints) Scan(src interface{}) error {
+	return scanJSON(src, p)
+}
+
+// ConditionFactors scales the value by the car's condition. Stored as JSONB.
+type ConditionFactors map[VehicleCondition]decimal.Decimal
+
+func (f ConditionFactors) Value() (driver.Value, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (f *ConditionFactors) Scan(src interface{}) error {
+	return scanJSON(src, f)
+}
+
+func scanJSON(src interface{}, v interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, v)
+	}
+}
+
+// DepreciationCurve describes how a brand's cars lose value. A curve without
+// a brand is the default for brands that have none of their own.
+//
+// Retention is interpolated linearly between points and stays at the last
+// point's value after it. Each 1,000 km driven above (or below) the expected
+// AnnualMileageKm per year of age lowers (or raises) the value by
+// MileageRatePer1000Km, capped at MaxMileageAdjustment either way.
+type DepreciationCurve struct {
+	ID                   uuid.UUID        `json:"id"`
+	Brand                *string          `json:"brand,omitempty"`
+	Points               CurvePoints      `json:"points"`
+	AnnualMileageKm      int              `json:"annual_mileage_km"`
+	MileageRatePer1000Km decimal.Decimal  `json:"mileage_rate_per_1000_km"`
+	MaxMileageAdjustment decimal.Decimal  `json:"max_mileage_adjustment"`
+	ConditionFactors     ConditionFactors `json:"condition_factors"`
+	TradeInMarginPercent decimal.Decimal  `json:"trade_in_margin_percent"`
+	CreatedAt            time.Time        `json:"created_at"`
+	UpdatedAt            time.Time        `json:"updated_at"`
+}
+
+type DepreciationCurveRequest struct {
+	Brand                *string          `json:"brand,omitempty"`
+	Points               CurvePoints      `json:"points"`
+	AnnualMileageKm      int              `json:"annual_mileage_km"`
+	MileageRatePer1000Km decimal.Decimal  `json:"mileage_rate_per_1000_km"`
+	MaxMileageAdjustment decimal.Decimal  `json:"max_mileage_adjustment"`
+	ConditionFactors     ConditionFactors `json:"condition_factors"`
+	TradeInMarginPercent decimal.Decimal  `json:"trade_in_margin_percent"`
+}
+
+func ValidateDepreciationCurveRequest(curveRequest DepreciationCurveRequest) error {
+	if curveRequest.Brand != nil && strings.TrimSpace(*curveRequest.Brand) == "" {
+		return errors.New("Brand must not be empty; omit it for the default curve")
+	}
+	if err := validateCurvePoints(curveRequest.Points); err != nil {
+		return err
+	}
+	if curveRequest.AnnualMileageKm <= 0 {
+		return errors.New("Annual mileage must be positive")
+	}
+	if curveRequest.MileageRatePer1000Km.IsNegative() {
+		return errors.New("Mileage rate must not be negative")
+	}
+	if curveRequest.MaxMileageAdjustment.IsNegative() || curveRequest.MaxMileageAdjustment.GreaterThanOrEqual(one) {
+		return errors.New("Max mileage adjustment must be at least 0 and below 1")
+	}
+	for _, condition := range vehicleConditions {
+		factor, ok := curveRequest.ConditionFactors[condition]
+		if !ok || !factor.IsPositive() {
+			return fmt.Errorf("Condition factor for %s must be positive", condition)
+		}
+	}
+	if curveRequest.TradeInMarginPercent.IsNegative() || curveRequest.TradeInMarginPercent.GreaterThanOrEqual(hundred) {
+		return errors.New("Trade-in margin must be at least 0% and below 100%")
+	}
+	return nil
+}
+
+// validateCurvePoints requires points starting at age 0 with strictly
+// increasing ages and retention that never goes up.
+func validateCurvePoints(points CurvePoints) error {
+	if len(points) == 0 || points[0].AgeYears != 0 {
+		return errors.New("Curve must start at age 0")
+	}
+	for i, point := range points {
+		if !point.Retention.IsPositive() || point.Retention.GreaterThan(one) {
+			return fmt.Errorf("Retention at age %d must be above 0 and at most 1", point.AgeYears)
+		}
+		if i == 0 {
+			continue
+		}
+		if point.AgeYears <= points[i-1].AgeYears {
+			return errors.New("Curve ages must be increasing")
+		}
+		if point.Retention.GreaterThan(points[i-1].Retention) {
+			return errors.New("Retention must not increase with age")
+		}
+	}
+	return nil
+}
+
+// Retention is the share of the new price kept at ageYears.
+func (c DepreciationCurve) Retention(ageYears int) decimal.Decimal {
+	points := c.Points
+	if len(points) == 0 {
+		return one
+	}
+
+	for i := 1; i < len(points); i++ {
+		if ageYears > points[i].AgeYears {
+			continue
+		}
+		prev, next := points[i-1], points[i]
+		span := decimal.NewFromInt(int64(next.AgeYears - prev.AgeYears))
+		into := decimal.NewFromInt(int64(ageYears - prev.AgeYears))
+		return prev.Retention.Add(next.Retention.Sub(prev.Retention).Mul(into).Div(span))
+	}
+
+	return points[len(points)-1].Retention
+}
+
+// MileageFactor adjusts for distance driven against what is expected for
+// the car's age. A car in its first year is compared against one year of
+// driving.
+func (c DepreciationCurve) MileageFactor(ageYears, mileageKm int) decimal.Decimal {
+	if ageYears < 1 {
+		ageYears = 1
+	}
+
+	expected := c.AnnualMileageKm * ageYears
+	excessThousands := decimal.NewFromInt(int64(mileageKm - expected)).Div(decimal.NewFromInt(1000))
+	adjustment := excessThousands.Mul(c.MileageRatePer1000Km).Neg()
+
+	if adjustment.GreaterThan(c.MaxMileageAdjustment) {
+		adjustment = c.MaxMileageAdjustment
+	}
+	if adjustment.LessThan(c.MaxMileageAdjustment.Neg()) {
+		adjustment = c.MaxMileageAdjustment.Neg()
+	}
+
+	return one.Add(adjustment)
+}
+
+func (c DepreciationCurve) ConditionFactor(condition VehicleCondition) decimal.Decimal {
+	if factor, ok := c.ConditionFactors[condition]; ok {
+		return factor
+	}
+	return one
+}
+
+// TradeInRequest describes the customer's car. Currency defaults to
+// DefaultCurrency.
+type TradeInRequest struct {
+	Brand     string           `json:"brand"`
+	Model     string           `json:"model,omitempty"`
+	Year      int              `json:"year"`
+	MileageKm int              `json:"mileage_km"`
+	Condition VehicleCondition `json:"condition"`
+	Currency  string           `json:"currency,omitempty"`
+}
+
+func ValidateTradeInRequest(tradeInRequest TradeInRequest, now time.Time) error {
+	if strings.TrimSpace(tradeInRequest.Brand) == "" {
+		return fmt.Errorf("%w: brand is Required", ErrInvalidValuation)
+	}
+	if tradeInRequest.Year < 1886 || tradeInRequest.Year > now.Year()+1 {
+		return fmt.Errorf("%w: year must be between 1886 and %d", ErrInvalidValuation, now.Year()+1)
+	}
+	if tradeInRequest.MileageKm < 0 {
+		return fmt.Errorf("%w: mileage must not be negative", ErrInvalidValuation)
+	}
+	for _, condition := range vehicleConditions {
+		if tradeInRequest.Condition == condition {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: condition must be excellent, good, fair or poor", ErrInvalidValuation)
+}
+
+// CarAge is the age in whole years of a car of modelYear at the given time;
+// next year's models count as new.
+func CarAge(modelYear int, at time.Time) int {
+	if age := at.Year() - modelYear; age > 0 {
+		return age
+	}
+	return 0
+}
+
+// ValuationSample is a catalog listing or a past sale used to value cars
+// of the same brand.
+type ValuationSample struct {
+	Price     Money     `json:"price"`
+	Year      int       `json:"year"`
+	MileageKm int       `json:"mileage_km"`
+	At        time.Time `json:"at"`
+}
+
+// Calibration is how far real sale prices sat from what the curve
+// predicted. Factor is 1 until there are MinCalibrationSamples sales.
+type Calibration struct {
+	Factor     decimal.Decimal `json:"factor"`
+	SampleSize int             `json:"sample_size"`
+	Applied    bool            `json:"applied"`
+}
+
+// NewCalibration takes the median of actual/predicted price ratios, clamped
+// so a handful of outliers cannot swing valuations too far.
+func NewCalibration(ratios []decimal.Decimal) Calibration {
+	calibration := Calibration{Factor: one, SampleSize: len(ratios)}
+	if len(ratios) < MinCalibrationSamples {
+		return calibration
+	}
+
+	sorted := append([]decimal.Decimal(nil), ratios...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+
+	mid := len(sorted) / 2
+	median := sorted[mid]
+	if len(sorted)%2 == 0 {
+		median = sorted[mid-1].Add(sorted[mid]).Div(decimal.NewFromInt(2))
+	}
+
+	calibration.Factor = decimal.Min(decimal.Max(median, minCalibration), maxCalibration).Round(4)
+	calibration.Applied = true
+	return calibration
+}
+
+// ValuationStep is one line of the breakdown: Factor applied to the
+// previous value gives Value.
+type ValuationStep struct {
+	Name   string          `json:"name"`
+	Factor decimal.Decimal `json:"factor"`
+	Value  Money           `json:"value"`
+	Detail string          `json:"detail"`
+}
+
+type TradeInValuation struct {
+	Brand          string           `json:"brand"`
+	Model          string           `json:"model,omitempty"`
+	Year           int              `json:"year"`
+	AgeYears       int              `json:"age_years"`
+	MileageKm      int              `json:"mileage_km"`
+	Condition      VehicleCondition `json:"condition"`
+	CurveID        uuid.UUID        `json:"curve_id"`
+	BasePrice      Money            `json:"base_price"`
+	BaseSampleSize int              `json:"base_sample_size"`
+	Calibration    Calibration      `json:"calibration"`
+	Steps          []ValuationStep  `json:"steps"`
+	RetailValue    Money            `json:"retail_value"`
+	TradeInValue   Money            `json:"trade_in_value"`
+}
+
+// Value walks a new-car base price down the curve for the car described by
+// tradeInRequest and records every step.
+func (c DepreciationCurve) Value(tradeInRequest TradeInRequest, base Money, baseSamples int, calibration Calibration, now time.Time) TradeInValuation {
+	age := CarAge(tradeInRequest.Year, now)
+
+	valuation := TradeInValuation{
+		Brand:          tradeInRequest.Brand,
+		Model:          tradeInRequest.Model,
+		Year:           tradeInRequest.Year,
+		AgeYears:       age,
+		MileageKm:      tradeInRequest.MileageKm,
+		Condition:      tradeInRequest.Condition,
+		CurveID:        c.ID,
+		BasePrice:      base.Round(),
+		BaseSampleSize: baseSamples,
+		Calibration:    calibration,
+	}
+
+	value := base
+	step := func(name string, factor decimal.Decimal, detail string) {
+		value = value.Mul(factor)
+		valuation.Steps = append(valuation.Steps, ValuationStep{
+			Name:   name,
+			Factor: factor.Round(4),
+			Value:  value.Round(),
+			Detail: detail,
+		})
+	}
+
+	step("age", c.Retention(age), fmt.Sprintf("%d years old", age))
+	step("mileage", c.MileageFactor(age, tradeInRequest.MileageKm),
+		fmt.Sprintf("%d km against %d km expected", tradeInRequest.MileageKm, c.AnnualMileageKm*max(age, 1)))
+	step("condition", c.ConditionFactor(tradeInRequest.Condition), string(tradeInRequest.Condition))
+	marketDetail := fmt.Sprintf("median of %d recent sales", calibration.SampleSize)
+	if !calibration.Applied {
+		marketDetail = fmt.Sprintf("not calibrated, %d of %d recent sales needed", calibration.SampleSize, MinCalibrationSamples)
+	}
+	step("market", calibration.Factor, marketDetail)
+
+	valuation.RetailValue = value.Round()
+
+	step("dealer margin", one.Sub(c.TradeInMarginPercent.Div(hundred)), c.TradeInMarginPercent.String()+"%")
+
+	valuation.TradeInValue = value.Round()
+	return valuation
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vin_wmi.json maps World Manufacturer Identifiers (the first three VIN
+// characters) to the manufacturer, its brands and known assembly plants
+// keyed by the 11th VIN character. Unknown WMIs still decode the year.
+//
+//go:embed vin_wmi.json
+var wmiDataset []byte
+
+type wmiEntry struct {
+	Manufacturer string            `json:"manufacturer"`
+	Country      string            `json:"country"`
+	Brands       []string          `json:"brands"`
+	Plants       map[string]string `json:"plants"`
+}
+
+var wmiTable = func() map[string]wmiEntry {
+	table := make(map[string]wmiEntry)
+	if err := json.Unmarshal(wmiDataset, &table); err != nil {
+		panic("models: invalid vin_wmi.json: " + err.Error())
+	}
+	return table
+}()
+
+var (
+	ErrInvalidVIN       = errors.New("invalid VIN")
+	ErrVINCheckDigit    = errors.New("VIN check digit does not match")
+	ErrVINBrandMismatch = errors.New("VIN manufacturer does not match the car brand")
+	ErrVINYearMismatch  = errors.New("VIN model year does not match the car year")
+)
+
+// VINInfo is what can be read from a VIN without calling an external
+// service. Brand and ModelYear are meant to pre-fill registration forms.
+type VINInfo struct {
+	VIN          string   `json:"vin"`
+	WMI          string   `json:"wmi"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Country      string   `json:"country,omitempty"`
+	Brand        string   `json:"brand,omitempty"`
+	Brands       []string `json:"brands,omitempty"`
+	ModelYear    int      `json:"model_year"`
+	PlantCode    string   `json:"plant_code"`
+	Plant        string   `json:"plant,omitempty"`
+
+	// yearCandidates holds both years of the 30-year cycle the year code
+	// could stand for.
+	yearCandidates []int
+}
+
+// DecodeVIN validates the format and check digit of a VIN and decodes the
+// manufacturer, model year and plant.
+func DecodeVIN(vin string) (VINInfo, error) {
+	vin = NormalizeVIN(vin)
+
+	if err := validateVINFormat(vin); err != nil {
+		return VINInfo{}, fmt.Errorf("%w: %v", ErrInvalidVIN, err)
+	}
+
+	if err := validateVINCheckDigit(vin); err != nil {
+		return VINInfo{}, err
+	}
+
+	candidates, err := vinYearCandidates(vin[9])
+	if err != nil {
+		return VINInfo{}, fmt.Errorf("%w: %v", ErrInvalidVIN, err)
+	}
+
+	info := VINInfo{
+		VIN:            vin,
+		WMI:            vin[:3],
+		PlantCode:      vin[10:11],
+		yearCandidates: candidates,
+	}
+
+	// The later year of the cycle is the right one unless it is still in
+	// the future (model years may run one year ahead of the calendar).
+	info.ModelYear = candidates[0]
+	if candidates[1] <= time.Now().Year()+1 {
+		info.ModelYear = candidates[1]
+	}
+
+	if entry, ok := wmiTable[info.WMI]; ok {
+		info.Manufacturer = entry.Manufacturer
+		info.Country = entry.Country
+		info.Brands = entry.Brands
+		if len(entry.Brands) > 0 {
+			info.Brand = entry.Brands[0]
+		}
+		info.Plant = entry.Plants[info.PlantCode]
+	}
+
+	return info, nil
+}
+
+// IsVINError reports whether err came from decoding or matching a VIN, so
+// handlers can answer 400 rather than 500.
+func IsVINError(err error) bool {
+	return errors.Is(err, ErrInvalidVIN) || errors.Is(err, ErrVINCheckDigit) ||
+		errors.Is(err, ErrVINBrandMismatch) || errors.Is(err, ErrVINYearMismatch)
+}
+
+// MatchesCar checks the decoded VIN against the brand and year of the car
+// listing the unit belongs to. The brand is only checked when the WMI is in
+// the dataset.
+func (info VINInfo) MatchesCar(brand, year string) error {
+	if len(info.Brands) > 0 {
+		matched := false
+		for _, b := range info.Brands {
+			if strings.EqualFold(NormalizeBrandName(b), NormalizeBrandName(brand)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%w: VIN is a %s, car is a %s", ErrVINBrandMismatch, info.Brand, brand)
+		}
+	}
+
+	carYear, err := strconv.Atoi(year)
+	if err != nil {
+		return fmt.Errorf("%w: car year %q is not a number", ErrVINYearMismatch, year)
+	}
+	for _, candidate := range info.yearCandidates {
+		if candidate == carYear {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: VIN is model year %d, car is %d", ErrVINYearMismatch, info.ModelYear, carYear)
+}
+
+var vinTransliteration = map[byte]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+var vinWeights = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// validateVINCheckDigit applies the ISO 3779 / FMVSS 115 check digit in
+// position 9. It expects a VIN that already passed validateVINFormat.
+func validateVINCheckDigit(vin string) error {
+	sum := 0
+	for i := 0; i < len(vin); i++ {
+		c := vin[i]
+		value, ok := vinTransliteration[c]
+		if !ok {
+			value = int(c - '0')
+		}
+		sum += value * vinWeights[i]
+	}
+
+	expected := byte('0' + sum%11)
+	if sum%11 == 10 {
+		expected = 'X'
+	}
+
+	if vin[8] != expected {
+		return fmt.Errorf("%w: expected %c, got %c", ErrVINCheckDigit, expected, vin[8])
+	}
+	return nil
+}
+
+const vinYearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// vinYearCandidates maps the 10th VIN character to the two model years it
+// can mean: one in 1980-2009 and one 30 years later.
+func vinYearCandidates(code byte) ([]int, error) {
+	index := strings.IndexByte(vinYearCodes, code)
+	if index < 0 {
+		return nil, fmt.Errorf("Invalid VIN model year code %q", code)
+	}
+	return []int{1980 + index, 2010 + index}, nil
+}
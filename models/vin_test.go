@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateVINCheckDigit(t *testing.T) {
+	tests := []struct {
+		name    string
+		vin     string
+		wantErr error
+	}{
+		{"Honda Accord", "1HGCM82633A004352", nil},
+		{"check digit X", "1M8GDM9AXKP042788", nil},
+		{"all ones", "11111111111111111", nil},
+		{"wrong digit", "1HGCM82643A004352", ErrVINCheckDigit},
+		{"X expected", "1M8GDM9A1KP042788", ErrVINCheckDigit},
+		{"transposed serial", "1HGCM82633A004325", ErrVINCheckDigit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVINCheckDigit(tt.vin)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateVINCheckDigit(%s) error = %v, want %v", tt.vin, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeVIN(t *testing.T) {
+	tests := []struct {
+		name      string
+		vin       string
+		wantBrand string
+		wantYear  int
+		wantPlant string
+		wantErr   error
+	}{
+		{"known WMI and plant", "1HGCM82633A004352", "Honda", 2003, "Marysville, Ohio", nil},
+		{"normalised", "1hgcm8263-3a00 4352", "Honda", 2003, "Marysville, Ohio", nil},
+		{"unknown WMI still decodes year", "11111111111111111", "", 2001, "", nil},
+		{"too short", "1HGCM82633A00435", "", 0, "", ErrInvalidVIN},
+		{"letter O", "1HGCM82633AO04352", "", 0, "", ErrInvalidVIN},
+		{"bad check digit", "1HGCM82643A004352", "", 0, "", ErrVINCheckDigit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := DecodeVIN(tt.vin)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeVIN(%s) error = %v, want %v", tt.vin, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if !IsVINError(err) {
+					t.Errorf("IsVINError(%v) = false, want true", err)
+				}
+				return
+			}
+			if info.Brand != tt.wantBrand || info.ModelYear != tt.wantYear || info.Plant != tt.wantPlant {
+				t.Errorf("DecodeVIN(%s) = %s %d %q, want %s %d %q", tt.vin, info.Brand, info.ModelYear, info.Plant, tt.wantBrand, tt.wantYear, tt.wantPlant)
+			}
+		})
+	}
+}
+
+func TestVINInfoMatchesCar(t *testing.T) {
+	honda, err := DecodeVIN("1HGCM82633A004352")
+	if err != nil {
+		t.Fatalf("DecodeVIN() error = %v", err)
+	}
+	unknown, err := DecodeVIN("11111111111111111")
+	if err != nil {
+		t.Fatalf("DecodeVIN() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		info    VINInfo
+		brand   string
+		year    string
+		wantErr error
+	}{
+		{"matching car", honda, "Honda", "2003", nil},
+		{"brand is case insensitive", honda, "honda", "2003", nil},
+		{"other year of the cycle", honda, "Honda", "2033", nil},
+		{"wrong brand", honda, "Toyota", "2003", ErrVINBrandMismatch},
+		{"wrong year", honda, "Honda", "2004", ErrVINYearMismatch},
+		{"year not a number", honda, "Honda", "new", ErrVINYearMismatch},
+		{"unknown WMI skips brand", unknown, "Toyota", "2001", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.info.MatchesCar(tt.brand, tt.year)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("MatchesCar(%s, %s) error = %v, want %v", tt.brand, tt.year, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookCarCreated    WebhookEventType = "car.created"
+	WebhookCarUpdated    WebhookEventType = "car.updated"
+	WebhookCarDeleted    WebhookEventType = "car.deleted"
+	WebhookEngineCreated WebhookEventType = "engine.created"
+	WebhookEngineUpdated WebhookEventType = "engine.updated"
+	WebhookEngineDeleted WebhookEventType = "engine.deleted"
+)
+
+// WebhookEntityTypes are the audited entities that raise webhook events.
+var WebhookEntityTypes = []string{"car", "engine"}
+
+var webhookEventTypes = []WebhookEventType{
+	WebhookCarCreated,
+	WebhookCarUpdated,
+	WebhookCarDeleted,
+	WebhookEngineCreated,
+	WebhookEngineUpdated,
+	WebhookEngineDeleted,
+}
+
+func (t WebhookEventType) IsValid() bool {
+	for _, eventType := range webhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+func (s WebhookDeliveryStatus) IsValid() bool {
+	return s == WebhookDeliveryPending || s == WebhookDeliveryDelivered || s == WebhookDeliveryFailed
+}
+
+const (
+	// WebhookSignatureHeader carries the HMAC signature of the payload, in
+	// the same "t=<unix>,v1=<hex>" form as payment callbacks.
+	WebhookSignatureHeader = "X-CarZone-Signature"
+	WebhookEventHeader     = "X-CarZone-Event"
+	WebhookDeliveryHeader  = "X-CarZone-Delivery"
+
+	// MaxWebhookAttempts is how often a delivery is tried before it is
+	// marked failed; retries back off from 30 seconds to 32 minutes.
+	MaxWebhookAttempts = 8
+
+	webhookRetryBase    = 30 * time.Second
+	minWebhookSecretLen = 16
+)
+
+var (
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidWebhookStatus        = errors.New("status must be pending, delivered or failed")
+	ErrWebhookTargetNotAllowed     = errors.New("webhook URL must point to a public address")
+)
+
+// nonPublicNetworks are reserved ranges that net.IP has no predicate for:
+// "this network" and carrier-grade NAT.
+var nonPublicNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// IsPublicWebhookIP reports whether webhooks may be sent to the address.
+// Loopback, private, link-local, unspecified and multicast addresses are
+// refused, so a subscription cannot reach services on our own network.
+func IsPublicWebhookIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// WebhookSubscription is a partner endpoint and the events it receives.
+// Secret signs the payloads; it is only shown when the subscription is
+// created.
+type WebhookSubscription struct {
+	ID          uuid.UUID          `json:"id"`
+	URL         string             `json:"url"`
+	Secret      string             `json:"secret,omitempty"`
+	EventTypes  []WebhookEventType `json:"event_types"`
+	Description string             `json:"description"`
+	Active      bool               `json:"active"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// Subscribes reports whether the subscription wants events of the type.
+func (s WebhookSubscription) Subscribes(eventType WebhookEventType) bool {
+	for _, subscribed := range s.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscriptionRequest creates or updates a subscription. A secret is
+// generated when none is given on create; on update a missing secret keeps
+// the current one.
+type WebhookSubscriptionRequest struct {
+	URL         string             `json:"url"`
+	Secret      *string            `json:"secret,omitempty"`
+	EventTypes  []WebhookEventType `json:"event_types"`
+	Description string             `json:"description"`
+	Active      bool               `json:"active"`
+}
+
+func ValidateWebhookSubscriptionRequest(subscriptionRequest WebhookSubscriptionRequest) error {
+	endpoint, err := url.Parse(subscriptionRequest.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return errors.New("URL must be an absolute http or https URL")
+	}
+
+	// Hostnames are resolved and checked by the service; literal addresses
+	// and localhost can be refused here.
+	host := strings.TrimSuffix(strings.ToLower(endpoint.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrWebhookTargetNotAllowed, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicWebhookIP(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookTargetNotAllowed, host)
+	}
+
+	if subscriptionRequest.Secret != nil && len(*subscriptionRequest.Secret) < minWebhookSecretLen {
+		return fmt.Errorf("Secret must be at least %d characters", minWebhookSecretLen)
+	}
+
+	if len(subscriptionRequest.EventTypes) == 0 {
+		return errors.New("At least one event type is Required")
+	}
+	for _, eventType := range subscriptionRequest.EventTypes {
+		if !eventType.IsValid() {
+			return fmt.Errorf("Unknown event type %s", eventType)
+		}
+	}
+
+	return nil
+}
+
+// WebhookEvent is the JSON body posted to subscribers. ID is stable across
+// retries and redeliveries, so receivers can discard repeats.
+type WebhookEvent struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      WebhookEventData `json:"data"`
+}
+
+// WebhookEventData is the entity after the change, or as it was before a
+// delete. Changes lists the changed fields of an update.
+type WebhookEventData struct {
+	ID      string                 `json:"id"`
+	Object  json.RawMessage        `json:"object"`
+	Changes map[string]AuditChange `json:"changes,omitempty"`
+}
+
+// WebhookEventFromAudit turns an audit entry into the event partners see.
+// It reports false for entries that raise no event.
+func WebhookEventFromAudit(entry AuditEntry) (WebhookEvent, bool) {
+	var suffix string
+	object := entry.After
+
+	switch entry.Action {
+	case AuditActionCreate:
+		suffix = "created"
+	case AuditActionUpdate:
+		suffix = "updated"
+	case AuditActionDelete:
+		suffix = "deleted"
+		object = entry.Before
+	default:
+		return WebhookEvent{}, false
+	}
+
+	eventType := WebhookEventType(entry.EntityType + "." + suffix)
+	if !eventType.IsValid() {
+		return WebhookEvent{}, false
+	}
+
+	event := WebhookEvent{
+		ID:        entry.ID,
+		Type:      eventType,
+		CreatedAt: entry.CreatedAt,
+		Data: WebhookEventData{
+			ID:     entry.EntityID,
+			Object: object,
+		},
+	}
+
+	if entry.Action == AuditActionUpdate {
+		event.Data.Changes = entry.Diff
+	}
+
+	return event, true
+}
+
+// WebhookDelivery is one event queued for one subscription.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id"`
+	SubscriptionID uuid.UUID             `json:"subscription_id"`
+	EventID        uuid.UUID             `json:"event_id"`
+	EventType      WebhookEventType      `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty"`
+	LastError      *string               `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+
+	// Log lists every attempt, oldest first, when a single delivery is
+	// fetched.
+	Log []WebhookAttempt `json:"log,omitempty"`
+}
+
+// WebhookAttempt records one POST of a delivery. StatusCode is unset when
+// no response arrived.
+type WebhookAttempt struct {
+	ID           uuid.UUID `json:"id"`
+	DeliveryID   uuid.UUID `json:"delivery_id"`
+	AttemptedAt  time.Time `json:"attempted_at"`
+	StatusCode   *int      `json:"status_code,omitempty"`
+	Error        *string   `json:"error,omitempty"`
+	ResponseBody string    `json:"response_body"`
+	DurationMs   int64     `json:"duration_ms"`
+}
+
+// Succeeded reports whether the receiver acknowledged the delivery with a
+// 2xx response.
+func (a WebhookAttempt) Succeeded() bool {
+	return a.StatusCode != nil && *a.StatusCode >= 200 && *a.StatusCode < 300
+}
+
+// Describe summarises a failed attempt for the delivery's last error.
+func (a WebhookAttempt) Describe() string {
+	if a.Error != nil {
+		return *a.Error
+	}
+	if a.StatusCode != nil {
+		return fmt.Sprintf("receiver answered %d: %s", *a.StatusCode, strings.TrimSpace(a.ResponseBody))
+	}
+	return "no response"
+}
+
+// NextWebhookAttempt is when a delivery that has failed attempts times
+// should be retried, doubling from 30 seconds. It reports false once
+// MaxWebhookAttempts is reached.
+func NextWebhookAttempt(attempts int, now time.Time) (time.Time, bool) {
+	if attempts >= MaxWebhookAttempts {
+		return time.Time{}, false
+	}
+	if attempts < 1 {
+		attempts = 1
+	}
+	return now.Add(webhookRetryBase << (attempts - 1)), true
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestIsPublicWebhookIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := IsPublicWebhookIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("IsPublicWebhookIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+
+	if IsPublicWebhookIP(nil) {
+		t.Error("IsPublicWebhookIP(nil) = true, want false")
+	}
+}
+
+func TestValidateWebhookSubscriptionRequest(t *testing.T) {
+	secret := "0123456789abcdef"
+	shortSecret := "short"
+	events := []WebhookEventType{WebhookCarCreated}
+
+	tests := []struct {
+		name          string
+		request       WebhookSubscriptionRequest
+		wantErr       bool
+		wantForbidden bool
+	}{
+		{"valid", WebhookSubscriptionRequest{URL: "https://partner.example.com/hooks", Secret: &secret, EventTypes: events}, false, false},
+		{"public address", WebhookSubscriptionRequest{URL: "http://93.184.216.34:8080/hooks", EventTypes: events}, false, false},
+		{"not http", WebhookSubscriptionRequest{URL: "ftp://partner.example.com", EventTypes: events}, true, false},
+		{"relative", WebhookSubscriptionRequest{URL: "/hooks", EventTypes: events}, true, false},
+		{"short secret", WebhookSubscriptionRequest{URL: "https://partner.example.com", Secret: &shortSecret, EventTypes: events}, true, false},
+		{"no events", WebhookSubscriptionRequest{URL: "https://partner.example.com"}, true, false},
+		{"unknown event", WebhookSubscriptionRequest{URL: "https://partner.example.com", EventTypes: []WebhookEventType{"car.sold"}}, true, false},
+		{"localhost", WebhookSubscriptionRequest{URL: "http://localhost:8080/admin", EventTypes: events}, true, true},
+		{"localhost subdomain", WebhookSubscriptionRequest{URL: "http://api.localhost./", EventTypes: events}, true, true},
+		{"loopback", WebhookSubscriptionRequest{URL: "http://127.0.0.1/", EventTypes: events}, true, true},
+		{"private", WebhookSubscriptionRequest{URL: "http://10.0.0.5/", EventTypes: events}, true, true},
+		{"metadata endpoint", WebhookSubscriptionRequest{URL: "http://169.254.169.254/latest/meta-data", EventTypes: events}, true, true},
+		{"IPv6 loopback", WebhookSubscriptionRequest{URL: "http://[::1]:8080/", EventTypes: events}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWebhookSubscriptionRequest(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateWebhookSubscriptionRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrWebhookTargetNotAllowed) != tt.wantForbidden {
+				t.Errorf("ValidateWebhookSubscriptionRequest() error = %v, want ErrWebhookTargetNotAllowed %v", err, tt.wantForbidden)
+			}
+		})
+	}
+}
+
+func TestWebhookEventFromAudit(t *testing.T) {
+	before := json.RawMessage(`{"name":"Civic"}`)
+	after := json.RawMessage(`{"name":"Civic Type R"}`)
+	diff := map[string]AuditChange{"name": {}}
+
+	entry := func(action, entityType string) AuditEntry {
+		return AuditEntry{
+			ID:         uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+			Action:     action,
+			EntityType: entityType,
+			EntityID:   "car-1",
+			Before:     before,
+			After:      after,
+			Diff:       diff,
+			CreatedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		entry       AuditEntry
+		wantOK      bool
+		wantType    WebhookEventType
+		wantObject  json.RawMessage
+		wantChanges bool
+	}{
+		{"car created", entry(AuditActionCreate, "car"), true, WebhookCarCreated, after, false},
+		{"engine updated", entry(AuditActionUpdate, "engine"), true, WebhookEngineUpdated, after, true},
+		{"car deleted sends the old object", entry(AuditActionDelete, "car"), true, WebhookCarDeleted, before, false},
+		{"entity without events", entry(AuditActionUpdate, "brand"), false, "", nil, false},
+		{"unknown action", entry("restore", "car"), false, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, ok := WebhookEventFromAudit(tt.entry)
+			if ok != tt.wantOK {
+				t.Fatalf("WebhookEventFromAudit() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if event.ID != tt.entry.ID || event.Type != tt.wantType || event.Data.ID != "car-1" || !event.CreatedAt.Equal(tt.entry.CreatedAt) {
+				t.Errorf("WebhookEventFromAudit() = %s %s %s, want %s %s car-1", event.ID, event.Type, event.Data.ID, tt.entry.ID, tt.wantType)
+			}
+			if string(event.Data.Object) != string(tt.wantObject) {
+				t.Errorf("object = %s, want %s", event.Data.Object, tt.wantObject)
+			}
+			if (event.Data.Changes != nil) != tt.wantChanges {
+				t.Errorf("changes = %v, want changes %v", event.Data.Changes, tt.wantChanges)
+			}
+		})
+	}
+}
+
+func TestNextWebhookAttempt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		attempts  int
+		wantDelay time.Duration
+		wantOK    bool
+	}{
+		{0, 30 * time.Second, true},
+		{1, 30 * time.Second, true},
+		{2, time.Minute, true},
+		{3, 2 * time.Minute, true},
+		{MaxWebhookAttempts - 1, 32 * time.Minute, true},
+		{MaxWebhookAttempts, 0, false},
+		{MaxWebhookAttempts + 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		next, ok := NextWebhookAttempt(tt.attempts, now)
+		if ok != tt.wantOK {
+			t.Errorf("NextWebhookAttempt(%d) ok = %v, want %v", tt.attempts, ok, tt.wantOK)
+			continue
+		}
+		if ok && next.Sub(now) != tt.wantDelay {
+			t.Errorf("NextWebhookAttempt(%d) = now + %s, want now + %s", tt.attempts, next.Sub(now), tt.wantDelay)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrWishlistItemNotFound = errors.New("car is not in the wishlist")
+
+// WishlistItem is a car a customer has favorited. Car is filled in when the
+// wishlist is listed.
+type WishlistItem struct {
+	CarID   uuid.UUID `json:"car_id"`
+	AddedAt time.Time `json:"added_at"`
+	Car     *Car      `json:"car,omitempty"`
+}
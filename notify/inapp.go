@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/NhutNam2904/carzone/models"
+)
+
+// Inbox stores in-app messages. Adding the same delivery twice must keep a
+// single message.
+type Inbox interface {
+	AddInboxMessage(ctx context.Context, delivery models.NotificationDelivery) error
+}
+
+// InAppChannel delivers to the customer's inbox in the database.
+type InAppChannel struct {
+	inbox Inbox
+}
+
+func NewInAppChannel(inbox Inbox) *InAppChannel {
+	return &InAppChannel{inbox: inbox}
+}
+
+func (c *InAppChannel) Name() models.NotificationChannel {
+	return models.NotificationChannelInApp
+}
+
+func (c *InAppChannel) Send(ctx context.Context, delivery models.NotificationDelivery) error {
+	return c.inbox.AddInboxMessage(ctx, delivery)
+}
@@ -0,0 +1,15 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/NhutNam2904/carzone/models"
+)
+
+// Channel delivers rendered notifications. Send may be retried with the
+// same delivery after an error, so channels should tolerate repeats.
+type Channel interface {
+	Name() models.NotificationChannel
+
+	Send(ctx context.Context, delivery models.NotificationDelivery) error
+}
@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPChannel sends plain-text email through an SMTP server. STARTTLS is
+// used when the server offers it and authentication only when a username
+// is configured, so it also works against a local test server such as
+// MailHog on localhost:1025.
+type SMTPChannel struct {
+	addr     string
+	from     mail.Address
+	username string
+	password string
+}
+
+// NewSMTPChannel sends from the given address, which may include a display
+// name, e.g. "CarZone <no-reply@carzone.local>".
+func NewSMTPChannel(addr, from, username, password string) (*SMTPChannel, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+
+	return &SMTPChannel{
+		addr:     addr,
+		from:     *sender,
+		username: username,
+		password: password,
+	}, nil
+}
+
+func (c *SMTPChannel) Name() models.NotificationChannel {
+	return models.NotificationChannelEmail
+}
+
+func (c *SMTPChannel) Send(ctx context.Context, delivery models.NotificationDelivery) error {
+	host, _, err := net.SplitHostPort(c.addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", c.addr, err)
+	}
+
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(smtpTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if c.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.username, c.password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(c.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(delivery.Recipient); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	message, err := c.message(delivery)
+	if err != nil {
+		writer.Close()
+		return err
+	}
+
+	if _, err := writer.Write(message); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// message builds the RFC 5322 message. The Message-ID is the delivery's,
+// so a retried send can be recognised as the same email.
+func (c *SMTPChannel) message(delivery models.NotificationDelivery) ([]byte, error) {
+	var body bytes.Buffer
+	encoder := quotedprintable.NewWriter(&body)
+	if _, err := encoder.Write([]byte(delivery.Body)); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(c.from.Address, "@"); at >= 0 {
+		domain = c.from.Address[at+1:]
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", c.from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", delivery.Recipient)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", delivery.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <%s@%s>\r\n", delivery.ID, domain)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
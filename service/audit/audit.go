@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	defaultEntryLimit = 50
+	maxEntryLimit     = 500
+)
+
+type AuditService struct {
+	store store.AuditStoreInterface
+}
+
+func NewAuditService(store store.AuditStoreInterface) AuditService {
+	return AuditService{store: store}
+}
+
+func (s AuditService) GetEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	tracer := otel.Tracer("AuditService")
+
+	ctx, span := tracer.Start(ctx, "GetEntries-Service")
+
+	defer span.End()
+
+	if filter.Limit <= 0 || filter.Limit > maxEntryLimit {
+		filter.Limit = defaultEntryLimit
+	}
+
+	return s.store.GetEntries(ctx, filter)
+}
@@ -0,0 +1,172 @@
+package brand
+
+import (
+	"context"
+	"log"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type BrandService struct {
+	store    store.BrandStoreInterface
+	carStore store.CarStoreInterface
+}
+
+func NewBrandService(store store.BrandStoreInterface, carStore store.CarStoreInterface) BrandService {
+	return BrandService{store: store, carStore: carStore}
+}
+
+// rebuildSuggestions refreshes brand and model suggestions after a catalog
+// rename. The rename is already committed, so a failure is only logged.
+func (s BrandService) rebuildSuggestions(ctx context.Context) {
+	if err := s.carStore.RebuildSuggestIndex(ctx); err != nil {
+		log.Println("Error rebuilding the suggest index: ", err)
+	}
+}
+
+func (s BrandService) GetBrands(ctx context.Context) ([]models.Brand, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "GetBrands-Service")
+
+	defer span.End()
+
+	return s.store.GetBrands(ctx)
+}
+
+func (s BrandService) GetBrandById(ctx context.Context, id string) (models.Brand, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "GetBrandByID-Service")
+
+	defer span.End()
+
+	return s.store.GetBrandById(ctx, id)
+}
+
+func (s BrandService) CreateBrand(ctx context.Context, brandReq *models.BrandRequest) (models.Brand, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "CreateBrand-Service")
+
+	defer span.End()
+
+	if err := models.ValidateBrandRequest(*brandReq); err != nil {
+		return models.Brand{}, err
+	}
+
+	return s.store.CreateBrand(ctx, brandReq)
+}
+
+func (s BrandService) UpdateBrand(ctx context.Context, actor models.AuditActor, id string, brandReq *models.BrandRequest) (models.Brand, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "UpdateBrand-Service")
+
+	defer span.End()
+
+	if err := models.ValidateBrandRequest(*brandReq); err != nil {
+		return models.Brand{}, err
+	}
+
+	oldBrand, err := s.store.GetBrandById(ctx, id)
+	if err != nil {
+		return models.Brand{}, err
+	}
+
+	brand, err := s.store.UpdateBrand(ctx, actor, id, brandReq)
+	if err != nil {
+		return models.Brand{}, err
+	}
+
+	// Cars are cached by brand name, so both the old and the new name may
+	// hold stale lists. The rename is committed; a failure is only logged.
+	if err := s.carStore.InvalidateBrandCache(ctx, oldBrand.Name, brand.Name); err != nil {
+		log.Println("Error invalidating the brand cache: ", err)
+	}
+
+	s.rebuildSuggestions(ctx)
+
+	return brand, nil
+}
+
+func (s BrandService) DeleteBrand(ctx context.Context, id string) (models.Brand, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "DeleteBrand-Service")
+
+	defer span.End()
+
+	return s.store.DeleteBrand(ctx, id)
+}
+
+func (s BrandService) GetModelsByBrand(ctx context.Context, brandID string) ([]models.CarModel, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "GetModelsByBrand-Service")
+
+	defer span.End()
+
+	return s.store.GetModelsByBrand(ctx, brandID)
+}
+
+func (s BrandService) GetModelById(ctx context.Context, id string) (models.CarModel, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "GetModelByID-Service")
+
+	defer span.End()
+
+	return s.store.GetModelById(ctx, id)
+}
+
+func (s BrandService) CreateModel(ctx context.Context, brandID string, modelReq *models.CarModelRequest) (models.CarModel, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "CreateModel-Service")
+
+	defer span.End()
+
+	if err := models.ValidateCarModelRequest(*modelReq); err != nil {
+		return models.CarModel{}, err
+	}
+
+	if _, err := s.store.GetBrandById(ctx, brandID); err != nil {
+		return models.CarModel{}, err
+	}
+
+	return s.store.CreateModel(ctx, brandID, modelReq)
+}
+
+func (s BrandService) UpdateModel(ctx context.Context, id string, modelReq *models.CarModelRequest) (models.CarModel, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "UpdateModel-Service")
+
+	defer span.End()
+
+	if err := models.ValidateCarModelRequest(*modelReq); err != nil {
+		return models.CarModel{}, err
+	}
+
+	carModel, err := s.store.UpdateModel(ctx, id, modelReq)
+	if err != nil {
+		return models.CarModel{}, err
+	}
+
+	s.rebuildSuggestions(ctx)
+
+	return carModel, nil
+}
+
+func (s BrandService) DeleteModel(ctx context.Context, id string) (models.CarModel, error) {
+	tracer := otel.Tracer("BrandService")
+
+	ctx, span := tracer.Start(ctx, "DeleteModel-Service")
+
+	defer span.End()
+
+	return s.store.DeleteModel(ctx, id)
+}
@@ -2,19 +2,41 @@ package car
 
 import (
 	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
 	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 )
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
 type CarService struct {
-	store store.CarStoreInterface
+	store      store.CarStoreInterface
+	rates      service.ExchangeRateServiceInterface
+	inventory  service.InventoryServiceInterface
+	promotions service.PromotionServiceInterface
+	taxes      service.TaxServiceInterface
 }
 
-func NewCarService(store store.CarStoreInterface) CarService {
+func NewCarService(store store.CarStoreInterface, rates service.ExchangeRateServiceInterface, inventory service.InventoryServiceInterface, promotions service.PromotionServiceInterface, taxes service.TaxServiceInterface) CarService {
 	return CarService{
-		store: store,
+		store:      store,
+		rates:      rates,
+		inventory:  inventory,
+		promotions: promotions,
+		taxes:      taxes,
 	}
 }
 
@@ -28,8 +50,25 @@ func (s CarService) GetCarById(ctx context.Context, id string) (*models.Car, err
 
 	car, err := s.store.GetCarById(ctx, id)
 
+	if err != nil || car.ID == uuid.Nil {
+		return car, err
+	}
+
+	if err := s.decorate(ctx, car); err != nil {
+		return car, err
+	}
+
+	// On-road prices are a convenience; a car whose price cannot be
+	// converted or taxed is still shown without them.
+	onRoadPrices, err := s.taxes.GetOnRoadPrices(ctx, *car)
+	if err != nil {
+		log.Printf("Error computing on-road prices for car %s: %v", car.ID, err)
+		return car, nil
+	}
+	car.OnRoadPrices = onRoadPrices
+
 	//fmt.Printf("Car in layer service: %v", car)
-	return car, err
+	return car, nil
 
 }
 
@@ -46,11 +85,11 @@ func (s CarService) GetCarByBrand(ctx context.Context, brand string, isEngine bo
 	if err != nil {
 		return nil, err
 	}
-	return cars, nil
+	return cars, s.decorate(ctx, carRefs(cars)...)
 
 }
 
-func (s CarService) CreateCar(ctx context.Context, carReq *models.CarRequest) (models.Car, error) {
+func (s CarService) CreateCar(ctx context.Context, actor models.AuditActor, carReq *models.CarRequest) (models.Car, error) {
 
 	tracer := otel.Tracer("CarService")
 
@@ -64,7 +103,7 @@ func (s CarService) CreateCar(ctx context.Context, carReq *models.CarRequest) (m
 		return models.Car{}, err
 	}
 
-	car, err := s.store.CreateCar(ctx, carReq)
+	car, err := s.store.CreateCar(ctx, actor, carReq)
 
 	if err != nil {
 		return models.Car{}, err
@@ -72,13 +111,13 @@ func (s CarService) CreateCar(ctx context.Context, carReq *models.CarRequest) (m
 	return car, err
 }
 
-func (s CarService) DeleteCar(ctx context.Context, id string) (models.Car, error) {
+func (s CarService) DeleteCar(ctx context.Context, actor models.AuditActor, id string) (models.Car, error) {
 	tracer := otel.Tracer("CarService")
 
 	ctx, span := tracer.Start(ctx, "DeleteCar-Service")
 
 	defer span.End()
-	car, err := s.store.DeleteCar(ctx, id)
+	car, err := s.store.DeleteCar(ctx, actor, id)
 
 	if err != nil {
 		return models.Car{}, err
@@ -87,14 +126,19 @@ func (s CarService) DeleteCar(ctx context.Context, id string) (models.Car, error
 	return car, err
 }
 
-func (s CarService) UpdateCar(ctx context.Context, id string, carReq *models.CarRequest) (models.Car, error) {
+func (s CarService) UpdateCar(ctx context.Context, actor models.AuditActor, id string, carReq *models.CarRequest) (models.Car, error) {
 
 	tracer := otel.Tracer("CarService")
 
 	ctx, span := tracer.Start(ctx, "UpdateCar-Service")
 
 	defer span.End()
-	car, err := s.store.UpdateCar(ctx, id, carReq)
+
+	if err := models.ValidateCarRequest(*carReq); err != nil {
+		return models.Car{}, err
+	}
+
+	car, err := s.store.UpdateCar(ctx, actor, id, carReq)
 
 	if err != nil {
 		return models.Car{}, err
@@ -102,3 +146,182 @@ func (s CarService) UpdateCar(ctx context.Context, id string, carReq *models.Car
 
 	return car, err
 }
+
+func (s CarService) SearchCars(ctx context.Context, q string, limit int) ([]models.CarSearchResult, error) {
+
+	tracer := otel.Tracer("CarService")
+
+	ctx, span := tracer.Start(ctx, "SearchCars-Service")
+
+	defer span.End()
+
+	q = strings.TrimSpace(q)
+
+	if q == "" {
+		return nil, errors.New("Search query is Required")
+	}
+
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	results, err := s.store.SearchCars(ctx, q, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]*models.Car, 0, len(results))
+	for i := range results {
+		refs = append(refs, &results[i].Car)
+	}
+
+	return results, s.decorate(ctx, refs...)
+}
+
+func (s CarService) Suggest(ctx context.Context, prefix string, limit int) (models.Suggestions, error) {
+
+	tracer := otel.Tracer("CarService")
+
+	ctx, span := tracer.Start(ctx, "Suggest-Service")
+
+	defer span.End()
+
+	prefix = strings.TrimSpace(prefix)
+
+	if prefix == "" {
+		return models.Suggestions{Brands: []models.Suggestion{}, Models: []models.Suggestion{}}, nil
+	}
+
+	if limit <= 0 || limit > maxSuggestLimit {
+		limit = defaultSuggestLimit
+	}
+
+	return s.store.Suggest(ctx, prefix, limit)
+}
+
+// ConvertPrice sets car.DisplayPrice to the car's price in the requested
+// currency. The stored price is left untouched.
+func (s CarService) ConvertPrice(ctx context.Context, car *models.Car, currency string) error {
+
+	tracer := otel.Tracer("CarService")
+
+	ctx, span := tracer.Start(ctx, "ConvertPrice-Service")
+
+	defer span.End()
+
+	displayPrice, err := s.rates.Convert(ctx, car.Price, currency)
+
+	if err != nil {
+		return err
+	}
+
+	car.DisplayPrice = &displayPrice
+	return nil
+}
+
+func (s CarService) ListCars(ctx context.Context, filter models.CarFilter) ([]models.Car, error) {
+
+	tracer := otel.Tracer("CarService")
+
+	ctx, span := tracer.Start(ctx, "ListCars-Service")
+
+	defer span.End()
+
+	if filter.ReducedWithinDays < 0 {
+		return nil, errors.New("reducedWithinDays must not be negative")
+	}
+
+	cars, err := s.store.ListCars(ctx, filter)
+
+	if err != nil {
+		return nil, err
+	}
+	return cars, s.decorate(ctx, carRefs(cars)...)
+}
+
+// GetPriceHistory lists the car's price changes, or ErrCarNotFound so an
+// unknown car is not mistaken for one whose price never changed.
+func (s CarService) GetPriceHistory(ctx context.Context, carID string) ([]models.PriceChange, error) {
+
+	tracer := otel.Tracer("CarService")
+
+	ctx, span := tracer.Start(ctx, "GetPriceHistory-Service")
+
+	defer span.End()
+
+	car, err := s.store.GetCarById(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if car.ID == uuid.Nil {
+		return nil, models.ErrCarNotFound
+	}
+
+	return s.store.GetPriceHistory(ctx, carID)
+}
+
+// decorate adds what car reads show beyond the stored row: stock counts
+// and the effective price after promotions.
+func (s CarService) decorate(ctx context.Context, cars ...*models.Car) error {
+	if err := s.attachAvailability(ctx, cars...); err != nil {
+		return err
+	}
+	return s.attachPromotions(ctx, cars...)
+}
+
+// attachAvailability sets the inventory counts on each car with a single
+// lookup for the whole batch.
+func (s CarService) attachAvailability(ctx context.Context, cars ...*models.Car) error {
+	if len(cars) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(cars))
+	for _, car := range cars {
+		ids = append(ids, car.ID)
+	}
+
+	availability, err := s.inventory.GetAvailability(ctx, ids)
+
+	if err != nil {
+		return err
+	}
+
+	for _, car := range cars {
+		counts := availability[car.ID]
+		car.Availability = &counts
+	}
+	return nil
+}
+
+// attachPromotions sets each car's effective price from the promotions live
+// now, loaded once for the whole batch.
+func (s CarService) attachPromotions(ctx context.Context, cars ...*models.Car) error {
+	if len(cars) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	promotions, err := s.promotions.GetLivePromotions(ctx, now)
+
+	if err != nil {
+		return err
+	}
+
+	for _, car := range cars {
+		effectivePrice, applied := models.ApplyPromotions(*car, promotions, now)
+		car.EffectivePrice = &effectivePrice
+		car.Promotions = applied
+	}
+	return nil
+}
+
+func carRefs(cars []models.Car) []*models.Car {
+	refs := make([]*models.Car, 0, len(cars))
+	for i := range cars {
+		refs = append(refs, &cars[i])
+	}
+	return refs
+}
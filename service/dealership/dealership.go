@@ -0,0 +1,75 @@
+package dealership
+
+import (
+	"context"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type DealershipService struct {
+	store store.DealershipStoreInterface
+}
+
+func NewDealershipService(store store.DealershipStoreInterface) DealershipService {
+	return DealershipService{store: store}
+}
+
+func (s DealershipService) GetDealerships(ctx context.Context) ([]models.Dealership, error) {
+	tracer := otel.Tracer("DealershipService")
+
+	ctx, span := tracer.Start(ctx, "GetDealerships-Service")
+
+	defer span.End()
+
+	return s.store.GetDealerships(ctx)
+}
+
+func (s DealershipService) GetDealershipById(ctx context.Context, id string) (models.Dealership, error) {
+	tracer := otel.Tracer("DealershipService")
+
+	ctx, span := tracer.Start(ctx, "GetDealershipByID-Service")
+
+	defer span.End()
+
+	return s.store.GetDealershipById(ctx, id)
+}
+
+func (s DealershipService) CreateDealership(ctx context.Context, dealershipReq *models.DealershipRequest) (models.Dealership, error) {
+	tracer := otel.Tracer("DealershipService")
+
+	ctx, span := tracer.Start(ctx, "CreateDealership-Service")
+
+	defer span.End()
+
+	if err := models.ValidateDealershipRequest(*dealershipReq); err != nil {
+		return models.Dealership{}, err
+	}
+
+	return s.store.CreateDealership(ctx, dealershipReq)
+}
+
+func (s DealershipService) UpdateDealership(ctx context.Context, id string, dealershipReq *models.DealershipRequest) (models.Dealership, error) {
+	tracer := otel.Tracer("DealershipService")
+
+	ctx, span := tracer.Start(ctx, "UpdateDealership-Service")
+
+	defer span.End()
+
+	if err := models.ValidateDealershipRequest(*dealershipReq); err != nil {
+		return models.Dealership{}, err
+	}
+
+	return s.store.UpdateDealership(ctx, id, dealershipReq)
+}
+
+func (s DealershipService) DeleteDealership(ctx context.Context, id string) (models.Dealership, error) {
+	tracer := otel.Tracer("DealershipService")
+
+	ctx, span := tracer.Start(ctx, "DeleteDealership-Service")
+
+	defer span.End()
+
+	return s.store.DeleteDealership(ctx, id)
+}
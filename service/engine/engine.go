@@ -30,13 +30,22 @@ func (s EngineService) EngineById(ctx context.Context, id string) (models.Engine
 	return engine, nil
 }
 
-func (s EngineService) CreateEngine(ctx context.Context, engineReq *models.EngineRequest) (models.Engine, error) {
+func (s EngineService) CreateEngine(ctx context.Context, actor models.AuditActor, engineReq *models.EngineRequest) (models.Engine, error) {
 	tracer := otel.Tracer("EngineService")
 
 	ctx, span := tracer.Start(ctx, "CreateEngine-Service")
 
 	defer span.End()
-	engine, err := s.store.CreateEngine(ctx, engineReq)
+
+	if engineReq.Powertrain == "" {
+		engineReq.Powertrain = models.PowertrainCombustion
+	}
+
+	if err := models.ValidateEngineRequest(*engineReq); err != nil {
+		return models.Engine{}, err
+	}
+
+	engine, err := s.store.CreateEngine(ctx, actor, engineReq)
 	if err != nil {
 		return models.Engine{}, err
 	}
@@ -44,14 +53,22 @@ func (s EngineService) CreateEngine(ctx context.Context, engineReq *models.Engin
 	return engine, nil
 }
 
-func (s EngineService) EngineUpdate(ctx context.Context, id string, engineReq *models.EngineRequest) (models.Engine, error) {
+func (s EngineService) EngineUpdate(ctx context.Context, actor models.AuditActor, id string, engineReq *models.EngineRequest) (models.Engine, error) {
 	tracer := otel.Tracer("EngineService")
 
 	ctx, span := tracer.Start(ctx, "EngineUpdate-Service")
 
 	defer span.End()
 
-	engine, err := s.store.EngineUpdate(ctx, id, engineReq)
+	if engineReq.Powertrain == "" {
+		engineReq.Powertrain = models.PowertrainCombustion
+	}
+
+	if err := models.ValidateEngineRequest(*engineReq); err != nil {
+		return models.Engine{}, err
+	}
+
+	engine, err := s.store.EngineUpdate(ctx, actor, id, engineReq)
 
 	if err != nil {
 		return models.Engine{}, err
@@ -60,13 +77,13 @@ func (s EngineService) EngineUpdate(ctx context.Context, id string, engineReq *m
 	return engine, nil
 }
 
-func (s EngineService) DeleteEngine(ctx context.Context, id string) (models.Engine, error) {
+func (s EngineService) DeleteEngine(ctx context.Context, actor models.AuditActor, id string) (models.Engine, error) {
 	tracer := otel.Tracer("EngineService")
 
 	ctx, span := tracer.Start(ctx, "DeleteEngine-Service")
 
 	defer span.End()
-	engine, err := s.store.DeleteEngine(ctx, id)
+	engine, err := s.store.DeleteEngine(ctx, actor, id)
 
 	if err != nil {
 		return models.Engine{}, err
@@ -0,0 +1,166 @@
+package exchangerate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+type ExchangeRateService struct {
+	store store.ExchangeRateStoreInterface
+}
+
+func NewExchangeRateService(store store.ExchangeRateStoreInterface) ExchangeRateService {
+	return ExchangeRateService{store: store}
+}
+
+func (s ExchangeRateService) GetRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	tracer := otel.Tracer("ExchangeRateService")
+
+	ctx, span := tracer.Start(ctx, "GetRates-Service")
+
+	defer span.End()
+
+	return s.store.GetRates(ctx)
+}
+
+func (s ExchangeRateService) UpsertRates(ctx context.Context, rates []models.ExchangeRate) error {
+	tracer := otel.Tracer("ExchangeRateService")
+
+	ctx, span := tracer.Start(ctx, "UpsertRates-Service")
+
+	defer span.End()
+
+	if err := normalizeRates(rates, time.Now()); err != nil {
+		return err
+	}
+
+	return s.store.UpsertRates(ctx, rates)
+}
+
+// LoadRatesFromFile reads a JSON array of exchange rates, the same shape the
+// admin endpoint accepts, and stores the pairs that are not stored yet.
+// Rates without updated_at are dated by the file's modification time, so a
+// restart neither overwrites admin-set rates nor makes old ones look fresh.
+func (s ExchangeRateService) LoadRatesFromFile(ctx context.Context, fileName string) error {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	var rates []models.ExchangeRate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return err
+	}
+
+	if err := normalizeRates(rates, info.ModTime()); err != nil {
+		return err
+	}
+
+	return s.store.SeedRates(ctx, rates)
+}
+
+// normalizeRates upper-cases the currency codes, dates rates that carry no
+// updated_at and validates each rate.
+func normalizeRates(rates []models.ExchangeRate, updatedAt time.Time) error {
+	for i := range rates {
+		rates[i].Base = strings.ToUpper(strings.TrimSpace(rates[i].Base))
+		rates[i].Quote = strings.ToUpper(strings.TrimSpace(rates[i].Quote))
+		if rates[i].UpdatedAt.IsZero() {
+			rates[i].UpdatedAt = updatedAt
+		}
+		if err := models.ValidateExchangeRate(rates[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Convert converts money into the given currency. Rates are looked up
+// directly, then inverted, then crossed through models.DefaultCurrency.
+func (s ExchangeRateService) Convert(ctx context.Context, money models.Money, currency string) (models.ConvertedPrice, error) {
+	tracer := otel.Tracer("ExchangeRateService")
+
+	ctx, span := tracer.Start(ctx, "Convert-Service")
+
+	defer span.End()
+
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	if !models.IsValidCurrency(currency) {
+		return models.ConvertedPrice{}, fmt.Errorf("%w: %s", models.ErrUnsupportedCurrency, currency)
+	}
+
+	rate, err := s.resolveRate(ctx, money.Currency, currency)
+	if err != nil {
+		return models.ConvertedPrice{}, err
+	}
+
+	price, err := rate.Convert(money)
+	if err != nil {
+		return models.ConvertedPrice{}, err
+	}
+
+	return models.ConvertedPrice{
+		Price:         price,
+		Rate:          rate.Rate,
+		RateUpdatedAt: rate.UpdatedAt,
+	}, nil
+}
+
+func (s ExchangeRateService) resolveRate(ctx context.Context, base, quote string) (models.ExchangeRate, error) {
+	if base == quote {
+		return models.ExchangeRate{Base: base, Quote: quote, Rate: decimal.NewFromInt(1), UpdatedAt: time.Now()}, nil
+	}
+
+	rate, err := s.pairRate(ctx, base, quote)
+	if err == nil || !errors.Is(err, models.ErrExchangeRateNotFound) {
+		return rate, err
+	}
+
+	if base == models.DefaultCurrency || quote == models.DefaultCurrency {
+		return models.ExchangeRate{}, err
+	}
+
+	toPivot, err := s.pairRate(ctx, base, models.DefaultCurrency)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	fromPivot, err := s.pairRate(ctx, models.DefaultCurrency, quote)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	return toPivot.Cross(fromPivot)
+}
+
+// pairRate returns the stored rate for base to quote, or the inverse of the
+// stored quote to base rate.
+func (s ExchangeRateService) pairRate(ctx context.Context, base, quote string) (models.ExchangeRate, error) {
+	rate, err := s.store.GetRate(ctx, base, quote)
+	if err == nil || !errors.Is(err, models.ErrExchangeRateNotFound) {
+		return rate, err
+	}
+
+	inverse, err := s.store.GetRate(ctx, quote, base)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	return inverse.Inverse(), nil
+}
@@ -0,0 +1,126 @@
+package financing
+
+import (
+	"context"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type FinancingService struct {
+	store store.FinancingStoreInterface
+	cars  store.CarStoreInterface
+}
+
+func NewFinancingService(store store.FinancingStoreInterface, cars store.CarStoreInterface) FinancingService {
+	return FinancingService{
+		store: store,
+		cars:  cars,
+	}
+}
+
+func (s FinancingService) GetLenderProducts(ctx context.Context, activeOnly bool) ([]models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingService")
+
+	ctx, span := tracer.Start(ctx, "GetLenderProducts-Service")
+
+	defer span.End()
+
+	return s.store.GetLenderProducts(ctx, activeOnly)
+}
+
+func (s FinancingService) GetLenderProductById(ctx context.Context, id string) (models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingService")
+
+	ctx, span := tracer.Start(ctx, "GetLenderProductByID-Service")
+
+	defer span.End()
+
+	return s.store.GetLenderProductById(ctx, id)
+}
+
+func (s FinancingService) CreateLenderProduct(ctx context.Context, productReq *models.LenderProductRequest) (models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingService")
+
+	ctx, span := tracer.Start(ctx, "CreateLenderProduct-Service")
+
+	defer span.End()
+
+	if err := models.ValidateLenderProductRequest(*productReq); err != nil {
+		return models.LenderProduct{}, err
+	}
+
+	return s.store.CreateLenderProduct(ctx, productReq)
+}
+
+func (s FinancingService) UpdateLenderProduct(ctx context.Context, id string, productReq *models.LenderProductRequest) (models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingService")
+
+	ctx, span := tracer.Start(ctx, "UpdateLenderProduct-Service")
+
+	defer span.End()
+
+	if err := models.ValidateLenderProductRequest(*productReq); err != nil {
+		return models.LenderProduct{}, err
+	}
+
+	return s.store.UpdateLenderProduct(ctx, id, productReq)
+}
+
+func (s FinancingService) DeleteLenderProduct(ctx context.Context, id string) (models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingService")
+
+	ctx, span := tracer.Start(ctx, "DeleteLenderProduct-Service")
+
+	defer span.End()
+
+	return s.store.DeleteLenderProduct(ctx, id)
+}
+
+// QuoteFinancing prices a loan on the car's current price. With a product
+// the product's APR is used and its term and down payment limits apply.
+func (s FinancingService) QuoteFinancing(ctx context.Context, carID string, quoteReq *models.FinancingQuoteRequest) (models.FinancingQuote, error) {
+	tracer := otel.Tracer("FinancingService")
+
+	ctx, span := tracer.Start(ctx, "QuoteFinancing-Service")
+
+	defer span.End()
+
+	if err := models.ValidateFinancingQuoteRequest(*quoteReq); err != nil {
+		return models.FinancingQuote{}, err
+	}
+
+	car, err := s.cars.GetCarById(ctx, carID)
+	if err != nil {
+		return models.FinancingQuote{}, err
+	}
+	if car.ID == uuid.Nil {
+		return models.FinancingQuote{}, models.ErrCarNotFound
+	}
+
+	apr := quoteReq.APR
+
+	if quoteReq.ProductID != nil {
+		product, err := s.store.GetLenderProductById(ctx, quoteReq.ProductID.String())
+		if err != nil {
+			return models.FinancingQuote{}, err
+		}
+
+		if err := product.CheckQuote(car.Price, *quoteReq); err != nil {
+			return models.FinancingQuote{}, err
+		}
+		apr = &product.APR
+	}
+
+	quote, err := models.NewFinancingQuote(car.Price, quoteReq.DownPayment, quoteReq.TermMonths, *apr)
+	if err != nil {
+		return models.FinancingQuote{}, err
+	}
+
+	quote.CarID = car.ID
+	quote.ProductID = quoteReq.ProductID
+
+	return quote, nil
+}
@@ -2,23 +2,183 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
 )
 
 type CarServiceInterface interface {
 	GetCarById(ctx context.Context, id string) (*models.Car, error)
 	GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error)
-	CreateCar(ctx context.Context, carReq *models.CarRequest) (models.Car, error)
-	DeleteCar(ctx context.Context, id string) (models.Car, error)
-	UpdateCar(ctx context.Context, id string, carReq *models.CarRequest) (models.Car, error)
+	CreateCar(ctx context.Context, actor models.AuditActor, carReq *models.CarRequest) (models.Car, error)
+	DeleteCar(ctx context.Context, actor models.AuditActor, id string) (models.Car, error)
+	UpdateCar(ctx context.Context, actor models.AuditActor, id string, carReq *models.CarRequest) (models.Car, error)
+	SearchCars(ctx context.Context, q string, limit int) ([]models.CarSearchResult, error)
+	Suggest(ctx context.Context, prefix string, limit int) (models.Suggestions, error)
+	ConvertPrice(ctx context.Context, car *models.Car, currency string) error
+	ListCars(ctx context.Context, filter models.CarFilter) ([]models.Car, error)
+	GetPriceHistory(ctx context.Context, carID string) ([]models.PriceChange, error)
 }
 
 type EngineServiceInterface interface {
 	EngineById(ctx context.Context, id string) (models.Engine, error)
-	CreateEngine(ctx context.Context, engineReq *models.EngineRequest) (models.Engine, error)
-	EngineUpdate(ctx context.Context, id string, engineReq *models.EngineRequest) (models.Engine, error)
-	DeleteEngine(ctx context.Context, id string) (models.Engine, error)
+	CreateEngine(ctx context.Context, actor models.AuditActor, engineReq *models.EngineRequest) (models.Engine, error)
+	EngineUpdate(ctx context.Context, actor models.AuditActor, id string, engineReq *models.EngineRequest) (models.Engine, error)
+	DeleteEngine(ctx context.Context, actor models.AuditActor, id string) (models.Engine, error)
+}
+
+type BrandServiceInterface interface {
+	GetBrands(ctx context.Context) ([]models.Brand, error)
+	GetBrandById(ctx context.Context, id string) (models.Brand, error)
+	CreateBrand(ctx context.Context, brandReq *models.BrandRequest) (models.Brand, error)
+	UpdateBrand(ctx context.Context, actor models.AuditActor, id string, brandReq *models.BrandRequest) (models.Brand, error)
+	DeleteBrand(ctx context.Context, id string) (models.Brand, error)
+	GetModelsByBrand(ctx context.Context, brandID string) ([]models.CarModel, error)
+	GetModelById(ctx context.Context, id string) (models.CarModel, error)
+	CreateModel(ctx context.Context, brandID string, modelReq *models.CarModelRequest) (models.CarModel, error)
+	UpdateModel(ctx context.Context, id string, modelReq *models.CarModelRequest) (models.CarModel, error)
+	DeleteModel(ctx context.Context, id string) (models.CarModel, error)
+}
+
+type ExchangeRateServiceInterface interface {
+	GetRates(ctx context.Context) ([]models.ExchangeRate, error)
+	UpsertRates(ctx context.Context, rates []models.ExchangeRate) error
+	Convert(ctx context.Context, money models.Money, currency string) (models.ConvertedPrice, error)
+}
+
+type AuditServiceInterface interface {
+	GetEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+type DealershipServiceInterface interface {
+	GetDealerships(ctx context.Context) ([]models.Dealership, error)
+	GetDealershipById(ctx context.Context, id string) (models.Dealership, error)
+	CreateDealership(ctx context.Context, dealershipReq *models.DealershipRequest) (models.Dealership, error)
+	UpdateDealership(ctx context.Context, id string, dealershipReq *models.DealershipRequest) (models.Dealership, error)
+	DeleteDealership(ctx context.Context, id string) (models.Dealership, error)
+}
+
+type InventoryServiceInterface interface {
+	GetUnitsByCar(ctx context.Context, carID string) ([]models.InventoryUnit, error)
+	GetUnitById(ctx context.Context, id string) (models.InventoryUnit, error)
+	CreateUnit(ctx context.Context, carID string, unitReq *models.InventoryUnitRequest) (models.InventoryUnit, error)
+	UpdateUnit(ctx context.Context, id string, unitReq *models.InventoryUnitRequest) (models.InventoryUnit, error)
+	DeleteUnit(ctx context.Context, id string) (models.InventoryUnit, error)
+	GetAvailability(ctx context.Context, carIDs []uuid.UUID) (map[uuid.UUID]models.Availability, error)
+	DecodeVIN(ctx context.Context, vin string) (models.VINInfo, error)
+}
+
+type ReservationServiceInterface interface {
+	CreateReservation(ctx context.Context, customer string, reservationReq *models.ReservationRequest) (models.Reservation, error)
+	GetReservationById(ctx context.Context, id, customer string) (models.Reservation, error)
+	GetReservationsByCustomer(ctx context.Context, customer string) ([]models.Reservation, error)
+	CancelReservation(ctx context.Context, id, customer string) (models.Reservation, error)
+}
+
+type TestDriveServiceInterface interface {
+	GetSlots(ctx context.Context, dealershipID, carID string, date time.Time) ([]models.TestDriveSlot, error)
+	CreateTestDrive(ctx context.Context, customer string, testDriveReq *models.TestDriveRequest) (models.TestDrive, error)
+	RescheduleTestDrive(ctx context.Context, id, customer string, rescheduleReq *models.TestDriveRescheduleRequest) (models.TestDrive, error)
+	CancelTestDrive(ctx context.Context, id, customer string) (models.TestDrive, error)
+	UpdateTestDriveStatus(ctx context.Context, id string, statusReq *models.TestDriveStatusRequest) (models.TestDrive, error)
+	GetTestDrivesByCustomer(ctx context.Context, customer string) ([]models.TestDrive, error)
+	GetTestDriveById(ctx context.Context, id, customer string) (models.TestDrive, error)
+}
+
+type OrderServiceInterface interface {
+	CreateOrder(ctx context.Context, customer string, orderReq *models.OrderRequest) (models.Order, error)
+	GetOrderById(ctx context.Context, id, customer string) (models.Order, error)
+	GetOrdersByCustomer(ctx context.Context, customer string) ([]models.Order, error)
+	GetOrdersByDealership(ctx context.Context, dealershipID string) ([]models.Order, error)
+	UpdateOrderStatus(ctx context.Context, id, caller string, staff bool, statusReq *models.OrderStatusRequest) (models.Order, error)
+	SetOrderDiscount(ctx context.Context, id string, discountReq *models.OrderDiscountRequest) (models.Order, error)
+}
+
+type PaymentServiceInterface interface {
+	CreatePayment(ctx context.Context, orderID, customer string, paymentReq *models.PaymentRequest) (models.Payment, error)
+	GetPaymentById(ctx context.Context, id, customer string) (models.Payment, error)
+	GetPaymentsByOrder(ctx context.Context, orderID, customer string) ([]models.Payment, error)
+	CapturePayment(ctx context.Context, id, caller string, staff bool, amountReq *models.PaymentAmountRequest) (models.Payment, error)
+	RefundPayment(ctx context.Context, id, caller string, staff bool, amountReq *models.PaymentAmountRequest) (models.Payment, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+}
+
+type FinancingServiceInterface interface {
+	GetLenderProducts(ctx context.Context, activeOnly bool) ([]models.LenderProduct, error)
+	GetLenderProductById(ctx context.Context, id string) (models.LenderProduct, error)
+	CreateLenderProduct(ctx context.Context, productReq *models.LenderProductRequest) (models.LenderProduct, error)
+	UpdateLenderProduct(ctx context.Context, id string, productReq *models.LenderProductRequest) (models.LenderProduct, error)
+	DeleteLenderProduct(ctx context.Context, id string) (models.LenderProduct, error)
+	QuoteFinancing(ctx context.Context, carID string, quoteReq *models.FinancingQuoteRequest) (models.FinancingQuote, error)
+}
+
+type ValuationServiceInterface interface {
+	GetCurves(ctx context.Context) ([]models.DepreciationCurve, error)
+	GetCurveById(ctx context.Context, id string) (models.DepreciationCurve, error)
+	CreateCurve(ctx context.Context, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error)
+	UpdateCurve(ctx context.Context, id string, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error)
+	DeleteCurve(ctx context.Context, id string) (models.DepreciationCurve, error)
+	ValueTradeIn(ctx context.Context, tradeInReq *models.TradeInRequest) (models.TradeInValuation, error)
+}
+
+type PromotionServiceInterface interface {
+	GetPromotions(ctx context.Context, all bool) ([]models.Promotion, error)
+	GetLivePromotions(ctx context.Context, at time.Time) ([]models.Promotion, error)
+	GetPromotionById(ctx context.Context, id string) (models.Promotion, error)
+	CreatePromotion(ctx context.Context, promotionReq *models.PromotionRequest) (models.Promotion, error)
+	UpdatePromotion(ctx context.Context, id string, promotionReq *models.PromotionRequest) (models.Promotion, error)
+	DeletePromotion(ctx context.Context, id string) (models.Promotion, error)
+}
+
+type TaxServiceInterface interface {
+	GetTaxRegions(ctx context.Context) ([]models.TaxRegion, error)
+	GetTaxRegion(ctx context.Context, code string) (models.TaxRegion, error)
+	UpsertTaxRegion(ctx context.Context, code string, regionReq *models.TaxRegionRequest) (models.TaxRegion, error)
+	DeleteTaxRegion(ctx context.Context, code string) (models.TaxRegion, error)
+	GetSCTRules(ctx context.Context) ([]models.SCTRule, error)
+	CreateSCTRule(ctx context.Context, ruleReq *models.SCTRuleRequest) (models.SCTRule, error)
+	UpdateSCTRule(ctx context.Context, id string, ruleReq *models.SCTRuleRequest) (models.SCTRule, error)
+	DeleteSCTRule(ctx context.Context, id string) (models.SCTRule, error)
+	FindSCTRule(ctx context.Context, car models.Car) (models.SCTRule, error)
+	GetOnRoadPrices(ctx context.Context, car models.Car) ([]models.OnRoadPrice, error)
+}
+
+type WishlistServiceInterface interface {
+	GetWishlist(ctx context.Context, customer string) ([]models.WishlistItem, error)
+	AddToWishlist(ctx context.Context, customer, carID string) (models.WishlistItem, error)
+	RemoveFromWishlist(ctx context.Context, customer, carID string) error
+}
+
+type SavedSearchServiceInterface interface {
+	GetSavedSearches(ctx context.Context, customer string) ([]models.SavedSearch, error)
+	GetSavedSearchById(ctx context.Context, id, customer string) (models.SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, customer string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, id, customer string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id, customer string) (models.SavedSearch, error)
+	GetMatches(ctx context.Context, id, customer string) ([]models.SavedSearchMatch, error)
+}
+
+type NotificationServiceInterface interface {
+	Notify(ctx context.Context, event models.NotificationEvent) error
+	GetInbox(ctx context.Context, customer string, unreadOnly bool) ([]models.InboxMessage, error)
+	MarkInboxMessageRead(ctx context.Context, id, customer string) (models.InboxMessage, error)
+	GetPreferences(ctx context.Context, customer string) (models.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, customer string, preferencesReq *models.NotificationPreferencesRequest) (models.NotificationPreferences, error)
+	GetTemplates(ctx context.Context) ([]models.NotificationTemplate, error)
+	UpsertTemplate(ctx context.Context, kind models.NotificationKind, templateReq *models.NotificationTemplateRequest) (models.NotificationTemplate, error)
+	GetDeliveries(ctx context.Context, status string) ([]models.NotificationDelivery, error)
+}
+
+type WebhookServiceInterface interface {
+	GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscriptionById(ctx context.Context, id string) (models.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, subscriptionReq *models.WebhookSubscriptionRequest) (models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id string, subscriptionReq *models.WebhookSubscriptionRequest) (models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) (models.WebhookSubscription, error)
+	GetDeliveries(ctx context.Context, subscriptionID string, status string) ([]models.WebhookDelivery, error)
+	GetDeliveryById(ctx context.Context, id string) (models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id string) (models.WebhookDelivery, error)
 }
 
 //type LoginServiceInterface interface {
@@ -0,0 +1,137 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type InventoryService struct {
+	store store.InventoryStoreInterface
+	cars  store.CarStoreInterface
+}
+
+func NewInventoryService(store store.InventoryStoreInterface, cars store.CarStoreInterface) InventoryService {
+	return InventoryService{
+		store: store,
+		cars:  cars,
+	}
+}
+
+func (s InventoryService) GetUnitsByCar(ctx context.Context, carID string) ([]models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryService")
+
+	ctx, span := tracer.Start(ctx, "GetUnitsByCar-Service")
+
+	defer span.End()
+
+	return s.store.GetUnitsByCar(ctx, carID)
+}
+
+func (s InventoryService) GetUnitById(ctx context.Context, id string) (models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryService")
+
+	ctx, span := tracer.Start(ctx, "GetUnitByID-Service")
+
+	defer span.End()
+
+	return s.store.GetUnitById(ctx, id)
+}
+
+func (s InventoryService) CreateUnit(ctx context.Context, carID string, unitReq *models.InventoryUnitRequest) (models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryService")
+
+	ctx, span := tracer.Start(ctx, "CreateUnit-Service")
+
+	defer span.End()
+
+	if unitReq.Status == "" {
+		unitReq.Status = models.StockStatusAvailable
+	}
+
+	if err := models.ValidateInventoryUnitRequest(*unitReq); err != nil {
+		return models.InventoryUnit{}, err
+	}
+
+	if err := s.checkVIN(ctx, carID, unitReq.VIN); err != nil {
+		return models.InventoryUnit{}, err
+	}
+
+	return s.store.CreateUnit(ctx, carID, unitReq)
+}
+
+func (s InventoryService) UpdateUnit(ctx context.Context, id string, unitReq *models.InventoryUnitRequest) (models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryService")
+
+	ctx, span := tracer.Start(ctx, "UpdateUnit-Service")
+
+	defer span.End()
+
+	if err := models.ValidateInventoryUnitRequest(*unitReq); err != nil {
+		return models.InventoryUnit{}, err
+	}
+
+	unit, err := s.store.GetUnitById(ctx, id)
+	if err != nil {
+		return models.InventoryUnit{}, err
+	}
+
+	if err := s.checkVIN(ctx, unit.CarID.String(), unitReq.VIN); err != nil {
+		return models.InventoryUnit{}, err
+	}
+
+	return s.store.UpdateUnit(ctx, id, unitReq)
+}
+
+func (s InventoryService) DeleteUnit(ctx context.Context, id string) (models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryService")
+
+	ctx, span := tracer.Start(ctx, "DeleteUnit-Service")
+
+	defer span.End()
+
+	return s.store.DeleteUnit(ctx, id)
+}
+
+func (s InventoryService) GetAvailability(ctx context.Context, carIDs []uuid.UUID) (map[uuid.UUID]models.Availability, error) {
+	tracer := otel.Tracer("InventoryService")
+
+	ctx, span := tracer.Start(ctx, "GetAvailability-Service")
+
+	defer span.End()
+
+	return s.store.GetAvailability(ctx, carIDs)
+}
+
+func (s InventoryService) DecodeVIN(ctx context.Context, vin string) (models.VINInfo, error) {
+	tracer := otel.Tracer("InventoryService")
+
+	_, span := tracer.Start(ctx, "DecodeVIN-Service")
+
+	defer span.End()
+
+	return models.DecodeVIN(vin)
+}
+
+// checkVIN decodes the VIN and rejects units whose manufacturer or model
+// year disagree with the car listing they are registered under.
+func (s InventoryService) checkVIN(ctx context.Context, carID, vin string) error {
+	info, err := models.DecodeVIN(vin)
+	if err != nil {
+		return err
+	}
+
+	car, err := s.cars.GetCarById(ctx, carID)
+	if err != nil {
+		return err
+	}
+	if car.ID == uuid.Nil {
+		return errors.New("Car ID does not exists in the car table")
+	}
+
+	return info.MatchesCar(car.Brand, car.Year)
+}
@@ -0,0 +1,335 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/notify"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	// deliveryBatchSize caps how many deliveries one worker tick sends.
+	deliveryBatchSize = 50
+	// deliveryLease is how long a claimed delivery is hidden from other
+	// workers while it is being sent.
+	deliveryLease = 5 * time.Minute
+
+	deliveryListLimit = 200
+
+	// eventLookback is how far back the scheduler looks for price drops
+	// and lapsed holds. Deduplication makes the overlap between runs
+	// harmless, and a restart within this window misses nothing.
+	eventLookback = 24 * time.Hour
+	// reservationExpiryNotice is how long before a hold runs out the
+	// customer is warned.
+	reservationExpiryNotice = 6 * time.Hour
+	// testDriveReminderLead is how long before a test drive the reminder
+	// goes out.
+	testDriveReminderLead = 24 * time.Hour
+)
+
+type NotificationService struct {
+	store    store.NotificationStoreInterface
+	channels map[models.NotificationChannel]notify.Channel
+}
+
+// NewNotificationService delivers through the given channels. Customers'
+// preferences for channels that are not configured are ignored.
+func NewNotificationService(store store.NotificationStoreInterface, channels ...notify.Channel) NotificationService {
+	byName := make(map[models.NotificationChannel]notify.Channel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+
+	return NotificationService{
+		store:    store,
+		channels: byName,
+	}
+}
+
+// Notify renders the event with its kind's template and queues it on
+// every channel the customer wants. Email is skipped for customers without
+// an address. Raising the same event again queues nothing new.
+func (s NotificationService) Notify(ctx context.Context, event models.NotificationEvent) error {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "Notify-Service")
+
+	defer span.End()
+
+	preferences, err := s.store.GetPreferences(ctx, event.Customer)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := s.store.GetTemplate(ctx, event.Kind)
+	if err != nil {
+		return err
+	}
+
+	subject, body, err := tmpl.Render(event.Data)
+	if err != nil {
+		return fmt.Errorf("rendering %s notification: %w", event.Kind, err)
+	}
+
+	now := time.Now()
+
+	for _, channel := range preferences.ChannelsFor(event.Kind) {
+		if _, ok := s.channels[channel]; !ok {
+			continue
+		}
+
+		recipient := event.Customer
+		if channel == models.NotificationChannelEmail {
+			if preferences.Email == nil {
+				continue
+			}
+			recipient = *preferences.Email
+		}
+
+		delivery := models.NotificationDelivery{
+			ID:            uuid.New(),
+			Customer:      event.Customer,
+			Kind:          event.Kind,
+			Channel:       channel,
+			DedupeKey:     event.DedupeKey,
+			Recipient:     recipient,
+			Subject:       subject,
+			Body:          body,
+			Status:        models.DeliveryStatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+
+		if _, err := s.store.EnqueueDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s NotificationService) GetInbox(ctx context.Context, customer string, unreadOnly bool) ([]models.InboxMessage, error) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "GetInbox-Service")
+
+	defer span.End()
+
+	return s.store.GetInbox(ctx, customer, unreadOnly)
+}
+
+func (s NotificationService) MarkInboxMessageRead(ctx context.Context, id, customer string) (models.InboxMessage, error) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "MarkInboxMessageRead-Service")
+
+	defer span.End()
+
+	return s.store.MarkInboxMessageRead(ctx, id, customer, time.Now())
+}
+
+func (s NotificationService) GetPreferences(ctx context.Context, customer string) (models.NotificationPreferences, error) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "GetPreferences-Service")
+
+	defer span.End()
+
+	return s.store.GetPreferences(ctx, customer)
+}
+
+func (s NotificationService) UpdatePreferences(ctx context.Context, customer string, preferencesReq *models.NotificationPreferencesRequest) (models.NotificationPreferences, error) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "UpdatePreferences-Service")
+
+	defer span.End()
+
+	if preferencesReq.Email != nil {
+		email := strings.TrimSpace(*preferencesReq.Email)
+		if email == "" {
+			preferencesReq.Email = nil
+		} else {
+			preferencesReq.Email = &email
+		}
+	}
+
+	if err := models.ValidateNotificationPreferencesRequest(*preferencesReq); err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	return s.store.UpsertPreferences(ctx, customer, preferencesReq)
+}
+
+func (s NotificationService) GetTemplates(ctx context.Context) ([]models.NotificationTemplate, error) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "GetTemplates-Service")
+
+	defer span.End()
+
+	return s.store.GetTemplates(ctx)
+}
+
+func (s NotificationService) UpsertTemplate(ctx context.Context, kind models.NotificationKind, templateReq *models.NotificationTemplateRequest) (models.NotificationTemplate, error) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "UpsertTemplate-Service")
+
+	defer span.End()
+
+	if err := models.ValidateNotificationTemplateRequest(kind, *templateReq); err != nil {
+		return models.NotificationTemplate{}, err
+	}
+
+	return s.store.UpsertTemplate(ctx, kind, templateReq)
+}
+
+// GetDeliveries lists recent deliveries, all of them when status is empty.
+func (s NotificationService) GetDeliveries(ctx context.Context, status string) ([]models.NotificationDelivery, error) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "GetDeliveries-Service")
+
+	defer span.End()
+
+	var filter *models.DeliveryStatus
+	if status != "" {
+		deliveryStatus := models.DeliveryStatus(status)
+		if !deliveryStatus.IsValid() {
+			return nil, models.ErrInvalidDeliveryStatus
+		}
+		filter = &deliveryStatus
+	}
+
+	return s.store.GetDeliveries(ctx, filter, deliveryListLimit)
+}
+
+// RunDeliveryWorker sends due deliveries every interval, retrying failures
+// with exponential backoff up to models.MaxDeliveryAttempts. It is meant to
+// run in its own goroutine.
+func (s NotificationService) RunDeliveryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.deliverDue(ctx, now)
+		}
+	}
+}
+
+func (s NotificationService) deliverDue(ctx context.Context, now time.Time) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "DeliverDue-Service")
+
+	defer span.End()
+
+	deliveries, err := s.store.ClaimDueDeliveries(ctx, now, deliveryLease, deliveryBatchSize)
+	if err != nil {
+		log.Println("Error claiming notification deliveries: ", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		err := s.send(ctx, delivery)
+		at := time.Now()
+
+		if err == nil {
+			if err := s.store.MarkDeliverySent(ctx, delivery.ID, at); err != nil {
+				log.Printf("Error marking delivery %s sent: %v", delivery.ID, err)
+			}
+			continue
+		}
+
+		var nextAttempt *time.Time
+		if next, ok := models.NextDeliveryAttempt(delivery.Attempts, at); ok {
+			nextAttempt = &next
+			log.Printf("Error sending %s notification %s (attempt %d), retrying at %s: %v", delivery.Channel, delivery.ID, delivery.Attempts, next.Format(time.RFC3339), err)
+		} else {
+			log.Printf("Giving up on %s notification %s after %d attempts: %v", delivery.Channel, delivery.ID, delivery.Attempts, err)
+		}
+
+		if err := s.store.MarkDeliveryFailed(ctx, delivery.ID, err.Error(), nextAttempt, at); err != nil {
+			log.Printf("Error recording failed delivery %s: %v", delivery.ID, err)
+		}
+	}
+}
+
+func (s NotificationService) send(ctx context.Context, delivery models.NotificationDelivery) error {
+	channel, ok := s.channels[delivery.Channel]
+	if !ok {
+		return fmt.Errorf("channel %s is not configured", delivery.Channel)
+	}
+	return channel.Send(ctx, delivery)
+}
+
+// RunScheduler raises notifications every interval for wishlist price
+// drops, holds about to run out or just lapsed, and upcoming test drives.
+// It is meant to run in its own goroutine.
+func (s NotificationService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.raiseEvents(ctx, now)
+		}
+	}
+}
+
+func (s NotificationService) raiseEvents(ctx context.Context, now time.Time) {
+	tracer := otel.Tracer("NotificationService")
+
+	ctx, span := tracer.Start(ctx, "RaiseEvents-Service")
+
+	defer span.End()
+
+	sources := []struct {
+		name  string
+		fetch func() ([]models.NotificationEvent, error)
+	}{
+		{"price drops", func() ([]models.NotificationEvent, error) {
+			return s.store.GetPriceDropEvents(ctx, now.Add(-eventLookback))
+		}},
+		{"expiring reservations", func() ([]models.NotificationEvent, error) {
+			return s.store.GetReservationExpiringEvents(ctx, now, now.Add(reservationExpiryNotice))
+		}},
+		{"expired reservations", func() ([]models.NotificationEvent, error) {
+			return s.store.GetReservationExpiredEvents(ctx, now.Add(-eventLookback))
+		}},
+		{"test drive reminders", func() ([]models.NotificationEvent, error) {
+			return s.store.GetTestDriveReminderEvents(ctx, now, now.Add(testDriveReminderLead))
+		}},
+	}
+
+	for _, source := range sources {
+		events, err := source.fetch()
+		if err != nil {
+			log.Printf("Error finding %s to notify: %v", source.name, err)
+			continue
+		}
+
+		for _, event := range events {
+			if err := s.Notify(ctx, event); err != nil {
+				log.Printf("Error notifying %s of %s: %v", event.Customer, event.DedupeKey, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package order
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+type OrderService struct {
+	store     store.OrderStoreInterface
+	cars      service.CarServiceInterface
+	inventory service.InventoryServiceInterface
+	taxes     service.TaxServiceInterface
+}
+
+func NewOrderService(store store.OrderStoreInterface, cars service.CarServiceInterface, inventory service.InventoryServiceInterface, taxes service.TaxServiceInterface) OrderService {
+	return OrderService{
+		store:     store,
+		cars:      cars,
+		inventory: inventory,
+		taxes:     taxes,
+	}
+}
+
+func (s OrderService) CreateOrder(ctx context.Context, customer string, orderReq *models.OrderRequest) (models.Order, error) {
+	tracer := otel.Tracer("OrderService")
+
+	ctx, span := tracer.Start(ctx, "CreateOrder-Service")
+
+	defer span.End()
+
+	orderReq.Region = models.NormalizeRegionCode(orderReq.Region)
+
+	if err := models.ValidateOrderRequest(*orderReq); err != nil {
+		return models.Order{}, err
+	}
+
+	unit, err := s.inventory.GetUnitById(ctx, orderReq.UnitID.String())
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	car, err := s.cars.GetCarById(ctx, unit.CarID.String())
+	if err != nil {
+		return models.Order{}, err
+	}
+	if car.ID == uuid.Nil {
+		return models.Order{}, models.ErrCarNotFound
+	}
+
+	// The discount is whatever the live promotions take off the car price.
+	discount := models.NewMoney(decimal.Zero, car.Price.Currency)
+	if car.EffectivePrice != nil {
+		discount, err = car.Price.Sub(*car.EffectivePrice)
+		if err != nil {
+			return models.Order{}, err
+		}
+	}
+
+	order := models.Order{
+		ID:        uuid.New(),
+		Customer:  customer,
+		UnitID:    orderReq.UnitID,
+		TaxRegion: orderReq.Region,
+		Price:     car.Price,
+		Status:    models.OrderStatusDraft,
+	}
+
+	order.Discount, order.Tax, order.Total, err = s.priceOrder(ctx, *car, order.TaxRegion, discount)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return s.store.CreateOrder(ctx, order)
+}
+
+// priceOrder works out the discount, tax and total of an order for the car
+// registered in the region.
+func (s OrderService) priceOrder(ctx context.Context, car models.Car, region string, discount models.Money) (models.Money, models.Money, models.Money, error) {
+	taxRegion, err := s.taxes.GetTaxRegion(ctx, region)
+	if err != nil {
+		return models.Money{}, models.Money{}, models.Money{}, err
+	}
+
+	rule, err := s.taxes.FindSCTRule(ctx, car)
+	if err != nil {
+		return models.Money{}, models.Money{}, models.Money{}, err
+	}
+
+	return models.NewOrderAmounts(car.Price, discount, taxRegion, rule)
+}
+
+// GetOrderById only returns orders placed by the customer; other
+// customers' orders look like they do not exist.
+func (s OrderService) GetOrderById(ctx context.Context, id, customer string) (models.Order, error) {
+	tracer := otel.Tracer("OrderService")
+
+	ctx, span := tracer.Start(ctx, "GetOrderByID-Service")
+
+	defer span.End()
+
+	order, err := s.store.GetOrderById(ctx, id)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if order.Customer != customer {
+		return models.Order{}, models.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+func (s OrderService) GetOrdersByCustomer(ctx context.Context, customer string) ([]models.Order, error) {
+	tracer := otel.Tracer("OrderService")
+
+	ctx, span := tracer.Start(ctx, "GetOrdersByCustomer-Service")
+
+	defer span.End()
+
+	return s.store.GetOrdersByCustomer(ctx, customer)
+}
+
+func (s OrderService) GetOrdersByDealership(ctx context.Context, dealershipID string) ([]models.Order, error) {
+	tracer := otel.Tracer("OrderService")
+
+	ctx, span := tracer.Start(ctx, "GetOrdersByDealership-Service")
+
+	defer span.End()
+
+	return s.store.GetOrdersByDealership(ctx, dealershipID)
+}
+
+// UpdateOrderStatus lets staff confirm, deliver or cancel any order and a
+// customer cancel their own. Other customers' orders look like they do not
+// exist.
+func (s OrderService) UpdateOrderStatus(ctx context.Context, id, caller string, staff bool, statusReq *models.OrderStatusRequest) (models.Order, error) {
+	tracer := otel.Tracer("OrderService")
+
+	ctx, span := tracer.Start(ctx, "UpdateOrderStatus-Service")
+
+	defer span.End()
+
+	if !staff {
+		if _, err := s.GetOrderById(ctx, id, caller); err != nil {
+			return models.Order{}, err
+		}
+	}
+
+	if err := models.CanSetOrderStatus(statusReq.Status, staff); err != nil {
+		return models.Order{}, err
+	}
+
+	return s.store.UpdateOrderStatus(ctx, id, statusReq.Status)
+}
+
+// SetOrderDiscount replaces the discount of a draft order with the one
+// staff grant and works out its tax and total again.
+func (s OrderService) SetOrderDiscount(ctx context.Context, id string, discountReq *models.OrderDiscountRequest) (models.Order, error) {
+	tracer := otel.Tracer("OrderService")
+
+	ctx, span := tracer.Start(ctx, "SetOrderDiscount-Service")
+
+	defer span.End()
+
+	order, err := s.store.GetOrderById(ctx, id)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if order.Status != models.OrderStatusDraft {
+		return models.Order{}, fmt.Errorf("%w: the discount of a %s order cannot change", models.ErrInvalidOrderTransition, order.Status)
+	}
+
+	car, err := s.cars.GetCarById(ctx, order.CarID.String())
+	if err != nil {
+		return models.Order{}, err
+	}
+	if car.ID == uuid.Nil {
+		return models.Order{}, models.ErrCarNotFound
+	}
+
+	// Tax is worked out on the price the order was drafted at.
+	car.Price = order.Price
+
+	discount, tax, total, err := s.priceOrder(ctx, *car, order.TaxRegion, models.NewMoney(discountReq.Discount, order.Price.Currency))
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return s.store.UpdateOrderAmounts(ctx, id, discount, tax, total)
+}
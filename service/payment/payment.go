@@ -0,0 +1,302 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/NhutNam2904/carzone/gateway"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+type PaymentService struct {
+	store   store.PaymentStoreInterface
+	orders  store.OrderStoreInterface
+	gateway gateway.PaymentGateway
+}
+
+func NewPaymentService(store store.PaymentStoreInterface, orders store.OrderStoreInterface, gateway gateway.PaymentGateway) PaymentService {
+	return PaymentService{
+		store:   store,
+		orders:  orders,
+		gateway: gateway,
+	}
+}
+
+// CreatePayment authorizes a deposit or the balance of a confirmed order
+// with the gateway. Amounts still held by open payments count as taken.
+// The store repeats the check under a lock on the order, so of two
+// concurrent payments for the same balance one is rejected with
+// models.ErrInvalidPaymentAmount and its intent is cancelled.
+func (s PaymentService) CreatePayment(ctx context.Context, orderID, customer string, paymentReq *models.PaymentRequest) (models.Payment, error) {
+	tracer := otel.Tracer("PaymentService")
+
+	ctx, span := tracer.Start(ctx, "CreatePayment-Service")
+
+	defer span.End()
+
+	if err := models.ValidatePaymentRequest(*paymentReq); err != nil {
+		return models.Payment{}, err
+	}
+
+	order, err := s.orders.GetOrderById(ctx, orderID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if order.Customer != customer {
+		return models.Payment{}, models.ErrOrderNotFound
+	}
+
+	if order.Status != models.OrderStatusConfirmed {
+		return models.Payment{}, models.ErrOrderNotPayable
+	}
+
+	payments, err := s.store.GetPaymentsByOrder(ctx, orderID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	outstanding := order.Balance()
+	for _, payment := range payments {
+		if payment.Status.IsOpen() {
+			outstanding.Amount = outstanding.Amount.Sub(payment.Amount.Amount.Sub(payment.Captured.Amount))
+		}
+	}
+
+	amount, err := models.PaymentAmount(paymentReq.Amount, outstanding)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	payment := models.Payment{
+		ID:       uuid.New(),
+		OrderID:  order.ID,
+		Customer: customer,
+		Kind:     paymentReq.Kind,
+		Amount:   amount,
+		Captured: models.NewMoney(decimal.Zero, amount.Currency),
+		Refunded: models.NewMoney(decimal.Zero, amount.Currency),
+		Status:   models.PaymentStatusPending,
+		Provider: s.gateway.Name(),
+	}
+
+	snapshot, err := s.gateway.CreateIntent(ctx, payment.ID.String(), amount)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	payment.ProviderRef = snapshot.ProviderRef
+	payment.Apply(snapshot)
+
+	now := time.Now()
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+
+	if err := s.store.CreatePayment(ctx, payment); err != nil {
+		// The authorization is held at the provider but was not stored;
+		// release it even if the request has been abandoned.
+		if _, cancelErr := s.gateway.Cancel(context.WithoutCancel(ctx), payment.ProviderRef); cancelErr != nil {
+			log.Printf("Error cancelling intent %s for payment %s: %v", payment.ProviderRef, payment.ID, cancelErr)
+		}
+		return models.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+func (s PaymentService) GetPaymentById(ctx context.Context, id, customer string) (models.Payment, error) {
+	tracer := otel.Tracer("PaymentService")
+
+	ctx, span := tracer.Start(ctx, "GetPaymentByID-Service")
+
+	defer span.End()
+
+	payment, err := s.store.GetPaymentById(ctx, id)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if payment.Customer != customer {
+		return models.Payment{}, models.ErrPaymentNotFound
+	}
+
+	return payment, nil
+}
+
+func (s PaymentService) GetPaymentsByOrder(ctx context.Context, orderID, customer string) ([]models.Payment, error) {
+	tracer := otel.Tracer("PaymentService")
+
+	ctx, span := tracer.Start(ctx, "GetPaymentsByOrder-Service")
+
+	defer span.End()
+
+	order, err := s.orders.GetOrderById(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Customer != customer {
+		return nil, models.ErrOrderNotFound
+	}
+
+	return s.store.GetPaymentsByOrder(ctx, orderID)
+}
+
+// staffPayment loads a payment that only staff may change. Customers'
+// own payments are refused and other customers' payments look like they
+// do not exist, the same as when reading them.
+func (s PaymentService) staffPayment(ctx context.Context, id, caller string, staff bool) (models.Payment, error) {
+	payment, err := s.store.GetPaymentById(ctx, id)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if !staff {
+		if payment.Customer != caller {
+			return models.Payment{}, models.ErrPaymentNotFound
+		}
+		return models.Payment{}, models.ErrPaymentForbidden
+	}
+
+	return payment, nil
+}
+
+// CapturePayment takes the authorized money, all of what is left by
+// default, and is for staff only. The order is marked paid once its total
+// is covered.
+func (s PaymentService) CapturePayment(ctx context.Context, id, caller string, staff bool, amountReq *models.PaymentAmountRequest) (models.Payment, error) {
+	tracer := otel.Tracer("PaymentService")
+
+	ctx, span := tracer.Start(ctx, "CapturePayment-Service")
+
+	defer span.End()
+
+	payment, err := s.staffPayment(ctx, id, caller, staff)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if payment.Status != models.PaymentStatusAuthorized {
+		return models.Payment{}, models.ErrPaymentState
+	}
+
+	uncaptured, err := payment.Amount.Sub(payment.Captured)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	amount, err := models.PaymentAmount(amountReq.Amount, uncaptured)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	snapshot, err := s.gateway.Capture(ctx, payment.ProviderRef, amount)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	return s.store.ApplySnapshot(ctx, payment.ID, snapshot)
+}
+
+// RefundPayment returns captured money, all of it by default, and is for
+// staff only.
+func (s PaymentService) RefundPayment(ctx context.Context, id, caller string, staff bool, amountReq *models.PaymentAmountRequest) (models.Payment, error) {
+	tracer := otel.Tracer("PaymentService")
+
+	ctx, span := tracer.Start(ctx, "RefundPayment-Service")
+
+	defer span.End()
+
+	payment, err := s.staffPayment(ctx, id, caller, staff)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if payment.Status != models.PaymentStatusCaptured {
+		return models.Payment{}, models.ErrPaymentState
+	}
+
+	amount, err := models.PaymentAmount(amountReq.Amount, payment.Net())
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	snapshot, err := s.gateway.Refund(ctx, payment.ProviderRef, amount)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	return s.store.ApplySnapshot(ctx, payment.ID, snapshot)
+}
+
+// HandleWebhook verifies and applies a gateway callback. Redelivered
+// events are accepted without being applied again.
+func (s PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	tracer := otel.Tracer("PaymentService")
+
+	ctx, span := tracer.Start(ctx, "HandleWebhook-Service")
+
+	defer span.End()
+
+	event, err := s.gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.ProcessWebhookEvent(ctx, s.gateway.Name(), event)
+	if errors.Is(err, models.ErrDuplicateWebhookDelivery) {
+		log.Printf("[INFO] Ignoring redelivered payment event %s", event.ID)
+		return nil
+	}
+
+	return err
+}
+
+// RunReconcileWorker asks the gateway every interval about payments that
+// are still open, in case a webhook was lost. It is meant to run in its own
+// goroutine.
+func (s PaymentService) RunReconcileWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.reconcilePayments(ctx, now.Add(-interval))
+		}
+	}
+}
+
+func (s PaymentService) reconcilePayments(ctx context.Context, before time.Time) {
+	tracer := otel.Tracer("PaymentService")
+
+	ctx, span := tracer.Start(ctx, "ReconcilePayments-Service")
+
+	defer span.End()
+
+	payments, err := s.store.GetOpenPayments(ctx, before)
+	if err != nil {
+		log.Println("Error listing open payments: ", err)
+		return
+	}
+
+	for _, payment := range payments {
+		snapshot, err := s.gateway.GetIntent(ctx, payment.ProviderRef)
+		if err != nil {
+			log.Printf("Error fetching payment %s from %s: %v", payment.ID, payment.Provider, err)
+			continue
+		}
+
+		if _, err := s.store.ApplySnapshot(ctx, payment.ID, snapshot); err != nil {
+			log.Printf("Error reconciling payment %s: %v", payment.ID, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package promotion
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type PromotionService struct {
+	store store.PromotionStoreInterface
+}
+
+func NewPromotionService(store store.PromotionStoreInterface) PromotionService {
+	return PromotionService{store: store}
+}
+
+// GetPromotions lists live promotions, or all of them when all is set.
+func (s PromotionService) GetPromotions(ctx context.Context, all bool) ([]models.Promotion, error) {
+	tracer := otel.Tracer("PromotionService")
+
+	ctx, span := tracer.Start(ctx, "GetPromotions-Service")
+
+	defer span.End()
+
+	if all {
+		return s.store.GetPromotions(ctx)
+	}
+	return s.store.GetLivePromotions(ctx, time.Now())
+}
+
+func (s PromotionService) GetLivePromotions(ctx context.Context, at time.Time) ([]models.Promotion, error) {
+	tracer := otel.Tracer("PromotionService")
+
+	ctx, span := tracer.Start(ctx, "GetLivePromotions-Service")
+
+	defer span.End()
+
+	return s.store.GetLivePromotions(ctx, at)
+}
+
+func (s PromotionService) GetPromotionById(ctx context.Context, id string) (models.Promotion, error) {
+	tracer := otel.Tracer("PromotionService")
+
+	ctx, span := tracer.Start(ctx, "GetPromotionByID-Service")
+
+	defer span.End()
+
+	return s.store.GetPromotionById(ctx, id)
+}
+
+func (s PromotionService) CreatePromotion(ctx context.Context, promotionReq *models.PromotionRequest) (models.Promotion, error) {
+	tracer := otel.Tracer("PromotionService")
+
+	ctx, span := tracer.Start(ctx, "CreatePromotion-Service")
+
+	defer span.End()
+
+	normalizePromotionRequest(promotionReq)
+
+	if err := models.ValidatePromotionRequest(*promotionReq); err != nil {
+		return models.Promotion{}, err
+	}
+
+	return s.store.CreatePromotion(ctx, promotionReq)
+}
+
+func (s PromotionService) UpdatePromotion(ctx context.Context, id string, promotionReq *models.PromotionRequest) (models.Promotion, error) {
+	tracer := otel.Tracer("PromotionService")
+
+	ctx, span := tracer.Start(ctx, "UpdatePromotion-Service")
+
+	defer span.End()
+
+	normalizePromotionRequest(promotionReq)
+
+	if err := models.ValidatePromotionRequest(*promotionReq); err != nil {
+		return models.Promotion{}, err
+	}
+
+	return s.store.UpdatePromotion(ctx, id, promotionReq)
+}
+
+func (s PromotionService) DeletePromotion(ctx context.Context, id string) (models.Promotion, error) {
+	tracer := otel.Tracer("PromotionService")
+
+	ctx, span := tracer.Start(ctx, "DeletePromotion-Service")
+
+	defer span.End()
+
+	return s.store.DeletePromotion(ctx, id)
+}
+
+// normalizePromotionRequest upper-cases the currency and drops blank
+// brands so they do not turn into a condition nothing matches.
+func normalizePromotionRequest(promotionReq *models.PromotionRequest) {
+	if promotionReq.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*promotionReq.Currency))
+		promotionReq.Currency = &currency
+	}
+
+	if promotionReq.Brand != nil {
+		brand := strings.TrimSpace(*promotionReq.Brand)
+		promotionReq.Brand = &brand
+		if brand == "" {
+			promotionReq.Brand = nil
+		}
+	}
+}
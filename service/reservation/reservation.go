@@ -0,0 +1,115 @@
+package reservation
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type ReservationService struct {
+	store store.ReservationStoreInterface
+}
+
+func NewReservationService(store store.ReservationStoreInterface) ReservationService {
+	return ReservationService{store: store}
+}
+
+func (s ReservationService) CreateReservation(ctx context.Context, customer string, reservationReq *models.ReservationRequest) (models.Reservation, error) {
+	tracer := otel.Tracer("ReservationService")
+
+	ctx, span := tracer.Start(ctx, "CreateReservation-Service")
+
+	defer span.End()
+
+	if err := models.ValidateReservationRequest(*reservationReq); err != nil {
+		return models.Reservation{}, err
+	}
+
+	holdHours := reservationReq.HoldHours
+	if holdHours == 0 {
+		holdHours = models.DefaultReservationHoldHours
+	}
+
+	expiresAt := time.Now().Add(time.Duration(holdHours) * time.Hour)
+
+	return s.store.CreateReservation(ctx, customer, reservationReq.UnitID, expiresAt)
+}
+
+// GetReservationById only returns reservations owned by the customer; other
+// customers' reservations look like they do not exist.
+func (s ReservationService) GetReservationById(ctx context.Context, id, customer string) (models.Reservation, error) {
+	tracer := otel.Tracer("ReservationService")
+
+	ctx, span := tracer.Start(ctx, "GetReservationByID-Service")
+
+	defer span.End()
+
+	reservation, err := s.store.GetReservationById(ctx, id)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	if reservation.Customer != customer {
+		return models.Reservation{}, models.ErrReservationNotFound
+	}
+
+	return reservation, nil
+}
+
+func (s ReservationService) GetReservationsByCustomer(ctx context.Context, customer string) ([]models.Reservation, error) {
+	tracer := otel.Tracer("ReservationService")
+
+	ctx, span := tracer.Start(ctx, "GetReservationsByCustomer-Service")
+
+	defer span.End()
+
+	return s.store.GetReservationsByCustomer(ctx, customer)
+}
+
+func (s ReservationService) CancelReservation(ctx context.Context, id, customer string) (models.Reservation, error) {
+	tracer := otel.Tracer("ReservationService")
+
+	ctx, span := tracer.Start(ctx, "CancelReservation-Service")
+
+	defer span.End()
+
+	return s.store.CancelReservation(ctx, id, customer)
+}
+
+// RunExpiryWorker releases expired holds every interval until ctx is done.
+// It is meant to run in its own goroutine.
+func (s ReservationService) RunExpiryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.expireReservations(ctx, now)
+		}
+	}
+}
+
+func (s ReservationService) expireReservations(ctx context.Context, now time.Time) {
+	tracer := otel.Tracer("ReservationService")
+
+	ctx, span := tracer.Start(ctx, "ExpireReservations-Service")
+
+	defer span.End()
+
+	expired, err := s.store.ExpireReservations(ctx, now)
+	if err != nil {
+		log.Println("Error expiring reservations: ", err)
+		return
+	}
+
+	if expired > 0 {
+		log.Printf("[INFO] Expired %d reservations", expired)
+	}
+}
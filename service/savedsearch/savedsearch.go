@@ -0,0 +1,232 @@
+package savedsearch
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type SavedSearchService struct {
+	store store.SavedSearchStoreInterface
+	cars  store.CarStoreInterface
+}
+
+func NewSavedSearchService(store store.SavedSearchStoreInterface, cars store.CarStoreInterface) SavedSearchService {
+	return SavedSearchService{
+		store: store,
+		cars:  cars,
+	}
+}
+
+func (s SavedSearchService) GetSavedSearches(ctx context.Context, customer string) ([]models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchService")
+
+	ctx, span := tracer.Start(ctx, "GetSavedSearches-Service")
+
+	defer span.End()
+
+	return s.store.GetSavedSearchesByCustomer(ctx, customer)
+}
+
+func (s SavedSearchService) GetSavedSearchById(ctx context.Context, id, customer string) (models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchService")
+
+	ctx, span := tracer.Start(ctx, "GetSavedSearchByID-Service")
+
+	defer span.End()
+
+	return s.ownedSearch(ctx, id, customer)
+}
+
+func (s SavedSearchService) CreateSavedSearch(ctx context.Context, customer string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchService")
+
+	ctx, span := tracer.Start(ctx, "CreateSavedSearch-Service")
+
+	defer span.End()
+
+	normalizeSavedSearchRequest(searchReq)
+
+	if err := models.ValidateSavedSearchRequest(*searchReq); err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	return s.store.CreateSavedSearch(ctx, customer, searchReq)
+}
+
+func (s SavedSearchService) UpdateSavedSearch(ctx context.Context, id, customer string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchService")
+
+	ctx, span := tracer.Start(ctx, "UpdateSavedSearch-Service")
+
+	defer span.End()
+
+	normalizeSavedSearchRequest(searchReq)
+
+	if err := models.ValidateSavedSearchRequest(*searchReq); err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	if _, err := s.ownedSearch(ctx, id, customer); err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	return s.store.UpdateSavedSearch(ctx, id, searchReq)
+}
+
+func (s SavedSearchService) DeleteSavedSearch(ctx context.Context, id, customer string) (models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchService")
+
+	ctx, span := tracer.Start(ctx, "DeleteSavedSearch-Service")
+
+	defer span.End()
+
+	if _, err := s.ownedSearch(ctx, id, customer); err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	return s.store.DeleteSavedSearch(ctx, id)
+}
+
+// GetMatches lists the cars the evaluator found for the search, with their
+// details.
+func (s SavedSearchService) GetMatches(ctx context.Context, id, customer string) ([]models.SavedSearchMatch, error) {
+	tracer := otel.Tracer("SavedSearchService")
+
+	ctx, span := tracer.Start(ctx, "GetMatches-Service")
+
+	defer span.End()
+
+	if _, err := s.ownedSearch(ctx, id, customer); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.store.GetMatches(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range matches {
+		car, err := s.cars.GetCarById(ctx, matches[i].CarID.String())
+		if err != nil {
+			log.Printf("Error loading matched car %s: %v", matches[i].CarID, err)
+			continue
+		}
+		if car.ID != uuid.Nil {
+			matches[i].Car = car
+		}
+	}
+
+	return matches, nil
+}
+
+// ownedSearch loads the search, hiding other customers' searches as not
+// found.
+func (s SavedSearchService) ownedSearch(ctx context.Context, id, customer string) (models.SavedSearch, error) {
+	search, err := s.store.GetSavedSearchById(ctx, id)
+	if err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	if search.Customer != customer {
+		return models.SavedSearch{}, models.ErrSavedSearchNotFound
+	}
+
+	return search, nil
+}
+
+// RunEvaluator checks saved searches every interval against the cars
+// created or updated since each was last evaluated. It is meant to run in
+// its own goroutine.
+func (s SavedSearchService) RunEvaluator(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.evaluateSearches(ctx, now)
+		}
+	}
+}
+
+// evaluateSearches loads the changed cars once for every search, starting
+// from the oldest cursor. Each search's cursor moves to now, which is taken
+// before the cars are read, so a car changed during a run is seen again in
+// the next one rather than missed.
+func (s SavedSearchService) evaluateSearches(ctx context.Context, now time.Time) {
+	tracer := otel.Tracer("SavedSearchService")
+
+	ctx, span := tracer.Start(ctx, "EvaluateSearches-Service")
+
+	defer span.End()
+
+	searches, err := s.store.GetSavedSearches(ctx)
+	if err != nil {
+		log.Println("Error listing saved searches: ", err)
+		return
+	}
+
+	if len(searches) == 0 {
+		return
+	}
+
+	since := searches[0].LastEvaluatedAt
+	for _, search := range searches {
+		if search.LastEvaluatedAt.Before(since) {
+			since = search.LastEvaluatedAt
+		}
+	}
+
+	cars, err := s.cars.ListCars(ctx, models.CarFilter{UpdatedSince: &since})
+	if err != nil {
+		log.Println("Error listing changed cars: ", err)
+		return
+	}
+
+	for _, search := range searches {
+		matched := []uuid.UUID{}
+		for _, car := range cars {
+			if car.UpdatedAt.After(search.LastEvaluatedAt) && search.Criteria.Matches(car) {
+				matched = append(matched, car.ID)
+			}
+		}
+
+		if err := s.store.RecordEvaluation(ctx, search.ID, matched, now); err != nil {
+			log.Printf("Error recording evaluation of saved search %s: %v", search.ID, err)
+			continue
+		}
+
+		if len(matched) > 0 {
+			log.Printf("[INFO] Saved search %s matched %d cars", search.ID, len(matched))
+		}
+	}
+}
+
+func normalizeSavedSearchRequest(searchReq *models.SavedSearchRequest) {
+	criteria := &searchReq.Criteria
+
+	criteria.Query = strings.Join(strings.Fields(criteria.Query), " ")
+
+	if criteria.Brand != nil {
+		brand := strings.TrimSpace(*criteria.Brand)
+		if brand == "" {
+			criteria.Brand = nil
+		} else {
+			criteria.Brand = &brand
+		}
+	}
+
+	if criteria.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*criteria.Currency))
+		criteria.Currency = &currency
+	}
+}
@@ -0,0 +1,171 @@
+package tax
+
+import (
+	"context"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/NhutNam2904/carzone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type TaxService struct {
+	store store.TaxStoreInterface
+	rates service.ExchangeRateServiceInterface
+}
+
+func NewTaxService(store store.TaxStoreInterface, rates service.ExchangeRateServiceInterface) TaxService {
+	return TaxService{
+		store: store,
+		rates: rates,
+	}
+}
+
+func (s TaxService) GetTaxRegions(ctx context.Context) ([]models.TaxRegion, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "GetTaxRegions-Service")
+
+	defer span.End()
+
+	return s.store.GetTaxRegions(ctx)
+}
+
+func (s TaxService) GetTaxRegion(ctx context.Context, code string) (models.TaxRegion, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "GetTaxRegion-Service")
+
+	defer span.End()
+
+	return s.store.GetTaxRegion(ctx, models.NormalizeRegionCode(code))
+}
+
+func (s TaxService) UpsertTaxRegion(ctx context.Context, code string, regionReq *models.TaxRegionRequest) (models.TaxRegion, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "UpsertTaxRegion-Service")
+
+	defer span.End()
+
+	code = models.NormalizeRegionCode(code)
+
+	if err := models.ValidateTaxRegionRequest(code, *regionReq); err != nil {
+		return models.TaxRegion{}, err
+	}
+
+	return s.store.UpsertTaxRegion(ctx, code, regionReq)
+}
+
+func (s TaxService) DeleteTaxRegion(ctx context.Context, code string) (models.TaxRegion, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "DeleteTaxRegion-Service")
+
+	defer span.End()
+
+	return s.store.DeleteTaxRegion(ctx, models.NormalizeRegionCode(code))
+}
+
+func (s TaxService) GetSCTRules(ctx context.Context) ([]models.SCTRule, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "GetSCTRules-Service")
+
+	defer span.End()
+
+	return s.store.GetSCTRules(ctx)
+}
+
+func (s TaxService) CreateSCTRule(ctx context.Context, ruleReq *models.SCTRuleRequest) (models.SCTRule, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "CreateSCTRule-Service")
+
+	defer span.End()
+
+	if err := models.ValidateSCTRuleRequest(*ruleReq); err != nil {
+		return models.SCTRule{}, err
+	}
+
+	return s.store.CreateSCTRule(ctx, ruleReq)
+}
+
+func (s TaxService) UpdateSCTRule(ctx context.Context, id string, ruleReq *models.SCTRuleRequest) (models.SCTRule, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "UpdateSCTRule-Service")
+
+	defer span.End()
+
+	if err := models.ValidateSCTRuleRequest(*ruleReq); err != nil {
+		return models.SCTRule{}, err
+	}
+
+	return s.store.UpdateSCTRule(ctx, id, ruleReq)
+}
+
+func (s TaxService) DeleteSCTRule(ctx context.Context, id string) (models.SCTRule, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "DeleteSCTRule-Service")
+
+	defer span.End()
+
+	return s.store.DeleteSCTRule(ctx, id)
+}
+
+// FindSCTRule returns the special consumption tax rule for the car's fuel
+// type and engine displacement.
+func (s TaxService) FindSCTRule(ctx context.Context, car models.Car) (models.SCTRule, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "FindSCTRule-Service")
+
+	defer span.End()
+
+	rules, err := s.store.GetSCTRules(ctx)
+	if err != nil {
+		return models.SCTRule{}, err
+	}
+
+	return models.FindSCTRule(rules, car.FuelType, car.Engine.Displacement)
+}
+
+// GetOnRoadPrices works out the car's on-road price in every region. The
+// effective price after promotions is taken as the pre-tax price and
+// converted to models.TaxCurrency first.
+func (s TaxService) GetOnRoadPrices(ctx context.Context, car models.Car) ([]models.OnRoadPrice, error) {
+	tracer := otel.Tracer("TaxService")
+
+	ctx, span := tracer.Start(ctx, "GetOnRoadPrices-Service")
+
+	defer span.End()
+
+	rule, err := s.FindSCTRule(ctx, car)
+	if err != nil {
+		return nil, err
+	}
+
+	regions, err := s.store.GetTaxRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	price := car.Price
+	if car.EffectivePrice != nil {
+		price = *car.EffectivePrice
+	}
+
+	converted, err := s.rates.Convert(ctx, price, models.TaxCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]models.OnRoadPrice, 0, len(regions))
+	for _, region := range regions {
+		prices = append(prices, models.NewOnRoadPrice(converted.Price, region, rule, car.FuelType))
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,197 @@
+package testdrive
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// Opening hours carry no time zone; they are read in the server's local
+// time zone.
+
+type TestDriveService struct {
+	store       store.TestDriveStoreInterface
+	dealerships store.DealershipStoreInterface
+}
+
+func NewTestDriveService(store store.TestDriveStoreInterface, dealerships store.DealershipStoreInterface) TestDriveService {
+	return TestDriveService{
+		store:       store,
+		dealerships: dealerships,
+	}
+}
+
+// GetSlots lists the future slots of the given day at the dealership with
+// the number of units of the car still free in each.
+func (s TestDriveService) GetSlots(ctx context.Context, dealershipID, carID string, date time.Time) ([]models.TestDriveSlot, error) {
+	tracer := otel.Tracer("TestDriveService")
+
+	ctx, span := tracer.Start(ctx, "GetSlots-Service")
+
+	defer span.End()
+
+	if _, err := uuid.Parse(carID); err != nil {
+		return nil, errors.New("Car ID is Required")
+	}
+
+	dealership, err := s.dealerships.GetDealershipById(ctx, dealershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := dealership.OpeningHours.Slots(date.In(time.Local), models.TestDriveSlotDuration)
+	if len(slots) == 0 {
+		return []models.TestDriveSlot{}, nil
+	}
+
+	eligible, err := s.store.CountEligibleUnits(ctx, carID, dealershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	booked, err := s.store.GetActiveTestDrives(ctx, carID, dealershipID, slots[0].StartsAt, slots[len(slots)-1].EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	available := []models.TestDriveSlot{}
+
+	for _, slot := range slots {
+		if !slot.StartsAt.After(now) {
+			continue
+		}
+
+		busy := make(map[uuid.UUID]bool)
+		for _, testDrive := range booked {
+			if testDrive.StartsAt.Before(slot.EndsAt) && testDrive.EndsAt.After(slot.StartsAt) {
+				busy[testDrive.UnitID] = true
+			}
+		}
+
+		slot.AvailableUnits = eligible - len(busy)
+		if slot.AvailableUnits < 0 {
+			slot.AvailableUnits = 0
+		}
+		available = append(available, slot)
+	}
+
+	return available, nil
+}
+
+func (s TestDriveService) CreateTestDrive(ctx context.Context, customer string, testDriveReq *models.TestDriveRequest) (models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveService")
+
+	ctx, span := tracer.Start(ctx, "CreateTestDrive-Service")
+
+	defer span.End()
+
+	if err := models.ValidateTestDriveRequest(*testDriveReq); err != nil {
+		return models.TestDrive{}, err
+	}
+
+	endsAt, err := s.checkSlot(ctx, testDriveReq.DealershipID.String(), testDriveReq.StartsAt)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	return s.store.CreateTestDrive(ctx, customer, testDriveReq, endsAt)
+}
+
+func (s TestDriveService) RescheduleTestDrive(ctx context.Context, id, customer string, rescheduleReq *models.TestDriveRescheduleRequest) (models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveService")
+
+	ctx, span := tracer.Start(ctx, "RescheduleTestDrive-Service")
+
+	defer span.End()
+
+	testDrive, err := s.GetTestDriveById(ctx, id, customer)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	endsAt, err := s.checkSlot(ctx, testDrive.DealershipID.String(), rescheduleReq.StartsAt)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	return s.store.RescheduleTestDrive(ctx, id, customer, rescheduleReq.StartsAt, endsAt)
+}
+
+func (s TestDriveService) CancelTestDrive(ctx context.Context, id, customer string) (models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveService")
+
+	ctx, span := tracer.Start(ctx, "CancelTestDrive-Service")
+
+	defer span.End()
+
+	return s.store.UpdateTestDriveStatus(ctx, id, customer, models.TestDriveStatusCancelled)
+}
+
+// UpdateTestDriveStatus is the dealership side of the workflow (confirm,
+// complete, no-show) and is not limited to the booking's customer, so it
+// is only routed for staff. Customers cancel through CancelTestDrive.
+func (s TestDriveService) UpdateTestDriveStatus(ctx context.Context, id string, statusReq *models.TestDriveStatusRequest) (models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveService")
+
+	ctx, span := tracer.Start(ctx, "UpdateTestDriveStatus-Service")
+
+	defer span.End()
+
+	return s.store.UpdateTestDriveStatus(ctx, id, "", statusReq.Status)
+}
+
+func (s TestDriveService) GetTestDrivesByCustomer(ctx context.Context, customer string) ([]models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveService")
+
+	ctx, span := tracer.Start(ctx, "GetTestDrivesByCustomer-Service")
+
+	defer span.End()
+
+	return s.store.GetTestDrivesByCustomer(ctx, customer)
+}
+
+func (s TestDriveService) GetTestDriveById(ctx context.Context, id, customer string) (models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveService")
+
+	ctx, span := tracer.Start(ctx, "GetTestDriveByID-Service")
+
+	defer span.End()
+
+	testDrive, err := s.store.GetTestDriveById(ctx, id)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	if testDrive.Customer != customer {
+		return models.TestDrive{}, models.ErrTestDriveNotFound
+	}
+
+	return testDrive, nil
+}
+
+// checkSlot makes sure startsAt is in the future and is the start of one of
+// the dealership's slots that day, and returns the slot's end.
+func (s TestDriveService) checkSlot(ctx context.Context, dealershipID string, startsAt time.Time) (time.Time, error) {
+	if !startsAt.After(time.Now()) {
+		return time.Time{}, errors.New("Test drive must start in the future")
+	}
+
+	dealership, err := s.dealerships.GetDealershipById(ctx, dealershipID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, slot := range dealership.OpeningHours.Slots(startsAt.In(time.Local), models.TestDriveSlotDuration) {
+		if slot.StartsAt.Equal(startsAt) {
+			return slot.EndsAt, nil
+		}
+	}
+
+	return time.Time{}, models.ErrOutsideOpeningHours
+}
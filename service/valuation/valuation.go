@@ -0,0 +1,204 @@
+package valuation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/service"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+// ratioPlaces is the precision kept for implied prices and calibration
+// ratios before the final amounts are rounded.
+const ratioPlaces = 16
+
+type ValuationService struct {
+	store store.ValuationStoreInterface
+	rates service.ExchangeRateServiceInterface
+}
+
+func NewValuationService(store store.ValuationStoreInterface, rates service.ExchangeRateServiceInterface) ValuationService {
+	return ValuationService{
+		store: store,
+		rates: rates,
+	}
+}
+
+func (s ValuationService) GetCurves(ctx context.Context) ([]models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationService")
+
+	ctx, span := tracer.Start(ctx, "GetCurves-Service")
+
+	defer span.End()
+
+	return s.store.GetCurves(ctx)
+}
+
+func (s ValuationService) GetCurveById(ctx context.Context, id string) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationService")
+
+	ctx, span := tracer.Start(ctx, "GetCurveByID-Service")
+
+	defer span.End()
+
+	return s.store.GetCurveById(ctx, id)
+}
+
+func (s ValuationService) CreateCurve(ctx context.Context, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationService")
+
+	ctx, span := tracer.Start(ctx, "CreateCurve-Service")
+
+	defer span.End()
+
+	if err := models.ValidateDepreciationCurveRequest(*curveReq); err != nil {
+		return models.DepreciationCurve{}, err
+	}
+
+	return s.store.CreateCurve(ctx, curveReq)
+}
+
+func (s ValuationService) UpdateCurve(ctx context.Context, id string, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationService")
+
+	ctx, span := tracer.Start(ctx, "UpdateCurve-Service")
+
+	defer span.End()
+
+	if err := models.ValidateDepreciationCurveRequest(*curveReq); err != nil {
+		return models.DepreciationCurve{}, err
+	}
+
+	return s.store.UpdateCurve(ctx, id, curveReq)
+}
+
+func (s ValuationService) DeleteCurve(ctx context.Context, id string) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationService")
+
+	ctx, span := tracer.Start(ctx, "DeleteCurve-Service")
+
+	defer span.End()
+
+	return s.store.DeleteCurve(ctx, id)
+}
+
+// ValueTradeIn estimates what we would pay for the customer's car:
+//
+//  1. the base price is the average new price implied by our listings of
+//     the brand and model, each scaled back up its depreciation curve;
+//  2. the base is walked down the curve for the car's age, mileage and
+//     condition;
+//  3. the result is calibrated by how our recent sales compared with the
+//     curve's predictions;
+//  4. the dealer margin is taken off.
+func (s ValuationService) ValueTradeIn(ctx context.Context, tradeInReq *models.TradeInRequest) (models.TradeInValuation, error) {
+	tracer := otel.Tracer("ValuationService")
+
+	ctx, span := tracer.Start(ctx, "ValueTradeIn-Service")
+
+	defer span.End()
+
+	now := time.Now()
+
+	if err := models.ValidateTradeInRequest(*tradeInReq, now); err != nil {
+		return models.TradeInValuation{}, err
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(tradeInReq.Currency))
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	if !models.IsValidCurrency(currency) {
+		return models.TradeInValuation{}, fmt.Errorf("%w: currency %s is not supported", models.ErrInvalidValuation, currency)
+	}
+
+	curve, err := s.store.GetCurveForBrand(ctx, tradeInReq.Brand)
+	if err != nil {
+		return models.TradeInValuation{}, err
+	}
+
+	model := tradeInReq.Model
+
+	listings, err := s.store.GetListingSamples(ctx, tradeInReq.Brand, model)
+	if err != nil {
+		return models.TradeInValuation{}, err
+	}
+
+	// An unlisted model is valued against the whole brand.
+	if len(listings) == 0 && model != "" {
+		model = ""
+		listings, err = s.store.GetListingSamples(ctx, tradeInReq.Brand, model)
+		if err != nil {
+			return models.TradeInValuation{}, err
+		}
+	}
+
+	base, baseSamples := s.basePrice(ctx, curve, listings, currency)
+	if baseSamples == 0 {
+		return models.TradeInValuation{}, models.ErrNoValuationData
+	}
+
+	sales, err := s.store.GetSaleSamples(ctx, tradeInReq.Brand, model, now.Add(-models.CalibrationWindow))
+	if err != nil {
+		return models.TradeInValuation{}, err
+	}
+
+	calibration := s.calibrate(ctx, curve, base, sales)
+
+	return curve.Value(*tradeInReq, base, baseSamples, calibration, now), nil
+}
+
+// basePrice averages the new price implied by each listing. Listings in a
+// currency we have no rate for are left out.
+func (s ValuationService) basePrice(ctx context.Context, curve models.DepreciationCurve, listings []models.ValuationSample, currency string) (models.Money, int) {
+	total := decimal.Zero
+	count := 0
+
+	for _, listing := range listings {
+		converted, err := s.rates.Convert(ctx, listing.Price, currency)
+		if err != nil {
+			continue
+		}
+
+		retention := curve.Retention(models.CarAge(listing.Year, listing.At))
+		total = total.Add(converted.Price.Amount.DivRound(retention, ratioPlaces))
+		count++
+	}
+
+	if count == 0 {
+		return models.Money{}, 0
+	}
+
+	return models.NewMoney(total.DivRound(decimal.NewFromInt(int64(count)), ratioPlaces), currency), count
+}
+
+// calibrate compares each recent sale with what the curve would have
+// predicted for that car in good condition when it sold.
+func (s ValuationService) calibrate(ctx context.Context, curve models.DepreciationCurve, base models.Money, sales []models.ValuationSample) models.Calibration {
+	ratios := make([]decimal.Decimal, 0, len(sales))
+
+	for _, sale := range sales {
+		converted, err := s.rates.Convert(ctx, sale.Price, base.Currency)
+		if err != nil {
+			continue
+		}
+
+		age := models.CarAge(sale.Year, sale.At)
+		predicted := base.Amount.
+			Mul(curve.Retention(age)).
+			Mul(curve.MileageFactor(age, sale.MileageKm)).
+			Mul(curve.ConditionFactor(models.ConditionGood))
+
+		if !predicted.IsPositive() {
+			continue
+		}
+		ratios = append(ratios, converted.Price.Amount.DivRound(predicted, ratioPlaces))
+	}
+
+	return models.NewCalibration(ratios)
+}
@@ -0,0 +1,456 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/NhutNam2904/carzone/gateway"
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	// deliveryBatchSize caps how many deliveries one worker tick sends.
+	deliveryBatchSize = 50
+	// deliveryLease is how long a claimed delivery is hidden from other
+	// workers while it is being sent.
+	deliveryLease = 5 * time.Minute
+	// deliveryTimeout bounds one POST to a receiver.
+	deliveryTimeout = 10 * time.Second
+	// responseBodyLimit is how much of a receiver's answer is kept in the
+	// delivery log.
+	responseBodyLimit = 1024
+
+	// dispatchOverlap is how far before the cursor each scan starts again.
+	// Audit entries are timestamped before their transaction commits, so a
+	// slow commit can surface behind the cursor; deliveries are unique per
+	// event, so rescanning them queues nothing twice.
+	dispatchOverlap = time.Minute
+
+	deliveryListLimit = 200
+
+	secretPrefix = "whsec_"
+)
+
+type WebhookService struct {
+	store    store.WebhookStoreInterface
+	client   *http.Client
+	resolver *net.Resolver
+}
+
+func NewWebhookService(store store.WebhookStoreInterface) WebhookService {
+	return WebhookService{
+		store:    store,
+		client:   newDeliveryClient(),
+		resolver: net.DefaultResolver,
+	}
+}
+
+// newDeliveryClient only connects to public addresses. The check runs on
+// the address actually dialled, so a hostname that resolves elsewhere after
+// the subscription was saved, or a redirect, cannot reach internal
+// services. Proxies are not used, as they would hide the receiver's address.
+func newDeliveryClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !models.IsPublicWebhookIP(net.ParseIP(host)) {
+				return fmt.Errorf("%w: %s", models.ErrWebhookTargetNotAllowed, host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: deliveryTimeout,
+			MaxIdleConns:        deliveryBatchSize,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// checkTarget refuses subscription URLs whose host resolves to an address
+// that is not public.
+func (s WebhookService) checkTarget(ctx context.Context, rawURL string) error {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := endpoint.Hostname()
+	if net.ParseIP(host) != nil {
+		// Literal addresses are checked when the request is validated.
+		return nil
+	}
+
+	addresses, err := s.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: %s does not resolve", models.ErrWebhookTargetNotAllowed, host)
+	}
+	for _, address := range addresses {
+		if !models.IsPublicWebhookIP(address.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", models.ErrWebhookTargetNotAllowed, host, address.IP)
+		}
+	}
+
+	return nil
+}
+
+// withoutSecret hides the signing secret, which is only shown when a
+// subscription is created.
+func withoutSecret(subscription models.WebhookSubscription) models.WebhookSubscription {
+	subscription.Secret = ""
+	return subscription
+}
+
+func (s WebhookService) GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "GetSubscriptions-Service")
+
+	defer span.End()
+
+	subscriptions, err := s.store.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subscriptions {
+		subscriptions[i] = withoutSecret(subscriptions[i])
+	}
+
+	return subscriptions, nil
+}
+
+func (s WebhookService) GetSubscriptionById(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "GetSubscriptionById-Service")
+
+	defer span.End()
+
+	subscription, err := s.store.GetSubscriptionById(ctx, id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return withoutSecret(subscription), nil
+}
+
+// CreateSubscription stores the subscription, generating a secret when the
+// request has none. The response is the only place the secret is shown.
+func (s WebhookService) CreateSubscription(ctx context.Context, subscriptionReq *models.WebhookSubscriptionRequest) (models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "CreateSubscription-Service")
+
+	defer span.End()
+
+	if err := models.ValidateWebhookSubscriptionRequest(*subscriptionReq); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	if err := s.checkTarget(ctx, subscriptionReq.URL); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	var secret string
+	if subscriptionReq.Secret != nil {
+		secret = *subscriptionReq.Secret
+	} else {
+		generated, err := generateSecret()
+		if err != nil {
+			return models.WebhookSubscription{}, err
+		}
+		secret = generated
+	}
+
+	return s.store.CreateSubscription(ctx, subscriptionReq, secret)
+}
+
+func (s WebhookService) UpdateSubscription(ctx context.Context, id string, subscriptionReq *models.WebhookSubscriptionRequest) (models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "UpdateSubscription-Service")
+
+	defer span.End()
+
+	if err := models.ValidateWebhookSubscriptionRequest(*subscriptionReq); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	if err := s.checkTarget(ctx, subscriptionReq.URL); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	subscription, err := s.store.UpdateSubscription(ctx, id, subscriptionReq)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return withoutSecret(subscription), nil
+}
+
+func (s WebhookService) DeleteSubscription(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "DeleteSubscription-Service")
+
+	defer span.End()
+
+	subscription, err := s.store.DeleteSubscription(ctx, id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return withoutSecret(subscription), nil
+}
+
+// GetDeliveries lists a subscription's recent deliveries, all of them when
+// status is empty.
+func (s WebhookService) GetDeliveries(ctx context.Context, subscriptionID string, status string) ([]models.WebhookDelivery, error) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "GetDeliveries-Service")
+
+	defer span.End()
+
+	if _, err := s.store.GetSubscriptionById(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	var filter *models.WebhookDeliveryStatus
+	if status != "" {
+		deliveryStatus := models.WebhookDeliveryStatus(status)
+		if !deliveryStatus.IsValid() {
+			return nil, models.ErrInvalidWebhookStatus
+		}
+		filter = &deliveryStatus
+	}
+
+	return s.store.GetDeliveries(ctx, subscriptionID, filter, deliveryListLimit)
+}
+
+func (s WebhookService) GetDeliveryById(ctx context.Context, id string) (models.WebhookDelivery, error) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "GetDeliveryById-Service")
+
+	defer span.End()
+
+	return s.store.GetDeliveryById(ctx, id)
+}
+
+// Redeliver queues the delivery to be sent on the next worker tick, with
+// the full number of attempts, whether it was delivered or gave up.
+func (s WebhookService) Redeliver(ctx context.Context, id string) (models.WebhookDelivery, error) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "Redeliver-Service")
+
+	defer span.End()
+
+	return s.store.Redeliver(ctx, id, time.Now())
+}
+
+// RunWorker turns new car and engine changes into deliveries and sends due
+// deliveries every interval, retrying failures with exponential backoff up
+// to models.MaxWebhookAttempts. It is meant to run in its own goroutine.
+func (s WebhookService) RunWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatchEvents(ctx, time.Now())
+			s.deliverDue(ctx, time.Now())
+		}
+	}
+}
+
+// dispatchEvents queues a delivery per active subscription for every audit
+// entry written since the cursor. The audit log is written in the same
+// transaction as the change, so no committed change is missed and none is
+// announced that was rolled back.
+func (s WebhookService) dispatchEvents(ctx context.Context, now time.Time) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "DispatchEvents-Service")
+
+	defer span.End()
+
+	cursor, err := s.store.GetCursor(ctx)
+	if err != nil {
+		log.Println("Error reading webhook cursor: ", err)
+		return
+	}
+
+	entries, err := s.store.GetAuditEntries(ctx, models.WebhookEntityTypes, cursor.Add(-dispatchOverlap), now)
+	if err != nil {
+		log.Println("Error reading audit log for webhooks: ", err)
+		return
+	}
+
+	subscriptions, err := s.store.GetActiveSubscriptions(ctx)
+	if err != nil {
+		log.Println("Error fetching webhook subscriptions: ", err)
+		return
+	}
+
+	deliveries := []models.WebhookDelivery{}
+
+	for _, entry := range entries {
+		event, ok := models.WebhookEventFromAudit(entry)
+		if !ok {
+			continue
+		}
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("Error encoding webhook event %s: %v", event.ID, err)
+			continue
+		}
+
+		for _, subscription := range subscriptions {
+			if !subscription.Subscribes(event.Type) {
+				continue
+			}
+
+			deliveries = append(deliveries, models.WebhookDelivery{
+				ID:             uuid.New(),
+				SubscriptionID: subscription.ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Payload:        payload,
+				Status:         models.WebhookDeliveryPending,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			})
+		}
+	}
+
+	if err := s.store.EnqueueDeliveries(ctx, deliveries, now); err != nil {
+		log.Println("Error queueing webhook deliveries: ", err)
+	}
+}
+
+func (s WebhookService) deliverDue(ctx context.Context, now time.Time) {
+	tracer := otel.Tracer("WebhookService")
+
+	ctx, span := tracer.Start(ctx, "DeliverDue-Service")
+
+	defer span.End()
+
+	deliveries, err := s.store.ClaimDueDeliveries(ctx, now, deliveryLease, deliveryBatchSize)
+	if err != nil {
+		log.Println("Error claiming webhook deliveries: ", err)
+		return
+	}
+
+	subscriptions := map[uuid.UUID]models.WebhookSubscription{}
+
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = s.store.GetSubscriptionById(ctx, delivery.SubscriptionID.String())
+			if err != nil {
+				log.Printf("Error fetching subscription of webhook delivery %s: %v", delivery.ID, err)
+				continue
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		attempt := s.send(ctx, subscription, delivery)
+
+		var nextAttempt *time.Time
+		if !attempt.Succeeded() {
+			if next, ok := models.NextWebhookAttempt(delivery.Attempts, attempt.AttemptedAt); ok {
+				nextAttempt = &next
+				log.Printf("Error delivering webhook %s to %s (attempt %d), retrying at %s: %s", delivery.ID, subscription.URL, delivery.Attempts, next.Format(time.RFC3339), attempt.Describe())
+			} else {
+				log.Printf("Giving up on webhook %s to %s after %d attempts: %s", delivery.ID, subscription.URL, delivery.Attempts, attempt.Describe())
+			}
+		}
+
+		if err := s.store.RecordAttempt(ctx, attempt, nextAttempt); err != nil {
+			log.Printf("Error recording webhook attempt for %s: %v", delivery.ID, err)
+		}
+	}
+}
+
+// send POSTs the payload, signed with the subscription's secret, and
+// reports how the receiver answered.
+func (s WebhookService) send(ctx context.Context, subscription models.WebhookSubscription, delivery models.WebhookDelivery) models.WebhookAttempt {
+	started := time.Now()
+
+	attempt := models.WebhookAttempt{
+		ID:          uuid.New(),
+		DeliveryID:  delivery.ID,
+		AttemptedAt: started,
+	}
+
+	fail := func(err error) models.WebhookAttempt {
+		message := err.Error()
+		attempt.Error = &message
+		attempt.DurationMs = time.Since(started).Milliseconds()
+		return attempt
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fail(err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "CarZone-Webhooks/1.0")
+	request.Header.Set(models.WebhookEventHeader, string(delivery.EventType))
+	request.Header.Set(models.WebhookDeliveryHeader, delivery.ID.String())
+	request.Header.Set(models.WebhookSignatureHeader, gateway.Sign(subscription.Secret, delivery.Payload, started))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fail(err)
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, responseBodyLimit))
+
+	statusCode := response.StatusCode
+	attempt.StatusCode = &statusCode
+	attempt.ResponseBody = string(body)
+	attempt.DurationMs = time.Since(started).Milliseconds()
+
+	return attempt
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(buf), nil
+}
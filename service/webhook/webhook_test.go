@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+)
+
+func TestSendRefusesNonPublicAddresses(t *testing.T) {
+	var received atomic.Bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(true)
+	}))
+	defer receiver.Close()
+
+	service := NewWebhookService(nil)
+	subscription := models.WebhookSubscription{ID: uuid.New(), URL: receiver.URL, Secret: "whsec_test"}
+	delivery := models.WebhookDelivery{ID: uuid.New(), EventType: models.WebhookCarCreated, Payload: []byte(`{}`)}
+
+	attempt := service.send(context.Background(), subscription, delivery)
+	if attempt.Succeeded() || attempt.Error == nil || !strings.Contains(*attempt.Error, models.ErrWebhookTargetNotAllowed.Error()) {
+		t.Fatalf("send() to %s = %+v, want a refused connection", receiver.URL, attempt)
+	}
+	if received.Load() {
+		t.Error("receiver on a loopback address was reached")
+	}
+}
+
+func TestCheckTarget(t *testing.T) {
+	service := NewWebhookService(nil)
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"literal address left to validation", "http://127.0.0.1/", false},
+		{"does not resolve", "http://carzone.invalid/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.checkTarget(context.Background(), tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkTarget(%s) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, models.ErrWebhookTargetNotAllowed) {
+				t.Errorf("checkTarget(%s) error = %v, want models.ErrWebhookTargetNotAllowed", tt.url, err)
+			}
+		})
+	}
+}
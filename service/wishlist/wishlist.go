@@ -0,0 +1,85 @@
+package wishlist
+
+import (
+	"context"
+	"log"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type WishlistService struct {
+	store store.WishlistStoreInterface
+	cars  store.CarStoreInterface
+}
+
+func NewWishlistService(store store.WishlistStoreInterface, cars store.CarStoreInterface) WishlistService {
+	return WishlistService{
+		store: store,
+		cars:  cars,
+	}
+}
+
+// GetWishlist lists the customer's favorited cars with their details.
+func (s WishlistService) GetWishlist(ctx context.Context, customer string) ([]models.WishlistItem, error) {
+	tracer := otel.Tracer("WishlistService")
+
+	ctx, span := tracer.Start(ctx, "GetWishlist-Service")
+
+	defer span.End()
+
+	items, err := s.store.GetWishlist(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		car, err := s.cars.GetCarById(ctx, items[i].CarID.String())
+		if err != nil {
+			log.Printf("Error loading wishlisted car %s: %v", items[i].CarID, err)
+			continue
+		}
+		if car.ID != uuid.Nil {
+			items[i].Car = car
+		}
+	}
+
+	return items, nil
+}
+
+func (s WishlistService) AddToWishlist(ctx context.Context, customer, carID string) (models.WishlistItem, error) {
+	tracer := otel.Tracer("WishlistService")
+
+	ctx, span := tracer.Start(ctx, "AddToWishlist-Service")
+
+	defer span.End()
+
+	car, err := s.cars.GetCarById(ctx, carID)
+	if err != nil {
+		return models.WishlistItem{}, err
+	}
+
+	if car.ID == uuid.Nil {
+		return models.WishlistItem{}, models.ErrCarNotFound
+	}
+
+	item, err := s.store.AddToWishlist(ctx, customer, carID)
+	if err != nil {
+		return models.WishlistItem{}, err
+	}
+
+	item.Car = car
+	return item, nil
+}
+
+func (s WishlistService) RemoveFromWishlist(ctx context.Context, customer, carID string) error {
+	tracer := otel.Tracer("WishlistService")
+
+	ctx, span := tracer.Start(ctx, "RemoveFromWishlist-Service")
+
+	defer span.End()
+
+	return s.store.RemoveFromWishlist(ctx, customer, carID)
+}
@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// anonymousActor is recorded when a mutation arrives without a token.
+const anonymousActor = "anonymous"
+
+type AuditStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) AuditStore {
+	return AuditStore{db: db}
+}
+
+// Record writes an audit entry inside the caller's transaction, so the entry
+// is committed or rolled back together with the change it describes. Pass nil
+// for before on create and for after on delete.
+func Record(ctx context.Context, tx *sql.Tx, actor models.AuditActor, action, entityType, entityID string, before, after interface{}) error {
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return err
+	}
+
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+
+	diff, err := json.Marshal(diffSnapshots(beforeJSON, afterJSON))
+	if err != nil {
+		return err
+	}
+
+	username := actor.Username
+	if username == "" {
+		username = anonymousActor
+	}
+
+	query := `INSERT INTO audit_log (id, actor, action, entity_type, entity_id, before, after, diff, request_id, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = tx.ExecContext(ctx, query,
+		uuid.New(),
+		username,
+		action,
+		entityType,
+		entityID,
+		nullableJSON(beforeJSON),
+		nullableJSON(afterJSON),
+		string(diff),
+		actor.RequestID,
+		time.Now(),
+	)
+
+	return err
+}
+
+func snapshot(value interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+func nullableJSON(data json.RawMessage) interface{} {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// diffSnapshots compares two JSON objects field by field. Nested objects are
+// compared as a whole, so a changed engine shows up as one "engine" entry.
+func diffSnapshots(before, after json.RawMessage) map[string]models.AuditChange {
+	beforeFields := map[string]interface{}{}
+	afterFields := map[string]interface{}{}
+
+	if before != nil {
+		_ = json.Unmarshal(before, &beforeFields)
+	}
+	if after != nil {
+		_ = json.Unmarshal(after, &afterFields)
+	}
+
+	diff := map[string]models.AuditChange{}
+
+	for field, from := range beforeFields {
+		to, ok := afterFields[field]
+		if !ok || !reflect.DeepEqual(from, to) {
+			diff[field] = models.AuditChange{From: from, To: to}
+		}
+	}
+	for field, to := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			diff[field] = models.AuditChange{From: nil, To: to}
+		}
+	}
+
+	return diff
+}
+
+func (s AuditStore) GetEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	tracer := otel.Tracer("AuditStore")
+
+	ctx, span := tracer.Start(ctx, "GetEntries-Store")
+
+	defer span.End()
+
+	var conditions []string
+	var args []interface{}
+
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+arg(filter.Actor))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+arg(filter.Action))
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type = "+arg(filter.EntityType))
+	}
+	if filter.EntityID != "" {
+		conditions = append(conditions, "entity_id = "+arg(filter.EntityID))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(filter.To))
+	}
+
+	query := `SELECT id, actor, action, entity_type, entity_id, before, after, diff, request_id, created_at FROM audit_log`
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY created_at DESC LIMIT " + arg(filter.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after, diff []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Actor,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&before,
+			&after,
+			&diff,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if before != nil {
+			entry.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			entry.After = json.RawMessage(after)
+		}
+		if err := json.Unmarshal(diff, &entry.Diff); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,475 @@
+package brand
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store/audit"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	auditEntityBrand = "brand"
+	// auditEntityCar matches the car store's entity type, so renamed cars
+	// show up in the car's history and raise car.updated webhooks.
+	auditEntityCar = "car"
+)
+
+// uniqueViolation is the Postgres error code raised by idx_brand_name_key.
+const uniqueViolation = "23505"
+
+// brandNameError turns a duplicate brand name into ErrBrandNameTaken.
+func brandNameError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "idx_brand_name_key" {
+		return models.ErrBrandNameTaken
+	}
+	return err
+}
+
+type BrandStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) BrandStore {
+	return BrandStore{db: db}
+}
+
+func (b BrandStore) GetBrands(ctx context.Context) ([]models.Brand, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "GetBrands-Store")
+
+	defer span.End()
+
+	rows, err := b.db.QueryContext(ctx, "SELECT id, name, country_of_origin, logo_url, created_at, updated_at FROM brand ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	brands := []models.Brand{}
+
+	for rows.Next() {
+		var brand models.Brand
+		err := rows.Scan(
+			&brand.ID,
+			&brand.Name,
+			&brand.CountryOfOrigin,
+			&brand.LogoURL,
+			&brand.CreatedAt,
+			&brand.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		brands = append(brands, brand)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return brands, nil
+}
+
+func (b BrandStore) GetBrandById(ctx context.Context, id string) (models.Brand, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "GetBrandByID-Store")
+
+	defer span.End()
+
+	var brand models.Brand
+
+	err := b.db.QueryRowContext(ctx, "SELECT id, name, country_of_origin, logo_url, created_at, updated_at FROM brand WHERE id = $1", id).Scan(
+		&brand.ID,
+		&brand.Name,
+		&brand.CountryOfOrigin,
+		&brand.LogoURL,
+		&brand.CreatedAt,
+		&brand.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Brand{}, errors.New("Brand ID not found")
+		}
+		return models.Brand{}, err
+	}
+
+	return brand, nil
+}
+
+func (b BrandStore) CreateBrand(ctx context.Context, brandReq *models.BrandRequest) (models.Brand, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "CreateBrand-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	brand := models.Brand{
+		ID:              uuid.New(),
+		Name:            models.NormalizeBrandName(brandReq.Name),
+		CountryOfOrigin: brandReq.CountryOfOrigin,
+		LogoURL:         brandReq.LogoURL,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	query := `INSERT INTO brand (id, name, country_of_origin, logo_url, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := b.db.ExecContext(ctx, query,
+		brand.ID,
+		brand.Name,
+		brand.CountryOfOrigin,
+		brand.LogoURL,
+		brand.CreatedAt,
+		brand.UpdatedAt,
+	)
+
+	if err != nil {
+		return models.Brand{}, brandNameError(err)
+	}
+
+	return brand, nil
+}
+
+// UpdateBrand also rewrites the denormalized brand name on car so listings
+// and the brand cache keep matching the catalog. Renamed cars get a new
+// updated_at and an audit entry each, so updatedSince readers and webhooks
+// see the change.
+func (b BrandStore) UpdateBrand(ctx context.Context, actor models.AuditActor, id string, brandReq *models.BrandRequest) (brand models.Brand, err error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateBrand-Store")
+
+	defer span.End()
+
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Brand{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var oldBrand models.Brand
+
+	err = tx.QueryRowContext(ctx, "SELECT id, name, country_of_origin, logo_url, created_at, updated_at FROM brand WHERE id = $1 FOR UPDATE", id).Scan(
+		&oldBrand.ID,
+		&oldBrand.Name,
+		&oldBrand.CountryOfOrigin,
+		&oldBrand.LogoURL,
+		&oldBrand.CreatedAt,
+		&oldBrand.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Brand{}, errors.New("Brand ID not found")
+		}
+		return models.Brand{}, err
+	}
+
+	query := `UPDATE brand SET name = $2, country_of_origin = $3, logo_url = $4, updated_at = $5 WHERE id = $1
+	          RETURNING id, name, country_of_origin, logo_url, created_at, updated_at`
+
+	err = tx.QueryRowContext(ctx, query,
+		id,
+		models.NormalizeBrandName(brandReq.Name),
+		brandReq.CountryOfOrigin,
+		brandReq.LogoURL,
+		time.Now(),
+	).Scan(
+		&brand.ID,
+		&brand.Name,
+		&brand.CountryOfOrigin,
+		&brand.LogoURL,
+		&brand.CreatedAt,
+		&brand.UpdatedAt,
+	)
+
+	if err != nil {
+		return models.Brand{}, brandNameError(err)
+	}
+
+	err = audit.Record(ctx, tx, actor, models.AuditActionUpdate, auditEntityBrand, id, oldBrand, brand)
+	if err != nil {
+		return models.Brand{}, err
+	}
+
+	if brand.Name != oldBrand.Name {
+		err = renameBrandOnCars(ctx, tx, actor, id, brand.Name, brand.UpdatedAt)
+		if err != nil {
+			return models.Brand{}, err
+		}
+	}
+
+	return brand, nil
+}
+
+// renameBrandOnCars sets the brand name on the brand's cars and audits each
+// car as updated.
+func renameBrandOnCars(ctx context.Context, tx *sql.Tx, actor models.AuditActor, brandID, name string, at time.Time) error {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, name, year, brand, brand_id, model_id, dealership_id, fuel_type, engine_id, price, currency, created_at, updated_at FROM car WHERE brand_id = $1 FOR UPDATE", brandID)
+	if err != nil {
+		return err
+	}
+
+	var cars []models.Car
+
+	for rows.Next() {
+		var car models.Car
+		err := rows.Scan(
+			&car.ID,
+			&car.Name,
+			&car.Year,
+			&car.Brand,
+			&car.BrandID,
+			&car.ModelID,
+			&car.DealershipID,
+			&car.FuelType,
+			&car.Engine.EngineID,
+			&car.Price.Amount,
+			&car.Price.Currency,
+			&car.CreatedAt,
+			&car.UpdatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		cars = append(cars, car)
+	}
+
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, car := range cars {
+		renamed := car
+		renamed.Brand = name
+		renamed.UpdatedAt = at
+
+		if _, err := tx.ExecContext(ctx, "UPDATE car SET brand = $2, updated_at = $3 WHERE id = $1", car.ID, name, at); err != nil {
+			return err
+		}
+
+		if err := audit.Record(ctx, tx, actor, models.AuditActionUpdate, auditEntityCar, car.ID.String(), car, renamed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b BrandStore) DeleteBrand(ctx context.Context, id string) (models.Brand, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteBrand-Store")
+
+	defer span.End()
+
+	var brand models.Brand
+
+	// Cars reference brand without ON DELETE CASCADE, so deleting a brand
+	// that is still in use fails with a foreign key violation.
+	query := `DELETE FROM brand WHERE id = $1
+	          RETURNING id, name, country_of_origin, logo_url, created_at, updated_at`
+
+	err := b.db.QueryRowContext(ctx, query, id).Scan(
+		&brand.ID,
+		&brand.Name,
+		&brand.CountryOfOrigin,
+		&brand.LogoURL,
+		&brand.CreatedAt,
+		&brand.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Brand{}, errors.New("Brand ID not found")
+		}
+		return models.Brand{}, err
+	}
+
+	return brand, nil
+}
+
+func (b BrandStore) GetModelsByBrand(ctx context.Context, brandID string) ([]models.CarModel, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "GetModelsByBrand-Store")
+
+	defer span.End()
+
+	rows, err := b.db.QueryContext(ctx, "SELECT id, brand_id, name, created_at, updated_at FROM model WHERE brand_id = $1 ORDER BY name", brandID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carModels := []models.CarModel{}
+
+	for rows.Next() {
+		var carModel models.CarModel
+		err := rows.Scan(
+			&carModel.ID,
+			&carModel.BrandID,
+			&carModel.Name,
+			&carModel.CreatedAt,
+			&carModel.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		carModels = append(carModels, carModel)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return carModels, nil
+}
+
+func (b BrandStore) GetModelById(ctx context.Context, id string) (models.CarModel, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "GetModelByID-Store")
+
+	defer span.End()
+
+	var carModel models.CarModel
+
+	err := b.db.QueryRowContext(ctx, "SELECT id, brand_id, name, created_at, updated_at FROM model WHERE id = $1", id).Scan(
+		&carModel.ID,
+		&carModel.BrandID,
+		&carModel.Name,
+		&carModel.CreatedAt,
+		&carModel.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.CarModel{}, errors.New("Model ID not found")
+		}
+		return models.CarModel{}, err
+	}
+
+	return carModel, nil
+}
+
+func (b BrandStore) CreateModel(ctx context.Context, brandID string, modelReq *models.CarModelRequest) (models.CarModel, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "CreateModel-Store")
+
+	defer span.End()
+
+	var carModel models.CarModel
+
+	now := time.Now()
+
+	query := `INSERT INTO model (id, brand_id, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $4)
+	          RETURNING id, brand_id, name, created_at, updated_at`
+
+	err := b.db.QueryRowContext(ctx, query,
+		uuid.New(),
+		brandID,
+		models.NormalizeBrandName(modelReq.Name),
+		now,
+	).Scan(
+		&carModel.ID,
+		&carModel.BrandID,
+		&carModel.Name,
+		&carModel.CreatedAt,
+		&carModel.UpdatedAt,
+	)
+
+	if err != nil {
+		return models.CarModel{}, err
+	}
+
+	return carModel, nil
+}
+
+func (b BrandStore) UpdateModel(ctx context.Context, id string, modelReq *models.CarModelRequest) (models.CarModel, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateModel-Store")
+
+	defer span.End()
+
+	var carModel models.CarModel
+
+	query := `UPDATE model SET name = $2, updated_at = $3 WHERE id = $1
+	          RETURNING id, brand_id, name, created_at, updated_at`
+
+	err := b.db.QueryRowContext(ctx, query,
+		id,
+		models.NormalizeBrandName(modelReq.Name),
+		time.Now(),
+	).Scan(
+		&carModel.ID,
+		&carModel.BrandID,
+		&carModel.Name,
+		&carModel.CreatedAt,
+		&carModel.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.CarModel{}, errors.New("Model ID not found")
+		}
+		return models.CarModel{}, err
+	}
+
+	return carModel, nil
+}
+
+func (b BrandStore) DeleteModel(ctx context.Context, id string) (models.CarModel, error) {
+	tracer := otel.Tracer("BrandStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteModel-Store")
+
+	defer span.End()
+
+	var carModel models.CarModel
+
+	query := `DELETE FROM model WHERE id = $1
+	          RETURNING id, brand_id, name, created_at, updated_at`
+
+	err := b.db.QueryRowContext(ctx, query, id).Scan(
+		&carModel.ID,
+		&carModel.BrandID,
+		&carModel.Name,
+		&carModel.CreatedAt,
+		&carModel.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.CarModel{}, errors.New("Model ID not found")
+		}
+		return models.CarModel{}, err
+	}
+
+	return carModel, nil
+}
@@ -7,14 +7,19 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store/audit"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.opentelemetry.io/otel"
 )
 
+const auditEntityCar = "car"
+
 type Store struct {
 	db          *sql.DB
 	redisClient *redis.Client
@@ -34,7 +39,7 @@ func (s Store) GetCarById(ctx context.Context, id string) (*models.Car, error) {
 
 	var car models.Car
 
-	query := `SELECT c.id, c.name,c.year,c.brand, c.fuel_type, c.engine_id, c.price, c.created_at, c.updated_at, e.id, e.displacement, e.no_of_cylinders, e.car_range FROM car c JOIN 
+	query := `SELECT c.id, c.name,c.year,c.brand, c.brand_id, c.model_id, c.dealership_id, c.fuel_type, c.engine_id, c.price, c.currency, c.created_at, c.updated_at, e.id, e.displacement, e.no_of_cylinders, e.car_range, e.powertrain, e.battery_kwh, e.motor_kw, e.charging_standards, e.wltp_range_km, e.power_kw, e.torque_nm, e.transmission, e.co2_g_per_km FROM car c JOIN 
 	engine e ON c.engine_id = e.id WHERE c.id = $1`
 
 	row := s.db.QueryRowContext(ctx, query, id)
@@ -44,15 +49,28 @@ func (s Store) GetCarById(ctx context.Context, id string) (*models.Car, error) {
 		&car.Name,
 		&car.Year,
 		&car.Brand,
+		&car.BrandID,
+		&car.ModelID,
+		&car.DealershipID,
 		&car.FuelType,
 		&car.Engine.EngineID,
-		&car.Price,
+		&car.Price.Amount,
+		&car.Price.Currency,
 		&car.CreatedAt,
 		&car.UpdatedAt,
 		&car.Engine.EngineID,
 		&car.Engine.Displacement,
 		&car.Engine.NoOfCyclinders,
-		&car.Engine.CarRange)
+		&car.Engine.CarRange,
+		&car.Engine.Powertrain,
+		&car.Engine.BatteryKWh,
+		&car.Engine.MotorKW,
+		pq.Array(&car.Engine.ChargingStandards),
+		&car.Engine.WLTPRangeKm,
+		&car.Engine.PowerKW,
+		&car.Engine.TorqueNm,
+		&car.Engine.Transmission,
+		&car.Engine.CO2GPerKm)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -65,6 +83,24 @@ func (s Store) GetCarById(ctx context.Context, id string) (*models.Car, error) {
 
 }
 
+// brandCacheKey is where GetCarByBrand caches the cars of a brand.
+func brandCacheKey(brand string) string {
+	return fmt.Sprintf("Brand:%s", strings.ToLower(models.NormalizeBrandName(brand)))
+}
+
+// InvalidateBrandCache drops the cached car lists of the brands, e.g. after
+// a brand is renamed.
+func (s Store) InvalidateBrandCache(ctx context.Context, brands ...string) error {
+	keys := make([]string, 0, len(brands))
+	for _, brand := range brands {
+		keys = append(keys, brandCacheKey(brand))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.redisClient.Del(ctx, keys...).Err()
+}
+
 func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error) {
 
 	tracer := otel.Tracer("CarStore")
@@ -75,7 +111,9 @@ func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) (
 	var cars []models.Car
 	var query string
 
-	key := fmt.Sprintf("Brand:%s", brand)
+	brand = models.NormalizeBrandName(brand)
+
+	key := brandCacheKey(brand)
 	cachedData, err := s.redisClient.Get(ctx, key).Result()
 
 	if err == nil {
@@ -90,14 +128,16 @@ func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) (
 	log.Println("Cache miss, querying database")
 
 	if isEngine {
-		query = `SELECT c.id, c.name, c.year, c.brand, c.fuel_type, c.engine_id, c.price, c.created_at, c.updated_at, e.id, e.displacement, e.no_of_cylinders, e.car_range 
+		query = `SELECT c.id, c.name, c.year, c.brand, c.brand_id, c.model_id, c.dealership_id, c.fuel_type, c.engine_id, c.price, c.currency, c.created_at, c.updated_at, e.id, e.displacement, e.no_of_cylinders, e.car_range,
+				e.powertrain, e.battery_kwh, e.motor_kw, e.charging_standards, e.wltp_range_km,
+				e.power_kw, e.torque_nm, e.transmission, e.co2_g_per_km
 				FROM car c 
 				JOIN engine e ON c.engine_id = e.id 
-				WHERE c.brand = $1`
+				WHERE lower(c.brand) = lower($1)`
 	} else {
-		query = `SELECT c.id, c.name, c.year, c.brand, c.fuel_type, c.price, c.created_at, c.updated_at 
+		query = `SELECT c.id, c.name, c.year, c.brand, c.brand_id, c.model_id, c.dealership_id, c.fuel_type, c.price, c.currency, c.created_at, c.updated_at 
 				FROM car c 
-				WHERE c.brand = $1`
+				WHERE lower(c.brand) = lower($1)`
 	}
 
 	rows, err := s.db.QueryContext(ctx, query, brand)
@@ -115,15 +155,28 @@ func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) (
 				&car.Name,
 				&car.Year,
 				&car.Brand,
+				&car.BrandID,
+				&car.ModelID,
+				&car.DealershipID,
 				&car.FuelType,
 				&car.Engine.EngineID,
-				&car.Price,
+				&car.Price.Amount,
+				&car.Price.Currency,
 				&car.CreatedAt,
 				&car.UpdatedAt,
 				&engine.EngineID,
 				&engine.Displacement,
 				&engine.NoOfCyclinders,
 				&engine.CarRange,
+				&engine.Powertrain,
+				&engine.BatteryKWh,
+				&engine.MotorKW,
+				pq.Array(&engine.ChargingStandards),
+				&engine.WLTPRangeKm,
+				&engine.PowerKW,
+				&engine.TorqueNm,
+				&engine.Transmission,
+				&engine.CO2GPerKm,
 			)
 			if err != nil {
 				return nil, err
@@ -136,8 +189,12 @@ func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) (
 				&car.Name,
 				&car.Year,
 				&car.Brand,
+				&car.BrandID,
+				&car.ModelID,
+				&car.DealershipID,
 				&car.FuelType,
-				&car.Price,
+				&car.Price.Amount,
+				&car.Price.Currency,
 				&car.CreatedAt,
 				&car.UpdatedAt,
 			)
@@ -167,7 +224,9 @@ func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) (
 	return cars, nil
 }
 
-func (s Store) CreateCar(ctx context.Context, carReq *models.CarRequest) (models.Car, error) {
+// CreateCar inserts the car. Named results let the deferred commit report
+// its error, and the suggest index is only touched once the car is stored.
+func (s Store) CreateCar(ctx context.Context, actor models.AuditActor, carReq *models.CarRequest) (createdCar models.Car, err error) {
 
 	tracer := otel.Tracer("CarStore")
 
@@ -175,18 +234,47 @@ func (s Store) CreateCar(ctx context.Context, carReq *models.CarRequest) (models
 
 	defer span.End()
 
-	var createdCar models.Car
+	tx, err := s.db.BeginTx(ctx, nil)
 
-	var engineID uuid.UUID
+	if err != nil {
+		return createdCar, err
+	}
 
-	err := s.db.QueryRowContext(ctx, "SELECT id FROM engine WHERE id=$1", carReq.Engine.EngineID).Scan(&engineID)
+	var modelName string
 
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return createdCar, errors.New("Engine ID does not exists in the engine table")
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			return
 		}
+		s.indexCarSuggestions(ctx, "", "", createdCar.Brand, modelName)
+	}()
+
+	err = checkEngine(ctx, tx, carReq.Engine.EngineID, carReq.FuelType)
+
+	if err != nil {
+		return createdCar, err
+	}
+
+	brandID, modelID, brandName, err := resolveCatalog(ctx, tx, carReq)
+
+	if err != nil {
+		return createdCar, err
+	}
+
+	modelName, err = lookupModelName(ctx, tx, modelID)
+
+	if err != nil {
 		return createdCar, err
+	}
 
+	err = checkDealership(ctx, tx, carReq.DealershipID)
+
+	if err != nil {
+		return createdCar, err
 	}
 
 	carID := uuid.New()
@@ -195,51 +283,50 @@ func (s Store) CreateCar(ctx context.Context, carReq *models.CarRequest) (models
 	updatedAt := createdAt
 
 	newCar := models.Car{
-		ID:        carID,
-		Name:      carReq.Name,
-		Year:      carReq.Year,
-		Brand:     carReq.Brand,
-		FuelType:  carReq.FuelType,
+		ID:       carID,
+		Name:     carReq.Name,
+		Year:     carReq.Year,
+		Brand:    brandName,
+		BrandID:  brandID,
+		ModelID:  modelID,
+		FuelType: carReq.FuelType,
+
+		DealershipID: carReq.DealershipID,
+
 		Engine:    carReq.Engine,
 		Price:     carReq.Price,
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
-
-	if err != nil {
-		return createdCar, err
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-			return
-		}
-		err = tx.Commit()
-	}()
-
-	query := `INSERT INTO car(id, name, year,brand,fuel_type, engine_id, price, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	          RETURNING id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at`
+	query := `INSERT INTO car(id, name, year,brand, brand_id, model_id, dealership_id, fuel_type, engine_id, price, currency, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	          RETURNING id, name, year, brand, brand_id, model_id, dealership_id, fuel_type, engine_id, price, currency, created_at, updated_at`
 
 	err = tx.QueryRowContext(ctx, query,
 		newCar.ID,
 		newCar.Name,
 		newCar.Year,
 		newCar.Brand,
+		newCar.BrandID,
+		newCar.ModelID,
+		newCar.DealershipID,
 		newCar.FuelType,
 		newCar.Engine.EngineID,
-		newCar.Price,
+		newCar.Price.Amount,
+		newCar.Price.Currency,
 		newCar.CreatedAt,
 		newCar.UpdatedAt,
 	).Scan(&createdCar.ID,
 		&createdCar.Name,
 		&createdCar.Year,
 		&createdCar.Brand,
+		&createdCar.BrandID,
+		&createdCar.ModelID,
+		&createdCar.DealershipID,
 		&createdCar.FuelType,
 		&createdCar.Engine.EngineID,
-		&createdCar.Price,
+		&createdCar.Price.Amount,
+		&createdCar.Price.Currency,
 		&createdCar.CreatedAt,
 		&createdCar.UpdatedAt,
 	)
@@ -247,41 +334,54 @@ func (s Store) CreateCar(ctx context.Context, carReq *models.CarRequest) (models
 		return createdCar, err
 	}
 
+	err = audit.Record(ctx, tx, actor, models.AuditActionCreate, auditEntityCar, createdCar.ID.String(), nil, auditSnapshot(createdCar))
+	if err != nil {
+		return createdCar, err
+	}
+
 	return createdCar, nil
 
 }
 
-func (s Store) DeleteCar(ctx context.Context, id string) (models.Car, error) {
+func (s Store) DeleteCar(ctx context.Context, actor models.AuditActor, id string) (deleteCar models.Car, err error) {
 	tracer := otel.Tracer("CarStore")
 
 	ctx, span := tracer.Start(ctx, "DeleteCar-Store")
 
 	defer span.End()
 
-	var deleteCar models.Car
-
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return deleteCar, err
 	}
+
+	var modelName string
+
 	defer func() {
 		if err != nil {
 			tx.Rollback()
 			return
 		}
-		err = tx.Commit()
+		if err = tx.Commit(); err != nil {
+			return
+		}
+		s.indexCarSuggestions(ctx, deleteCar.Brand, modelName, "", "")
 	}()
 
-	err = s.db.QueryRowContext(ctx,
-		"SELECT id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at FROM car WHERE id = $1", id).
+	err = tx.QueryRowContext(ctx,
+		"SELECT id, name, year, brand, brand_id, model_id, dealership_id, fuel_type, engine_id, price, currency, created_at, updated_at FROM car WHERE id = $1 FOR UPDATE", id).
 		Scan(
 			&deleteCar.ID,
 			&deleteCar.Name,
 			&deleteCar.Year,
 			&deleteCar.Brand,
+			&deleteCar.BrandID,
+			&deleteCar.ModelID,
+			&deleteCar.DealershipID,
 			&deleteCar.FuelType,
 			&deleteCar.Engine.EngineID, // Truyền vào trường Engine.EngineID
-			&deleteCar.Price,
+			&deleteCar.Price.Amount,
+			&deleteCar.Price.Currency,
 			&deleteCar.CreatedAt,
 			&deleteCar.UpdatedAt,
 		)
@@ -293,6 +393,11 @@ func (s Store) DeleteCar(ctx context.Context, id string) (models.Car, error) {
 		return models.Car{}, err
 	}
 
+	modelName, err = lookupModelName(ctx, tx, deleteCar.ModelID)
+	if err != nil {
+		return models.Car{}, err
+	}
+
 	result, err := tx.ExecContext(ctx, "DELETE FROM car WHERE id =$1", id)
 
 	if err != nil {
@@ -308,31 +413,87 @@ func (s Store) DeleteCar(ctx context.Context, id string) (models.Car, error) {
 		return models.Car{}, errors.New("No rows were affected")
 	}
 
+	err = audit.Record(ctx, tx, actor, models.AuditActionDelete, auditEntityCar, deleteCar.ID.String(), auditSnapshot(deleteCar), nil)
+	if err != nil {
+		return models.Car{}, err
+	}
+
 	return deleteCar, nil
 
 }
 
-func (s Store) UpdateCar(ctx context.Context, id string, carReq *models.CarRequest) (models.Car, error) {
+func (s Store) UpdateCar(ctx context.Context, actor models.AuditActor, id string, carReq *models.CarRequest) (updatedCar models.Car, err error) {
 	tracer := otel.Tracer("CarStore")
 
 	ctx, span := tracer.Start(ctx, "UpdateCar-Store")
 
 	defer span.End()
 
-	var updatedCar models.Car
-
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return updatedCar, err
 	}
+
+	var oldCar models.Car
+	var oldModelName, newModelName string
+
 	defer func() {
 		if err != nil {
 			tx.Rollback()
 			return
 		}
-		err = tx.Commit()
+		if err = tx.Commit(); err != nil {
+			return
+		}
+		s.indexCarSuggestions(ctx, oldCar.Brand, oldModelName, updatedCar.Brand, newModelName)
 	}()
 
+	err = tx.QueryRowContext(ctx,
+		"SELECT id, name, year, brand, brand_id, model_id, dealership_id, fuel_type, engine_id, price, currency, created_at, updated_at FROM car WHERE id = $1 FOR UPDATE", id).
+		Scan(
+			&oldCar.ID,
+			&oldCar.Name,
+			&oldCar.Year,
+			&oldCar.Brand,
+			&oldCar.BrandID,
+			&oldCar.ModelID,
+			&oldCar.DealershipID,
+			&oldCar.FuelType,
+			&oldCar.Engine.EngineID,
+			&oldCar.Price.Amount,
+			&oldCar.Price.Currency,
+			&oldCar.CreatedAt,
+			&oldCar.UpdatedAt,
+		)
+	if err != nil {
+		return updatedCar, err
+	}
+
+	err = checkEngine(ctx, tx, carReq.Engine.EngineID, carReq.FuelType)
+	if err != nil {
+		return updatedCar, err
+	}
+
+	brandID, modelID, brandName, err := resolveCatalog(ctx, tx, carReq)
+	if err != nil {
+		return updatedCar, err
+	}
+
+	oldModelName, err = lookupModelName(ctx, tx, oldCar.ModelID)
+	if err != nil {
+		return updatedCar, err
+	}
+
+	newModelName, err = lookupModelName(ctx, tx, modelID)
+	if err != nil {
+		return updatedCar, err
+	}
+
+	err = checkDealership(ctx, tx, carReq.DealershipID)
+	if err != nil {
+		return updatedCar, err
+	}
+
 	query := `
 	WITH updated_car AS (
     UPDATE car
@@ -342,23 +503,40 @@ func (s Store) UpdateCar(ctx context.Context, id string, carReq *models.CarReque
         fuel_type = $5, 
         engine_id = $6, 
         price = $7, 
-        updated_at = $8
+        currency = $11,
+        updated_at = $8,
+        brand_id = $9,
+        model_id = $10,
+        dealership_id = $12
     WHERE id = $1
-RETURNING id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at
+RETURNING id, name, year, brand, brand_id, model_id, dealership_id, fuel_type, engine_id, price, currency, created_at, updated_at
 )
 SELECT 
     updated_car.id, 
 	updated_car.name, 
     updated_car.year, 
     updated_car.brand, 
+    updated_car.brand_id, 
+    updated_car.model_id, 
+    updated_car.dealership_id, 
     updated_car.fuel_type, 
     updated_car.engine_id, 
     updated_car.price, 
+    updated_car.currency, 
     updated_car.created_at, 
     updated_car.updated_at,
     engine.displacement, 
     engine.no_of_cylinders, 
-    engine.car_range
+    engine.car_range,
+    engine.powertrain,
+    engine.battery_kwh,
+    engine.motor_kw,
+    engine.charging_standards,
+    engine.wltp_range_km,
+    engine.power_kw,
+    engine.torque_nm,
+    engine.transmission,
+    engine.co2_g_per_km
 FROM 
     updated_car
 JOIN 
@@ -369,28 +547,194 @@ JOIN
 		id,
 		carReq.Name,
 		carReq.Year,
-		carReq.Brand,
+		brandName,
 		carReq.FuelType,
 		carReq.Engine.EngineID,
-		carReq.Price,
+		carReq.Price.Amount,
 		time.Now(),
+		brandID,
+		modelID,
+		carReq.Price.Currency,
+		carReq.DealershipID,
 	).Scan(&updatedCar.ID,
 		&updatedCar.Name,
 		&updatedCar.Year,
 		&updatedCar.Brand,
+		&updatedCar.BrandID,
+		&updatedCar.ModelID,
+		&updatedCar.DealershipID,
 		&updatedCar.FuelType,
 		&updatedCar.Engine.EngineID,
-		&updatedCar.Price,
+		&updatedCar.Price.Amount,
+		&updatedCar.Price.Currency,
 		&updatedCar.CreatedAt,
 		&updatedCar.UpdatedAt,
 		&updatedCar.Engine.Displacement,
 		&updatedCar.Engine.NoOfCyclinders,
 		&updatedCar.Engine.CarRange,
+		&updatedCar.Engine.Powertrain,
+		&updatedCar.Engine.BatteryKWh,
+		&updatedCar.Engine.MotorKW,
+		pq.Array(&updatedCar.Engine.ChargingStandards),
+		&updatedCar.Engine.WLTPRangeKm,
+		&updatedCar.Engine.PowerKW,
+		&updatedCar.Engine.TorqueNm,
+		&updatedCar.Engine.Transmission,
+		&updatedCar.Engine.CO2GPerKm,
 	)
 	if err != nil {
 		return updatedCar, err
 	}
 
+	if !oldCar.Price.Amount.Equal(updatedCar.Price.Amount) || oldCar.Price.Currency != updatedCar.Price.Currency {
+		_, err = tx.ExecContext(ctx, `INSERT INTO car_price_history (id, car_id, old_price, old_currency, new_price, new_currency, changed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			uuid.New(),
+			updatedCar.ID,
+			oldCar.Price.Amount,
+			oldCar.Price.Currency,
+			updatedCar.Price.Amount,
+			updatedCar.Price.Currency,
+			updatedCar.UpdatedAt,
+		)
+		if err != nil {
+			return updatedCar, err
+		}
+	}
+
+	err = audit.Record(ctx, tx, actor, models.AuditActionUpdate, auditEntityCar, id, auditSnapshot(oldCar), auditSnapshot(updatedCar))
+	if err != nil {
+		return updatedCar, err
+	}
+
 	return updatedCar, nil
 
 }
+
+func (s Store) SearchCars(ctx context.Context, q string, limit int) ([]models.CarSearchResult, error) {
+	tracer := otel.Tracer("CarStore")
+
+	ctx, span := tracer.Start(ctx, "SearchCars-Store")
+
+	defer span.End()
+
+	var results []models.CarSearchResult
+
+	// The to_tsvector expression must match idx_car_search in schema.sql so the
+	// planner can use the GIN index; word_similarity (<%) adds typo tolerance.
+	query := `SELECT c.id, c.name, c.year, c.brand, c.brand_id, c.model_id, c.dealership_id, c.fuel_type, c.engine_id, c.price, c.currency, c.created_at, c.updated_at,
+				e.id, e.displacement, e.no_of_cylinders, e.car_range,
+				e.powertrain, e.battery_kwh, e.motor_kw, e.charging_standards, e.wltp_range_km,
+				e.power_kw, e.torque_nm, e.transmission, e.co2_g_per_km,
+				ts_rank(to_tsvector('simple', c.name || ' ' || c.brand || ' ' || c.fuel_type), websearch_to_tsquery('simple', $1))
+				+ GREATEST(word_similarity($1, c.name), word_similarity($1, c.brand), word_similarity($1, c.fuel_type)) AS rank
+			FROM car c
+			JOIN engine e ON c.engine_id = e.id
+			WHERE to_tsvector('simple', c.name || ' ' || c.brand || ' ' || c.fuel_type) @@ websearch_to_tsquery('simple', $1)
+				OR $1 <% c.name
+				OR $1 <% c.brand
+				OR $1 <% c.fuel_type
+			ORDER BY rank DESC, c.name
+			LIMIT $2`
+
+	rows, err := s.db.QueryContext(ctx, query, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.CarSearchResult
+		err := rows.Scan(
+			&result.ID,
+			&result.Name,
+			&result.Year,
+			&result.Brand,
+			&result.BrandID,
+			&result.ModelID,
+			&result.DealershipID,
+			&result.FuelType,
+			&result.Engine.EngineID,
+			&result.Price.Amount,
+			&result.Price.Currency,
+			&result.CreatedAt,
+			&result.UpdatedAt,
+			&result.Engine.EngineID,
+			&result.Engine.Displacement,
+			&result.Engine.NoOfCyclinders,
+			&result.Engine.CarRange,
+			&result.Engine.Powertrain,
+			&result.Engine.BatteryKWh,
+			&result.Engine.MotorKW,
+			pq.Array(&result.Engine.ChargingStandards),
+			&result.Engine.WLTPRangeKm,
+			&result.Engine.PowerKW,
+			&result.Engine.TorqueNm,
+			&result.Engine.Transmission,
+			&result.Engine.CO2GPerKm,
+			&result.Rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s Store) GetPriceHistory(ctx context.Context, carID string) ([]models.PriceChange, error) {
+	tracer := otel.Tracer("CarStore")
+
+	ctx, span := tracer.Start(ctx, "GetPriceHistory-Store")
+
+	defer span.End()
+
+	query := `SELECT id, car_id, old_price, old_currency, new_price, new_currency, changed_at
+			FROM car_price_history
+			WHERE car_id = $1
+			ORDER BY changed_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.PriceChange{}
+
+	for rows.Next() {
+		var change models.PriceChange
+		err := rows.Scan(
+			&change.ID,
+			&change.CarID,
+			&change.OldPrice.Amount,
+			&change.OldPrice.Currency,
+			&change.NewPrice.Amount,
+			&change.NewPrice.Currency,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// auditSnapshot reduces a car to its own columns for the audit log. The
+// engine is referenced by ID only; engine changes are audited separately.
+func auditSnapshot(car models.Car) models.Car {
+	car.Engine = models.Engine{EngineID: car.Engine.EngineID}
+	car.DisplayPrice = nil
+	return car
+}
@@ -0,0 +1,128 @@
+package car
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// resolveCatalog maps the brand and model on a car request to rows in the
+// brand and model tables. Explicit IDs must exist; free-text names are
+// normalized and upserted so differently spelled brands share one row.
+func resolveCatalog(ctx context.Context, tx *sql.Tx, carReq *models.CarRequest) (uuid.UUID, uuid.UUID, string, error) {
+	var brandID uuid.UUID
+	var brandName string
+
+	if carReq.BrandID != nil {
+		err := tx.QueryRowContext(ctx, "SELECT id, name FROM brand WHERE id = $1", *carReq.BrandID).Scan(&brandID, &brandName)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return uuid.Nil, uuid.Nil, "", errors.New("Brand ID does not exists in the brand table")
+			}
+			return uuid.Nil, uuid.Nil, "", err
+		}
+	} else {
+		now := time.Now()
+		err := tx.QueryRowContext(ctx, `INSERT INTO brand (id, name, created_at, updated_at) VALUES ($1, $2, $3, $3)
+			ON CONFLICT ((lower(name))) DO UPDATE SET name = brand.name
+			RETURNING id, name`,
+			uuid.New(), models.NormalizeBrandName(carReq.Brand), now).Scan(&brandID, &brandName)
+		if err != nil {
+			return uuid.Nil, uuid.Nil, "", err
+		}
+	}
+
+	var modelID uuid.UUID
+
+	if carReq.ModelID != nil {
+		err := tx.QueryRowContext(ctx, "SELECT id FROM model WHERE id = $1 AND brand_id = $2", *carReq.ModelID, brandID).Scan(&modelID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return uuid.Nil, uuid.Nil, "", errors.New("Model ID does not exists for this brand")
+			}
+			return uuid.Nil, uuid.Nil, "", err
+		}
+	} else {
+		now := time.Now()
+		err := tx.QueryRowContext(ctx, `INSERT INTO model (id, brand_id, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT (brand_id, (lower(name))) DO UPDATE SET name = model.name
+			RETURNING id`,
+			uuid.New(), brandID, models.ModelNameFromCarName(carReq.Name, brandName), now).Scan(&modelID)
+		if err != nil {
+			return uuid.Nil, uuid.Nil, "", err
+		}
+	}
+
+	return brandID, modelID, brandName, nil
+}
+
+// checkDealership verifies that an optional dealership assignment points
+// at an existing dealership.
+func checkDealership(ctx context.Context, tx *sql.Tx, dealershipID *uuid.UUID) error {
+	if dealershipID == nil {
+		return nil
+	}
+
+	var id uuid.UUID
+	err := tx.QueryRowContext(ctx, "SELECT id FROM dealership WHERE id = $1", *dealershipID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Dealership ID does not exists in the dealership table")
+		}
+		return err
+	}
+	return nil
+}
+
+// lookupModelName returns the catalog name of a car's model, which is what
+// model suggestions are indexed by. Cars from before the catalog have no
+// model and get "".
+func lookupModelName(ctx context.Context, tx *sql.Tx, modelID uuid.UUID) (string, error) {
+	if modelID == uuid.Nil {
+		return "", nil
+	}
+
+	var name string
+	err := tx.QueryRowContext(ctx, "SELECT name FROM model WHERE id = $1", modelID).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return name, err
+}
+
+// checkEngine loads the stored engine the car points at and checks it suits
+// the car's fuel type. The row is locked FOR SHARE so a concurrent engine
+// update waits for this transaction and then re-checks the car.
+func checkEngine(ctx context.Context, tx *sql.Tx, engineID uuid.UUID, fuelType models.FuelType) error {
+	var engine models.Engine
+
+	err := tx.QueryRowContext(ctx, `SELECT id, displacement, no_of_cylinders, car_range, powertrain, battery_kwh, motor_kw, charging_standards, wltp_range_km, power_kw, torque_nm, transmission, co2_g_per_km
+		FROM engine WHERE id = $1 FOR SHARE`, engineID).Scan(
+		&engine.EngineID,
+		&engine.Displacement,
+		&engine.NoOfCyclinders,
+		&engine.CarRange,
+		&engine.Powertrain,
+		&engine.BatteryKWh,
+		&engine.MotorKW,
+		pq.Array(&engine.ChargingStandards),
+		&engine.WLTPRangeKm,
+		&engine.PowerKW,
+		&engine.TorqueNm,
+		&engine.Transmission,
+		&engine.CO2GPerKm,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Engine ID does not exists in the engine table")
+		}
+		return err
+	}
+
+	return models.ValidateCarEngine(engine, fuelType)
+}
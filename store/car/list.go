@@ -0,0 +1,144 @@
+package car
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+// haversineKm is the great-circle distance in kilometres between a
+// dealership and the point given by the two placeholders. It needs no
+// PostGIS or earthdistance extension. LEAST guards asin against rounding
+// just above 1 for antipodal points.
+const haversineKm = `(2 * 6371 * asin(LEAST(1, sqrt(
+				power(sin(radians(d.latitude - %[1]s) / 2), 2)
+				+ cos(radians(%[1]s)) * cos(radians(d.latitude)) * power(sin(radians(d.longitude - %[2]s) / 2), 2)))))`
+
+// ListCars serves the car listing when filters beyond brand are used.
+// Unlike GetCarByBrand its results are not cached, since the combinations
+// of filters are open-ended.
+func (s Store) ListCars(ctx context.Context, filter models.CarFilter) ([]models.Car, error) {
+	tracer := otel.Tracer("CarStore")
+
+	ctx, span := tracer.Start(ctx, "ListCars-Store")
+
+	defer span.End()
+
+	var conditions []string
+	var args []interface{}
+
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Brand != "" {
+		conditions = append(conditions, "lower(c.brand) = lower("+arg(models.NormalizeBrandName(filter.Brand))+")")
+	}
+
+	if filter.DealershipID != "" {
+		conditions = append(conditions, "c.dealership_id = "+arg(filter.DealershipID))
+	}
+
+	distance := "NULL::double precision"
+	join := ""
+
+	if filter.Near != nil {
+		distance = fmt.Sprintf(haversineKm, arg(filter.Near.Latitude), arg(filter.Near.Longitude))
+		join = "\n\t\t\tJOIN dealership d ON c.dealership_id = d.id"
+		conditions = append(conditions, distance+" <= "+arg(filter.RadiusKm))
+	}
+
+	if filter.ReducedWithinDays > 0 {
+		since := time.Now().AddDate(0, 0, -filter.ReducedWithinDays)
+		conditions = append(conditions, `EXISTS (
+				SELECT 1 FROM car_price_history h
+				WHERE h.car_id = c.id
+					AND h.old_currency = h.new_currency
+					AND h.new_price < h.old_price
+					AND h.changed_at >= `+arg(since)+`)`)
+	}
+
+	if filter.UpdatedSince != nil {
+		conditions = append(conditions, "c.updated_at > "+arg(*filter.UpdatedSince))
+	}
+
+	query := `SELECT c.id, c.name, c.year, c.brand, c.brand_id, c.model_id, c.dealership_id, c.fuel_type, c.engine_id, c.price, c.currency, c.created_at, c.updated_at,
+				e.id, e.displacement, e.no_of_cylinders, e.car_range,
+				e.powertrain, e.battery_kwh, e.motor_kw, e.charging_standards, e.wltp_range_km,
+				e.power_kw, e.torque_nm, e.transmission, e.co2_g_per_km,
+				` + distance + ` AS distance_km
+			FROM car c
+			JOIN engine e ON c.engine_id = e.id` + join
+
+	if len(conditions) > 0 {
+		query += "\n\t\t\tWHERE " + strings.Join(conditions, "\n\t\t\t\tAND ")
+	}
+
+	if filter.Near != nil {
+		query += "\n\t\t\tORDER BY distance_km, c.name"
+	} else {
+		query += "\n\t\t\tORDER BY c.name"
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cars := []models.Car{}
+
+	for rows.Next() {
+		var car models.Car
+		var engine models.Engine
+		err := rows.Scan(
+			&car.ID,
+			&car.Name,
+			&car.Year,
+			&car.Brand,
+			&car.BrandID,
+			&car.ModelID,
+			&car.DealershipID,
+			&car.FuelType,
+			&car.Engine.EngineID,
+			&car.Price.Amount,
+			&car.Price.Currency,
+			&car.CreatedAt,
+			&car.UpdatedAt,
+			&engine.EngineID,
+			&engine.Displacement,
+			&engine.NoOfCyclinders,
+			&engine.CarRange,
+			&engine.Powertrain,
+			&engine.BatteryKWh,
+			&engine.MotorKW,
+			pq.Array(&engine.ChargingStandards),
+			&engine.WLTPRangeKm,
+			&engine.PowerKW,
+			&engine.TorqueNm,
+			&engine.Transmission,
+			&engine.CO2GPerKm,
+			&car.DistanceKm,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.IsEngine {
+			car.Engine = engine
+		}
+		cars = append(cars, car)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cars, nil
+}
@@ -0,0 +1,210 @@
+package car
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+)
+
+// Each suggestion kind is kept in two sorted sets: a count set scored by the
+// number of cars, and a lex set (all scores 0) whose members are
+// "<lowercase name>\x00<display name>" so ZRANGEBYLEX can do prefix lookups.
+const (
+	suggestBrandCountKey = "suggest:brand:count"
+	suggestBrandLexKey   = "suggest:brand:lex"
+	suggestModelCountKey = "suggest:model:count"
+	suggestModelLexKey   = "suggest:model:lex"
+
+	// suggestPageSize is how many prefix matches are read from the lex set
+	// per round trip. All matches are ranked, not just the first page.
+	suggestPageSize = 500
+)
+
+var suggestIncrScript = redis.NewScript(`
+local score = tonumber(redis.call('ZINCRBY', KEYS[1], ARGV[1], ARGV[2]))
+if score <= 0 then
+	redis.call('ZREM', KEYS[1], ARGV[2])
+	redis.call('ZREM', KEYS[2], ARGV[3])
+else
+	redis.call('ZADD', KEYS[2], 0, ARGV[3])
+end
+return score
+`)
+
+func suggestLexMember(name string) string {
+	return strings.ToLower(name) + "\x00" + name
+}
+
+func (s Store) incrSuggestion(ctx context.Context, countKey, lexKey, name string, delta int64) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+
+	err := suggestIncrScript.Run(ctx, s.redisClient, []string{countKey, lexKey}, delta, name, suggestLexMember(name)).Err()
+	if err != nil {
+		log.Println("Failed to update suggest index: ", err)
+	}
+}
+
+// indexCarSuggestions moves a car's brand and catalog model counts from the
+// old values to the new ones. Pass empty strings for old on create and for
+// new on delete. Callers run it only after their transaction commits.
+func (s Store) indexCarSuggestions(ctx context.Context, oldBrand, oldModel, newBrand, newModel string) {
+	if oldBrand != newBrand {
+		s.incrSuggestion(ctx, suggestBrandCountKey, suggestBrandLexKey, oldBrand, -1)
+		s.incrSuggestion(ctx, suggestBrandCountKey, suggestBrandLexKey, newBrand, 1)
+	}
+	if oldModel != newModel {
+		s.incrSuggestion(ctx, suggestModelCountKey, suggestModelLexKey, oldModel, -1)
+		s.incrSuggestion(ctx, suggestModelCountKey, suggestModelLexKey, newModel, 1)
+	}
+}
+
+func (s Store) Suggest(ctx context.Context, prefix string, limit int) (models.Suggestions, error) {
+	tracer := otel.Tracer("CarStore")
+
+	ctx, span := tracer.Start(ctx, "Suggest-Store")
+
+	defer span.End()
+
+	brands, err := s.suggestFrom(ctx, suggestBrandCountKey, suggestBrandLexKey, prefix, limit)
+	if err != nil {
+		return models.Suggestions{}, err
+	}
+
+	carModels, err := s.suggestFrom(ctx, suggestModelCountKey, suggestModelLexKey, prefix, limit)
+	if err != nil {
+		return models.Suggestions{}, err
+	}
+
+	return models.Suggestions{Brands: brands, Models: carModels}, nil
+}
+
+// suggestFrom ranks every name starting with prefix by its car count. The
+// lex set only orders names alphabetically, so all matches are read page by
+// page and scored before the top limit are kept.
+func (s Store) suggestFrom(ctx context.Context, countKey, lexKey, prefix string, limit int) ([]models.Suggestion, error) {
+	p := strings.ToLower(prefix)
+
+	suggestions := []models.Suggestion{}
+
+	for offset := int64(0); ; offset += suggestPageSize {
+		members, err := s.redisClient.ZRangeByLex(ctx, lexKey, &redis.ZRangeBy{
+			Min:    "[" + p,
+			Max:    "[" + p + "\xff",
+			Offset: offset,
+			Count:  suggestPageSize,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(members) == 0 {
+			break
+		}
+
+		pipe := s.redisClient.Pipeline()
+		scores := make([]*redis.FloatCmd, len(members))
+		names := make([]string, len(members))
+		for i, member := range members {
+			names[i] = member[strings.IndexByte(member, 0)+1:]
+			scores[i] = pipe.ZScore(ctx, countKey, names[i])
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return nil, err
+		}
+
+		for i, name := range names {
+			count, err := scores[i].Result()
+			if err != nil || count <= 0 {
+				continue
+			}
+			suggestions = append(suggestions, models.Suggestion{Name: name, Count: int64(count)})
+		}
+
+		if len(members) < suggestPageSize {
+			break
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
+
+// RebuildSuggestIndex recomputes the brand and model indexes from the car
+// table, counting models by their catalog name. Each set is built under a temporary key and renamed into place so
+// readers never see a half-built index.
+func (s Store) RebuildSuggestIndex(ctx context.Context) error {
+	tracer := otel.Tracer("CarStore")
+
+	ctx, span := tracer.Start(ctx, "RebuildSuggestIndex-Store")
+
+	defer span.End()
+
+	if err := s.rebuildSuggestSet(ctx, `SELECT brand, COUNT(*) FROM car GROUP BY brand`,
+		suggestBrandCountKey, suggestBrandLexKey); err != nil {
+		return err
+	}
+
+	return s.rebuildSuggestSet(ctx, `SELECT m.name, COUNT(*) FROM car c JOIN model m ON m.id = c.model_id GROUP BY m.name`,
+		suggestModelCountKey, suggestModelLexKey)
+}
+
+func (s Store) rebuildSuggestSet(ctx context.Context, query, countKey, lexKey string) error {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{}
+	for rows.Next() {
+		var name string
+		var count int64
+		if err := rows.Scan(&name, &count); err != nil {
+			return err
+		}
+		name = strings.TrimSpace(name)
+		if name != "" {
+			counts[name] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tmpCountKey := countKey + ":rebuild"
+	tmpLexKey := lexKey + ":rebuild"
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, tmpCountKey, tmpLexKey)
+	for name, count := range counts {
+		pipe.ZAdd(ctx, tmpCountKey, &redis.Z{Score: float64(count), Member: name})
+		pipe.ZAdd(ctx, tmpLexKey, &redis.Z{Score: 0, Member: suggestLexMember(name)})
+	}
+	if len(counts) == 0 {
+		pipe.Del(ctx, countKey, lexKey)
+	} else {
+		pipe.Rename(ctx, tmpCountKey, countKey)
+		pipe.Rename(ctx, tmpLexKey, lexKey)
+	}
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
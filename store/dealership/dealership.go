@@ -0,0 +1,192 @@
+package dealership
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const dealershipColumns = "id, name, address, latitude, longitude, opening_hours, phone, email, created_at, updated_at"
+
+type DealershipStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) DealershipStore {
+	return DealershipStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDealership(row rowScanner) (models.Dealership, error) {
+	var dealership models.Dealership
+	err := row.Scan(
+		&dealership.ID,
+		&dealership.Name,
+		&dealership.Address,
+		&dealership.Latitude,
+		&dealership.Longitude,
+		&dealership.OpeningHours,
+		&dealership.Phone,
+		&dealership.Email,
+		&dealership.CreatedAt,
+		&dealership.UpdatedAt,
+	)
+	return dealership, err
+}
+
+func (d DealershipStore) GetDealerships(ctx context.Context) ([]models.Dealership, error) {
+	tracer := otel.Tracer("DealershipStore")
+
+	ctx, span := tracer.Start(ctx, "GetDealerships-Store")
+
+	defer span.End()
+
+	rows, err := d.db.QueryContext(ctx, "SELECT "+dealershipColumns+" FROM dealership ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dealerships := []models.Dealership{}
+
+	for rows.Next() {
+		dealership, err := scanDealership(rows)
+		if err != nil {
+			return nil, err
+		}
+		dealerships = append(dealerships, dealership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dealerships, nil
+}
+
+func (d DealershipStore) GetDealershipById(ctx context.Context, id string) (models.Dealership, error) {
+	tracer := otel.Tracer("DealershipStore")
+
+	ctx, span := tracer.Start(ctx, "GetDealershipByID-Store")
+
+	defer span.End()
+
+	dealership, err := scanDealership(d.db.QueryRowContext(ctx, "SELECT "+dealershipColumns+" FROM dealership WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Dealership{}, errors.New("Dealership ID not found")
+		}
+		return models.Dealership{}, err
+	}
+
+	return dealership, nil
+}
+
+func (d DealershipStore) CreateDealership(ctx context.Context, dealershipReq *models.DealershipRequest) (models.Dealership, error) {
+	tracer := otel.Tracer("DealershipStore")
+
+	ctx, span := tracer.Start(ctx, "CreateDealership-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	dealership := models.Dealership{
+		ID:           uuid.New(),
+		Name:         strings.TrimSpace(dealershipReq.Name),
+		Address:      strings.TrimSpace(dealershipReq.Address),
+		Latitude:     dealershipReq.Latitude,
+		Longitude:    dealershipReq.Longitude,
+		OpeningHours: models.NormalizeOpeningHours(dealershipReq.OpeningHours),
+		Phone:        dealershipReq.Phone,
+		Email:        dealershipReq.Email,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	query := `INSERT INTO dealership (` + dealershipColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := d.db.ExecContext(ctx, query,
+		dealership.ID,
+		dealership.Name,
+		dealership.Address,
+		dealership.Latitude,
+		dealership.Longitude,
+		dealership.OpeningHours,
+		dealership.Phone,
+		dealership.Email,
+		dealership.CreatedAt,
+		dealership.UpdatedAt,
+	)
+
+	if err != nil {
+		return models.Dealership{}, err
+	}
+
+	return dealership, nil
+}
+
+func (d DealershipStore) UpdateDealership(ctx context.Context, id string, dealershipReq *models.DealershipRequest) (models.Dealership, error) {
+	tracer := otel.Tracer("DealershipStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateDealership-Store")
+
+	defer span.End()
+
+	query := `UPDATE dealership
+	          SET name = $2, address = $3, latitude = $4, longitude = $5, opening_hours = $6, phone = $7, email = $8, updated_at = $9
+	          WHERE id = $1
+	          RETURNING ` + dealershipColumns
+
+	dealership, err := scanDealership(d.db.QueryRowContext(ctx, query,
+		id,
+		strings.TrimSpace(dealershipReq.Name),
+		strings.TrimSpace(dealershipReq.Address),
+		dealershipReq.Latitude,
+		dealershipReq.Longitude,
+		models.NormalizeOpeningHours(dealershipReq.OpeningHours),
+		dealershipReq.Phone,
+		dealershipReq.Email,
+		time.Now(),
+	))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Dealership{}, errors.New("Dealership ID not found")
+		}
+		return models.Dealership{}, err
+	}
+
+	return dealership, nil
+}
+
+// DeleteDealership removes the dealership; its cars stay listed but are no
+// longer assigned to any dealership (car.dealership_id is ON DELETE SET NULL).
+func (d DealershipStore) DeleteDealership(ctx context.Context, id string) (models.Dealership, error) {
+	tracer := otel.Tracer("DealershipStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteDealership-Store")
+
+	defer span.End()
+
+	dealership, err := scanDealership(d.db.QueryRowContext(ctx, "DELETE FROM dealership WHERE id = $1 RETURNING "+dealershipColumns, id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Dealership{}, errors.New("Dealership ID not found")
+		}
+		return models.Dealership{}, err
+	}
+
+	return dealership, nil
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store/audit"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.opentelemetry.io/otel"
 )
 
+const auditEntityEngine = "engine"
+
 type EngineStore struct {
 	//dba,
 	//dbb
@@ -29,11 +34,20 @@ func (e EngineStore) EngineById(ctx context.Context, id string) (models.Engine,
 	defer span.End()
 	var get_engine_byid models.Engine
 
-	err := e.db.QueryRowContext(ctx, "SELECT id, displacement, no_of_cylinders, car_range FROM engine WHERE id =$1", id).Scan(
+	err := e.db.QueryRowContext(ctx, "SELECT id, displacement, no_of_cylinders, car_range, powertrain, battery_kwh, motor_kw, charging_standards, wltp_range_km, power_kw, torque_nm, transmission, co2_g_per_km FROM engine WHERE id =$1", id).Scan(
 		&get_engine_byid.EngineID,
 		&get_engine_byid.Displacement,
 		&get_engine_byid.NoOfCyclinders,
-		&get_engine_byid.CarRange)
+		&get_engine_byid.CarRange,
+		&get_engine_byid.Powertrain,
+		&get_engine_byid.BatteryKWh,
+		&get_engine_byid.MotorKW,
+		pq.Array(&get_engine_byid.ChargingStandards),
+		&get_engine_byid.WLTPRangeKm,
+		&get_engine_byid.PowerKW,
+		&get_engine_byid.TorqueNm,
+		&get_engine_byid.Transmission,
+		&get_engine_byid.CO2GPerKm)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -45,7 +59,7 @@ func (e EngineStore) EngineById(ctx context.Context, id string) (models.Engine,
 	return get_engine_byid, nil
 
 }
-func (e EngineStore) CreateEngine(ctx context.Context, engineReq *models.EngineRequest) (models.Engine, error) {
+func (e EngineStore) CreateEngine(ctx context.Context, actor models.AuditActor, engineReq *models.EngineRequest) (models.Engine, error) {
 	tracer := otel.Tracer("EngineStore")
 
 	ctx, span := tracer.Start(ctx, "CreateEngine-Store")
@@ -68,7 +82,19 @@ func (e EngineStore) CreateEngine(ctx context.Context, engineReq *models.EngineR
 
 	engineID := uuid.New()
 
-	query := `INSERT INTO engine(id, displacement, no_of_cylinders,car_range) VALUES ($1, $2, $3, $4)
+	chargingStandards := models.NormalizeChargingStandards(engineReq.ChargingStandards)
+
+	powerKW, err := engineReq.Power.KW()
+	if err != nil {
+		return models.Engine{}, err
+	}
+
+	torqueNm, err := engineReq.Torque.Nm()
+	if err != nil {
+		return models.Engine{}, err
+	}
+
+	query := `INSERT INTO engine(id, displacement, no_of_cylinders,car_range, powertrain, battery_kwh, motor_kw, charging_standards, wltp_range_km, power_kw, torque_nm, transmission, co2_g_per_km) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	          RETURNING id, displacement, no_of_cylinders ,car_range`
 
 	_, err = tx.Exec(query,
@@ -76,6 +102,15 @@ func (e EngineStore) CreateEngine(ctx context.Context, engineReq *models.EngineR
 		engineReq.Displacement,
 		engineReq.NoOfCyclinders,
 		engineReq.CarRange,
+		engineReq.Powertrain,
+		engineReq.BatteryKWh,
+		engineReq.MotorKW,
+		pq.Array(chargingStandards),
+		engineReq.WLTPRangeKm,
+		powerKW,
+		torqueNm,
+		engineReq.Transmission,
+		engineReq.CO2GPerKm,
 	)
 
 	if err != nil {
@@ -83,17 +118,34 @@ func (e EngineStore) CreateEngine(ctx context.Context, engineReq *models.EngineR
 	}
 
 	engine_created := models.Engine{
-		EngineID:       engineID,
-		Displacement:   engineReq.Displacement,
-		NoOfCyclinders: engineReq.NoOfCyclinders,
-		CarRange:       engineReq.CarRange,
+		EngineID:          engineID,
+		Displacement:      engineReq.Displacement,
+		NoOfCyclinders:    engineReq.NoOfCyclinders,
+		CarRange:          engineReq.CarRange,
+		Powertrain:        engineReq.Powertrain,
+		BatteryKWh:        engineReq.BatteryKWh,
+		MotorKW:           engineReq.MotorKW,
+		ChargingStandards: chargingStandards,
+		WLTPRangeKm:       engineReq.WLTPRangeKm,
+		PowerKW:           powerKW,
+		TorqueNm:          torqueNm,
+		Transmission:      engineReq.Transmission,
+		CO2GPerKm:         engineReq.CO2GPerKm,
+	}
+
+	err = audit.Record(ctx, tx, actor, models.AuditActionCreate, auditEntityEngine, engineID.String(), nil, engine_created)
+	if err != nil {
+		return models.Engine{}, err
 	}
 
 	return engine_created, nil
 
 }
 
-func (e EngineStore) EngineUpdate(ctx context.Context, id string, engineReq *models.EngineRequest) (models.Engine, error) {
+// EngineUpdate changes the engine and re-checks every car using it, so an
+// engine cannot be turned into one its cars' fuel types do not allow. Named
+// results let the deferred commit report its error.
+func (e EngineStore) EngineUpdate(ctx context.Context, actor models.AuditActor, id string, engineReq *models.EngineRequest) (engine models.Engine, txErr error) {
 
 	tracer := otel.Tracer("EngineStore")
 
@@ -101,12 +153,21 @@ func (e EngineStore) EngineUpdate(ctx context.Context, id string, engineReq *mod
 
 	defer span.End()
 	// Kiểm tra xem engine có tồn tại không
-	var existingID uuid.UUID
-	err := e.db.QueryRowContext(ctx, "SELECT id FROM engine WHERE id = $1", id).Scan(&existingID)
+	before, err := e.EngineById(ctx, id)
+	if err != nil {
+		return models.Engine{}, err
+	}
+	existingID := before.EngineID
+
+	chargingStandards := models.NormalizeChargingStandards(engineReq.ChargingStandards)
+
+	powerKW, err := engineReq.Power.KW()
+	if err != nil {
+		return models.Engine{}, err
+	}
+
+	torqueNm, err := engineReq.Torque.Nm()
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return models.Engine{}, errors.New("Engine ID not found")
-		}
 		return models.Engine{}, err
 	}
 
@@ -117,7 +178,6 @@ func (e EngineStore) EngineUpdate(ctx context.Context, id string, engineReq *mod
 	}
 
 	// Deferred function quản lý transaction
-	var txErr error
 	defer func() {
 		if txErr != nil {
 			tx.Rollback()
@@ -129,7 +189,9 @@ func (e EngineStore) EngineUpdate(ctx context.Context, id string, engineReq *mod
 	// Câu lệnh SQL cập nhật
 	query := `
         UPDATE engine 
-        SET displacement = $1, no_of_cylinders = $2, car_range = $3, updated_at = $4
+        SET displacement = $1, no_of_cylinders = $2, car_range = $3, updated_at = $4,
+            powertrain = $6, battery_kwh = $7, motor_kw = $8, charging_standards = $9, wltp_range_km = $10,
+            power_kw = $11, torque_nm = $12, transmission = $13, co2_g_per_km = $14
         WHERE id = $5
     `
 
@@ -140,6 +202,15 @@ func (e EngineStore) EngineUpdate(ctx context.Context, id string, engineReq *mod
 		engineReq.CarRange,
 		time.Now(),
 		id,
+		engineReq.Powertrain,
+		engineReq.BatteryKWh,
+		engineReq.MotorKW,
+		pq.Array(chargingStandards),
+		engineReq.WLTPRangeKm,
+		powerKW,
+		torqueNm,
+		engineReq.Transmission,
+		engineReq.CO2GPerKm,
 	)
 	if txErr != nil {
 		return models.Engine{}, txErr
@@ -155,17 +226,36 @@ func (e EngineStore) EngineUpdate(ctx context.Context, id string, engineReq *mod
 	}
 
 	// Tạo đối tượng trả về
-	engine := models.Engine{
-		EngineID:       existingID,
-		Displacement:   engineReq.Displacement,
-		NoOfCyclinders: engineReq.NoOfCyclinders,
-		CarRange:       engineReq.CarRange,
+	engine = models.Engine{
+		EngineID:          existingID,
+		Displacement:      engineReq.Displacement,
+		NoOfCyclinders:    engineReq.NoOfCyclinders,
+		CarRange:          engineReq.CarRange,
+		Powertrain:        engineReq.Powertrain,
+		BatteryKWh:        engineReq.BatteryKWh,
+		MotorKW:           engineReq.MotorKW,
+		ChargingStandards: chargingStandards,
+		WLTPRangeKm:       engineReq.WLTPRangeKm,
+		PowerKW:           powerKW,
+		TorqueNm:          torqueNm,
+		Transmission:      engineReq.Transmission,
+		CO2GPerKm:         engineReq.CO2GPerKm,
+	}
+
+	txErr = checkCarsUsing(ctx, tx, engine)
+	if txErr != nil {
+		return models.Engine{}, txErr
+	}
+
+	txErr = audit.Record(ctx, tx, actor, models.AuditActionUpdate, auditEntityEngine, id, before, engine)
+	if txErr != nil {
+		return models.Engine{}, txErr
 	}
 
 	return engine, nil
 }
 
-func (e EngineStore) DeleteEngine(ctx context.Context, id string) (models.Engine, error) {
+func (e EngineStore) DeleteEngine(ctx context.Context, actor models.AuditActor, id string) (models.Engine, error) {
 
 	tracer := otel.Tracer("EngineStore")
 
@@ -174,10 +264,19 @@ func (e EngineStore) DeleteEngine(ctx context.Context, id string) (models.Engine
 	defer span.End()
 	var engine_deleted_byid models.Engine
 
-	err := e.db.QueryRowContext(ctx, "SELECT id, displacement, no_of_cylinders, car_range FROM engine WHERE id =$1", id).Scan(&engine_deleted_byid.EngineID,
+	err := e.db.QueryRowContext(ctx, "SELECT id, displacement, no_of_cylinders, car_range, powertrain, battery_kwh, motor_kw, charging_standards, wltp_range_km, power_kw, torque_nm, transmission, co2_g_per_km FROM engine WHERE id =$1", id).Scan(&engine_deleted_byid.EngineID,
 		&engine_deleted_byid.Displacement,
 		&engine_deleted_byid.NoOfCyclinders,
-		&engine_deleted_byid.CarRange)
+		&engine_deleted_byid.CarRange,
+		&engine_deleted_byid.Powertrain,
+		&engine_deleted_byid.BatteryKWh,
+		&engine_deleted_byid.MotorKW,
+		pq.Array(&engine_deleted_byid.ChargingStandards),
+		&engine_deleted_byid.WLTPRangeKm,
+		&engine_deleted_byid.PowerKW,
+		&engine_deleted_byid.TorqueNm,
+		&engine_deleted_byid.Transmission,
+		&engine_deleted_byid.CO2GPerKm)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -219,6 +318,36 @@ func (e EngineStore) DeleteEngine(ctx context.Context, id string) (models.Engine
 		return models.Engine{}, errors.New("No engine deleted")
 	}
 
+	err = audit.Record(ctx, tx, actor, models.AuditActionDelete, auditEntityEngine, id, engine_deleted_byid, nil)
+	if err != nil {
+		return models.Engine{}, err
+	}
+
 	return engine_deleted_byid, nil
 
 }
+
+// checkCarsUsing validates the updated engine against the fuel type of every
+// car that uses it. The cars are locked so none changes fuel type meanwhile.
+func checkCarsUsing(ctx context.Context, tx *sql.Tx, engine models.Engine) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id, fuel_type FROM car WHERE engine_id = $1 FOR UPDATE", engine.EngineID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var carID uuid.UUID
+		var fuelType models.FuelType
+
+		if err := rows.Scan(&carID, &fuelType); err != nil {
+			return err
+		}
+
+		if err := models.ValidateCarEngine(engine, fuelType); err != nil {
+			return fmt.Errorf("Engine no longer suits car %s: %w", carID, err)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,169 @@
+package exchangerate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+)
+
+const rateCacheTTL = 10 * time.Minute
+
+type ExchangeRateStore struct {
+	db          *sql.DB
+	redisClient *redis.Client
+}
+
+func New(db *sql.DB, redisClient *redis.Client) ExchangeRateStore {
+	return ExchangeRateStore{db: db, redisClient: redisClient}
+}
+
+func rateCacheKey(base, quote string) string {
+	return fmt.Sprintf("ExchangeRate:%s:%s", base, quote)
+}
+
+// GetRate returns the stored rate for base to quote, reading through the
+// Redis cache. It does not derive inverse or cross rates.
+func (s ExchangeRateStore) GetRate(ctx context.Context, base, quote string) (models.ExchangeRate, error) {
+	tracer := otel.Tracer("ExchangeRateStore")
+
+	ctx, span := tracer.Start(ctx, "GetRate-Store")
+
+	defer span.End()
+
+	var rate models.ExchangeRate
+
+	key := rateCacheKey(base, quote)
+
+	cachedData, err := s.redisClient.Get(ctx, key).Result()
+	if err == nil {
+		if err := json.Unmarshal([]byte(cachedData), &rate); err == nil {
+			return rate, nil
+		}
+	}
+
+	err = s.db.QueryRowContext(ctx,
+		"SELECT base, quote, rate, updated_at FROM exchange_rate WHERE base = $1 AND quote = $2", base, quote).
+		Scan(&rate.Base, &rate.Quote, &rate.Rate, &rate.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ExchangeRate{}, fmt.Errorf("%w: %s to %s", models.ErrExchangeRateNotFound, base, quote)
+		}
+		return models.ExchangeRate{}, err
+	}
+
+	jsonData, err := json.Marshal(rate)
+	if err == nil {
+		if err := s.redisClient.Set(ctx, key, jsonData, rateCacheTTL).Err(); err != nil {
+			log.Println("Failed to set data to redis: ", err)
+		}
+	}
+
+	return rate, nil
+}
+
+func (s ExchangeRateStore) GetRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	tracer := otel.Tracer("ExchangeRateStore")
+
+	ctx, span := tracer.Start(ctx, "GetRates-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT base, quote, rate, updated_at FROM exchange_rate ORDER BY base, quote")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+
+	for rows.Next() {
+		var rate models.ExchangeRate
+		if err := rows.Scan(&rate.Base, &rate.Quote, &rate.Rate, &rate.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// UpsertRates stores the given rates in one transaction and drops their
+// cached copies once committed.
+func (s ExchangeRateStore) UpsertRates(ctx context.Context, rates []models.ExchangeRate) error {
+	tracer := otel.Tracer("ExchangeRateStore")
+
+	ctx, span := tracer.Start(ctx, "UpsertRates-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO exchange_rate (base, quote, rate, updated_at) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (base, quote) DO UPDATE SET rate = EXCLUDED.rate, updated_at = EXCLUDED.updated_at`
+
+	for _, rate := range rates {
+		if _, err := tx.ExecContext(ctx, query, rate.Base, rate.Quote, rate.Rate, rate.UpdatedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	keys := make([]string, len(rates))
+	for i, rate := range rates {
+		keys[i] = rateCacheKey(rate.Base, rate.Quote)
+	}
+	if len(keys) > 0 {
+		if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+			log.Println("Failed to invalidate exchange rate cache: ", err)
+		}
+	}
+
+	return nil
+}
+
+// SeedRates stores the rates whose pairs are not stored yet and leaves the
+// others as they are, so rates set by an admin survive a reseed.
+func (s ExchangeRateStore) SeedRates(ctx context.Context, rates []models.ExchangeRate) error {
+	tracer := otel.Tracer("ExchangeRateStore")
+
+	ctx, span := tracer.Start(ctx, "SeedRates-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO exchange_rate (base, quote, rate, updated_at) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (base, quote) DO NOTHING`
+
+	for _, rate := range rates {
+		if _, err := tx.ExecContext(ctx, query, rate.Base, rate.Quote, rate.Rate, rate.UpdatedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
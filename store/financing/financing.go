@@ -0,0 +1,192 @@
+package financing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const lenderProductColumns = "id, name, lender, apr, min_term_months, max_term_months, min_down_payment_percent, active, created_at, updated_at"
+
+type FinancingStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) FinancingStore {
+	return FinancingStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLenderProduct(row rowScanner) (models.LenderProduct, error) {
+	var product models.LenderProduct
+	err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.Lender,
+		&product.APR,
+		&product.MinTermMonths,
+		&product.MaxTermMonths,
+		&product.MinDownPaymentPercent,
+		&product.Active,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	)
+	return product, err
+}
+
+// GetLenderProducts lists products by lender and name; inactive ones only
+// when activeOnly is false.
+func (s FinancingStore) GetLenderProducts(ctx context.Context, activeOnly bool) ([]models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingStore")
+
+	ctx, span := tracer.Start(ctx, "GetLenderProducts-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+lenderProductColumns+" FROM lender_product WHERE active OR NOT $1 ORDER BY lender, name", activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.LenderProduct{}
+
+	for rows.Next() {
+		product, err := scanLenderProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (s FinancingStore) GetLenderProductById(ctx context.Context, id string) (models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingStore")
+
+	ctx, span := tracer.Start(ctx, "GetLenderProductByID-Store")
+
+	defer span.End()
+
+	product, err := scanLenderProduct(s.db.QueryRowContext(ctx, "SELECT "+lenderProductColumns+" FROM lender_product WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.LenderProduct{}, models.ErrLenderProductNotFound
+		}
+		return models.LenderProduct{}, err
+	}
+
+	return product, nil
+}
+
+func (s FinancingStore) CreateLenderProduct(ctx context.Context, productReq *models.LenderProductRequest) (models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingStore")
+
+	ctx, span := tracer.Start(ctx, "CreateLenderProduct-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	product := models.LenderProduct{
+		ID:                    uuid.New(),
+		Name:                  strings.TrimSpace(productReq.Name),
+		Lender:                strings.TrimSpace(productReq.Lender),
+		APR:                   productReq.APR,
+		MinTermMonths:         productReq.MinTermMonths,
+		MaxTermMonths:         productReq.MaxTermMonths,
+		MinDownPaymentPercent: productReq.MinDownPaymentPercent,
+		Active:                productReq.Active,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+
+	query := `INSERT INTO lender_product (` + lenderProductColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := s.db.ExecContext(ctx, query,
+		product.ID,
+		product.Name,
+		product.Lender,
+		product.APR,
+		product.MinTermMonths,
+		product.MaxTermMonths,
+		product.MinDownPaymentPercent,
+		product.Active,
+		product.CreatedAt,
+		product.UpdatedAt,
+	)
+
+	if err != nil {
+		return models.LenderProduct{}, err
+	}
+
+	return product, nil
+}
+
+func (s FinancingStore) UpdateLenderProduct(ctx context.Context, id string, productReq *models.LenderProductRequest) (models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateLenderProduct-Store")
+
+	defer span.End()
+
+	query := `UPDATE lender_product
+	          SET name = $2, lender = $3, apr = $4, min_term_months = $5, max_term_months = $6, min_down_payment_percent = $7, active = $8, updated_at = $9
+	          WHERE id = $1
+	          RETURNING ` + lenderProductColumns
+
+	product, err := scanLenderProduct(s.db.QueryRowContext(ctx, query,
+		id,
+		strings.TrimSpace(productReq.Name),
+		strings.TrimSpace(productReq.Lender),
+		productReq.APR,
+		productReq.MinTermMonths,
+		productReq.MaxTermMonths,
+		productReq.MinDownPaymentPercent,
+		productReq.Active,
+		time.Now(),
+	))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.LenderProduct{}, models.ErrLenderProductNotFound
+		}
+		return models.LenderProduct{}, err
+	}
+
+	return product, nil
+}
+
+func (s FinancingStore) DeleteLenderProduct(ctx context.Context, id string) (models.LenderProduct, error) {
+	tracer := otel.Tracer("FinancingStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteLenderProduct-Store")
+
+	defer span.End()
+
+	product, err := scanLenderProduct(s.db.QueryRowContext(ctx, "DELETE FROM lender_product WHERE id = $1 RETURNING "+lenderProductColumns, id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.LenderProduct{}, models.ErrLenderProductNotFound
+		}
+		return models.LenderProduct{}, err
+	}
+
+	return product, nil
+}
@@ -2,8 +2,10 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
 )
 
 type CarStoreInterface interface {
@@ -11,21 +13,305 @@ type CarStoreInterface interface {
 
 	GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error)
 
-	CreateCar(ctx context.Context, carReq *models.CarRequest) (models.Car, error)
+	CreateCar(ctx context.Context, actor models.AuditActor, carReq *models.CarRequest) (models.Car, error)
 
-	DeleteCar(ctx context.Context, id string) (models.Car, error)
+	DeleteCar(ctx context.Context, actor models.AuditActor, id string) (models.Car, error)
 
-	UpdateCar(ctx context.Context, id string, carReq *models.CarRequest) (models.Car, error)
+	UpdateCar(ctx context.Context, actor models.AuditActor, id string, carReq *models.CarRequest) (models.Car, error)
+
+	SearchCars(ctx context.Context, q string, limit int) ([]models.CarSearchResult, error)
+
+	Suggest(ctx context.Context, prefix string, limit int) (models.Suggestions, error)
+
+	ListCars(ctx context.Context, filter models.CarFilter) ([]models.Car, error)
+
+	GetPriceHistory(ctx context.Context, carID string) ([]models.PriceChange, error)
+
+	RebuildSuggestIndex(ctx context.Context) error
+
+	InvalidateBrandCache(ctx context.Context, brands ...string) error
 }
 
 type EngineStoreInterface interface {
 	EngineById(ctx context.Context, id string) (models.Engine, error)
 
-	CreateEngine(ctx context.Context, engineReq *models.EngineRequest) (models.Engine, error)
+	CreateEngine(ctx context.Context, actor models.AuditActor, engineReq *models.EngineRequest) (models.Engine, error)
+
+	EngineUpdate(ctx context.Context, actor models.AuditActor, id string, engineReq *models.EngineRequest) (models.Engine, error)
+
+	DeleteEngine(ctx context.Context, actor models.AuditActor, id string) (models.Engine, error)
+}
+
+type BrandStoreInterface interface {
+	GetBrands(ctx context.Context) ([]models.Brand, error)
+
+	GetBrandById(ctx context.Context, id string) (models.Brand, error)
+
+	CreateBrand(ctx context.Context, brandReq *models.BrandRequest) (models.Brand, error)
+
+	UpdateBrand(ctx context.Context, actor models.AuditActor, id string, brandReq *models.BrandRequest) (models.Brand, error)
+
+	DeleteBrand(ctx context.Context, id string) (models.Brand, error)
+
+	GetModelsByBrand(ctx context.Context, brandID string) ([]models.CarModel, error)
+
+	GetModelById(ctx context.Context, id string) (models.CarModel, error)
+
+	CreateModel(ctx context.Context, brandID string, modelReq *models.CarModelRequest) (models.CarModel, error)
+
+	UpdateModel(ctx context.Context, id string, modelReq *models.CarModelRequest) (models.CarModel, error)
+
+	DeleteModel(ctx context.Context, id string) (models.CarModel, error)
+}
+
+type ExchangeRateStoreInterface interface {
+	GetRate(ctx context.Context, base, quote string) (models.ExchangeRate, error)
+
+	GetRates(ctx context.Context) ([]models.ExchangeRate, error)
+
+	UpsertRates(ctx context.Context, rates []models.ExchangeRate) error
+
+	SeedRates(ctx context.Context, rates []models.ExchangeRate) error
+}
+
+type AuditStoreInterface interface {
+	GetEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+type DealershipStoreInterface interface {
+	GetDealerships(ctx context.Context) ([]models.Dealership, error)
+
+	GetDealershipById(ctx context.Context, id string) (models.Dealership, error)
+
+	CreateDealership(ctx context.Context, dealershipReq *models.DealershipRequest) (models.Dealership, error)
+
+	UpdateDealership(ctx context.Context, id string, dealershipReq *models.DealershipRequest) (models.Dealership, error)
+
+	DeleteDealership(ctx context.Context, id string) (models.Dealership, error)
+}
+
+type InventoryStoreInterface interface {
+	GetUnitsByCar(ctx context.Context, carID string) ([]models.InventoryUnit, error)
+
+	GetUnitById(ctx context.Context, id string) (models.InventoryUnit, error)
+
+	CreateUnit(ctx context.Context, carID string, unitReq *models.InventoryUnitRequest) (models.InventoryUnit, error)
+
+	UpdateUnit(ctx context.Context, id string, unitReq *models.InventoryUnitRequest) (models.InventoryUnit, error)
+
+	DeleteUnit(ctx context.Context, id string) (models.InventoryUnit, error)
+
+	GetAvailability(ctx context.Context, carIDs []uuid.UUID) (map[uuid.UUID]models.Availability, error)
+}
+
+type ReservationStoreInterface interface {
+	CreateReservation(ctx context.Context, customer string, unitID uuid.UUID, expiresAt time.Time) (models.Reservation, error)
+
+	GetReservationById(ctx context.Context, id string) (models.Reservation, error)
+
+	GetReservationsByCustomer(ctx context.Context, customer string) ([]models.Reservation, error)
+
+	CancelReservation(ctx context.Context, id, customer string) (models.Reservation, error)
+
+	ExpireReservations(ctx context.Context, now time.Time) (int, error)
+}
+
+type TestDriveStoreInterface interface {
+	CountEligibleUnits(ctx context.Context, carID, dealershipID string) (int, error)
+
+	GetActiveTestDrives(ctx context.Context, carID, dealershipID string, from, to time.Time) ([]models.TestDrive, error)
+
+	GetTestDrivesByCustomer(ctx context.Context, customer string) ([]models.TestDrive, error)
+
+	GetTestDriveById(ctx context.Context, id string) (models.TestDrive, error)
+
+	CreateTestDrive(ctx context.Context, customer string, testDriveReq *models.TestDriveRequest, endsAt time.Time) (models.TestDrive, error)
+
+	RescheduleTestDrive(ctx context.Context, id, customer string, startsAt, endsAt time.Time) (models.TestDrive, error)
+
+	UpdateTestDriveStatus(ctx context.Context, id, customer string, status models.TestDriveStatus) (models.TestDrive, error)
+}
+
+type OrderStoreInterface interface {
+	CreateOrder(ctx context.Context, order models.Order) (models.Order, error)
+
+	GetOrderById(ctx context.Context, id string) (models.Order, error)
+
+	GetOrdersByCustomer(ctx context.Context, customer string) ([]models.Order, error)
+
+	GetOrdersByDealership(ctx context.Context, dealershipID string) ([]models.Order, error)
+
+	UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) (models.Order, error)
+
+	UpdateOrderAmounts(ctx context.Context, id string, discount, tax, total models.Money) (models.Order, error)
+}
+
+type PaymentStoreInterface interface {
+	CreatePayment(ctx context.Context, payment models.Payment) error
+
+	GetPaymentById(ctx context.Context, id string) (models.Payment, error)
+
+	GetPaymentsByOrder(ctx context.Context, orderID string) ([]models.Payment, error)
+
+	GetOpenPayments(ctx context.Context, before time.Time) ([]models.Payment, error)
+
+	ApplySnapshot(ctx context.Context, id uuid.UUID, snapshot models.PaymentSnapshot) (models.Payment, error)
+
+	ProcessWebhookEvent(ctx context.Context, provider string, event models.PaymentEvent) (models.Payment, error)
+}
+
+type FinancingStoreInterface interface {
+	GetLenderProducts(ctx context.Context, activeOnly bool) ([]models.LenderProduct, error)
+
+	GetLenderProductById(ctx context.Context, id string) (models.LenderProduct, error)
+
+	CreateLenderProduct(ctx context.Context, productReq *models.LenderProductRequest) (models.LenderProduct, error)
+
+	UpdateLenderProduct(ctx context.Context, id string, productReq *models.LenderProductRequest) (models.LenderProduct, error)
+
+	DeleteLenderProduct(ctx context.Context, id string) (models.LenderProduct, error)
+}
+
+type ValuationStoreInterface interface {
+	GetCurves(ctx context.Context) ([]models.DepreciationCurve, error)
+
+	GetCurveById(ctx context.Context, id string) (models.DepreciationCurve, error)
+
+	GetCurveForBrand(ctx context.Context, brand string) (models.DepreciationCurve, error)
+
+	CreateCurve(ctx context.Context, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error)
+
+	UpdateCurve(ctx context.Context, id string, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error)
+
+	DeleteCurve(ctx context.Context, id string) (models.DepreciationCurve, error)
+
+	GetListingSamples(ctx context.Context, brand, model string) ([]models.ValuationSample, error)
+
+	GetSaleSamples(ctx context.Context, brand, model string, since time.Time) ([]models.ValuationSample, error)
+}
+
+type PromotionStoreInterface interface {
+	GetPromotions(ctx context.Context) ([]models.Promotion, error)
+
+	GetLivePromotions(ctx context.Context, at time.Time) ([]models.Promotion, error)
+
+	GetPromotionById(ctx context.Context, id string) (models.Promotion, error)
+
+	CreatePromotion(ctx context.Context, promotionReq *models.PromotionRequest) (models.Promotion, error)
+
+	UpdatePromotion(ctx context.Context, id string, promotionReq *models.PromotionRequest) (models.Promotion, error)
+
+	DeletePromotion(ctx context.Context, id string) (models.Promotion, error)
+}
+
+type TaxStoreInterface interface {
+	GetTaxRegions(ctx context.Context) ([]models.TaxRegion, error)
+
+	GetTaxRegion(ctx context.Context, code string) (models.TaxRegion, error)
+
+	UpsertTaxRegion(ctx context.Context, code string, regionReq *models.TaxRegionRequest) (models.TaxRegion, error)
+
+	DeleteTaxRegion(ctx context.Context, code string) (models.TaxRegion, error)
+
+	GetSCTRules(ctx context.Context) ([]models.SCTRule, error)
+
+	CreateSCTRule(ctx context.Context, ruleReq *models.SCTRuleRequest) (models.SCTRule, error)
+
+	UpdateSCTRule(ctx context.Context, id string, ruleReq *models.SCTRuleRequest) (models.SCTRule, error)
+
+	DeleteSCTRule(ctx context.Context, id string) (models.SCTRule, error)
+}
+
+type WishlistStoreInterface interface {
+	GetWishlist(ctx context.Context, customer string) ([]models.WishlistItem, error)
+
+	AddToWishlist(ctx context.Context, customer, carID string) (models.WishlistItem, error)
+
+	RemoveFromWishlist(ctx context.Context, customer, carID string) error
+}
+
+type SavedSearchStoreInterface interface {
+	GetSavedSearches(ctx context.Context) ([]models.SavedSearch, error)
+
+	GetSavedSearchesByCustomer(ctx context.Context, customer string) ([]models.SavedSearch, error)
+
+	GetSavedSearchById(ctx context.Context, id string) (models.SavedSearch, error)
+
+	CreateSavedSearch(ctx context.Context, customer string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error)
+
+	UpdateSavedSearch(ctx context.Context, id string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error)
+
+	DeleteSavedSearch(ctx context.Context, id string) (models.SavedSearch, error)
+
+	GetMatches(ctx context.Context, searchID string) ([]models.SavedSearchMatch, error)
+
+	RecordEvaluation(ctx context.Context, searchID uuid.UUID, carIDs []uuid.UUID, evaluatedAt time.Time) error
+}
+
+type NotificationStoreInterface interface {
+	GetTemplates(ctx context.Context) ([]models.NotificationTemplate, error)
+
+	GetTemplate(ctx context.Context, kind models.NotificationKind) (models.NotificationTemplate, error)
+
+	UpsertTemplate(ctx context.Context, kind models.NotificationKind, templateReq *models.NotificationTemplateRequest) (models.NotificationTemplate, error)
+
+	GetPreferences(ctx context.Context, customer string) (models.NotificationPreferences, error)
+
+	UpsertPreferences(ctx context.Context, customer string, preferencesReq *models.NotificationPreferencesRequest) (models.NotificationPreferences, error)
+
+	EnqueueDelivery(ctx context.Context, delivery models.NotificationDelivery) (bool, error)
+
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.NotificationDelivery, error)
+
+	MarkDeliverySent(ctx context.Context, id uuid.UUID, at time.Time) error
+
+	MarkDeliveryFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttempt *time.Time, at time.Time) error
+
+	GetDeliveries(ctx context.Context, status *models.DeliveryStatus, limit int) ([]models.NotificationDelivery, error)
+
+	AddInboxMessage(ctx context.Context, delivery models.NotificationDelivery) error
+
+	GetInbox(ctx context.Context, customer string, unreadOnly bool) ([]models.InboxMessage, error)
+
+	MarkInboxMessageRead(ctx context.Context, id, customer string, at time.Time) (models.InboxMessage, error)
+
+	GetPriceDropEvents(ctx context.Context, since time.Time) ([]models.NotificationEvent, error)
+
+	GetReservationExpiringEvents(ctx context.Context, from, to time.Time) ([]models.NotificationEvent, error)
+
+	GetReservationExpiredEvents(ctx context.Context, since time.Time) ([]models.NotificationEvent, error)
+
+	GetTestDriveReminderEvents(ctx context.Context, from, to time.Time) ([]models.NotificationEvent, error)
+}
+
+type WebhookStoreInterface interface {
+	GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+
+	GetActiveSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+
+	GetSubscriptionById(ctx context.Context, id string) (models.WebhookSubscription, error)
+
+	CreateSubscription(ctx context.Context, subscriptionReq *models.WebhookSubscriptionRequest, secret string) (models.WebhookSubscription, error)
+
+	UpdateSubscription(ctx context.Context, id string, subscriptionReq *models.WebhookSubscriptionRequest) (models.WebhookSubscription, error)
+
+	DeleteSubscription(ctx context.Context, id string) (models.WebhookSubscription, error)
+
+	GetCursor(ctx context.Context) (time.Time, error)
+
+	GetAuditEntries(ctx context.Context, entityTypes []string, from, to time.Time) ([]models.AuditEntry, error)
+
+	EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery, position time.Time) error
+
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+
+	RecordAttempt(ctx context.Context, attempt models.WebhookAttempt, nextAttempt *time.Time) error
+
+	GetDeliveries(ctx context.Context, subscriptionID string, status *models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error)
 
-	EngineUpdate(ctx context.Context, id string, engineReq *models.EngineRequest) (models.Engine, error)
+	GetDeliveryById(ctx context.Context, id string) (models.WebhookDelivery, error)
 
-	DeleteEngine(ctx context.Context, id string) (models.Engine, error)
+	Redeliver(ctx context.Context, id string, now time.Time) (models.WebhookDelivery, error)
 }
 
 //type LoginStoreInterface interface {
@@ -0,0 +1,251 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+const unitColumns = "id, car_id, vin, color, mileage_km, status, dealership_id, created_at, updated_at"
+
+// foreignKeyViolation is the Postgres error code raised when a deleted unit
+// is still referenced by sales_order.
+const foreignKeyViolation = "23503"
+
+type InventoryStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) InventoryStore {
+	return InventoryStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUnit(row rowScanner) (models.InventoryUnit, error) {
+	var unit models.InventoryUnit
+	err := row.Scan(
+		&unit.ID,
+		&unit.CarID,
+		&unit.VIN,
+		&unit.Color,
+		&unit.MileageKm,
+		&unit.Status,
+		&unit.DealershipID,
+		&unit.CreatedAt,
+		&unit.UpdatedAt,
+	)
+	return unit, err
+}
+
+func (i InventoryStore) GetUnitsByCar(ctx context.Context, carID string) ([]models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryStore")
+
+	ctx, span := tracer.Start(ctx, "GetUnitsByCar-Store")
+
+	defer span.End()
+
+	rows, err := i.db.QueryContext(ctx, "SELECT "+unitColumns+" FROM inventory_unit WHERE car_id = $1 ORDER BY created_at", carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := []models.InventoryUnit{}
+
+	for rows.Next() {
+		unit, err := scanUnit(rows)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return units, nil
+}
+
+func (i InventoryStore) GetUnitById(ctx context.Context, id string) (models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryStore")
+
+	ctx, span := tracer.Start(ctx, "GetUnitByID-Store")
+
+	defer span.End()
+
+	unit, err := scanUnit(i.db.QueryRowContext(ctx, "SELECT "+unitColumns+" FROM inventory_unit WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.InventoryUnit{}, errors.New("Inventory unit ID not found")
+		}
+		return models.InventoryUnit{}, err
+	}
+
+	return unit, nil
+}
+
+// CreateUnit inserts a unit for an existing car. Units registered without a
+// dealership are placed at the car's dealership.
+func (i InventoryStore) CreateUnit(ctx context.Context, carID string, unitReq *models.InventoryUnitRequest) (models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryStore")
+
+	ctx, span := tracer.Start(ctx, "CreateUnit-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	query := `INSERT INTO inventory_unit (` + unitColumns + `)
+	          SELECT $1, c.id, $3, $4, $5, $6, COALESCE($7, c.dealership_id), $8, $8
+	          FROM car c WHERE c.id = $2
+	          RETURNING ` + unitColumns
+
+	unit, err := scanUnit(i.db.QueryRowContext(ctx, query,
+		uuid.New(),
+		carID,
+		models.NormalizeVIN(unitReq.VIN),
+		strings.TrimSpace(unitReq.Color),
+		unitReq.MileageKm,
+		unitReq.Status,
+		unitReq.DealershipID,
+		now,
+	))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.InventoryUnit{}, errors.New("Car ID does not exists in the car table")
+		}
+		return models.InventoryUnit{}, err
+	}
+
+	return unit, nil
+}
+
+// UpdateUnit keeps the unit's status when the request has none. The status
+// only changes while the unit is available or in transit, checked in the
+// same statement so a reservation or order taken meanwhile is not undone.
+func (i InventoryStore) UpdateUnit(ctx context.Context, id string, unitReq *models.InventoryUnitRequest) (models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateUnit-Store")
+
+	defer span.End()
+
+	query := `UPDATE inventory_unit
+	          SET vin = $2, color = $3, mileage_km = $4, status = COALESCE(NULLIF($5::VARCHAR, ''), status),
+	              dealership_id = COALESCE($6, dealership_id), updated_at = $7
+	          WHERE id = $1 AND ($5::VARCHAR = '' OR status = $5::VARCHAR OR status IN ('available', 'in_transit'))
+	          RETURNING ` + unitColumns
+
+	unit, err := scanUnit(i.db.QueryRowContext(ctx, query,
+		id,
+		models.NormalizeVIN(unitReq.VIN),
+		strings.TrimSpace(unitReq.Color),
+		unitReq.MileageKm,
+		string(unitReq.Status),
+		unitReq.DealershipID,
+		time.Now(),
+	))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := i.GetUnitById(ctx, id); err != nil {
+				return models.InventoryUnit{}, err
+			}
+			return models.InventoryUnit{}, models.ErrUnitInUse
+		}
+		return models.InventoryUnit{}, err
+	}
+
+	return unit, nil
+}
+
+// DeleteUnit refuses reserved and sold units and units an order refers to,
+// which would otherwise drop a customer's hold or break the order.
+func (i InventoryStore) DeleteUnit(ctx context.Context, id string) (models.InventoryUnit, error) {
+	tracer := otel.Tracer("InventoryStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteUnit-Store")
+
+	defer span.End()
+
+	unit, err := scanUnit(i.db.QueryRowContext(ctx,
+		"DELETE FROM inventory_unit WHERE id = $1 AND status IN ('available', 'in_transit') RETURNING "+unitColumns, id))
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return models.InventoryUnit{}, models.ErrUnitInUse
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := i.GetUnitById(ctx, id); err != nil {
+				return models.InventoryUnit{}, err
+			}
+			return models.InventoryUnit{}, models.ErrUnitInUse
+		}
+		return models.InventoryUnit{}, err
+	}
+
+	return unit, nil
+}
+
+// GetAvailability counts units per car and status in one query. Cars
+// without units are present in the result with zero counts.
+func (i InventoryStore) GetAvailability(ctx context.Context, carIDs []uuid.UUID) (map[uuid.UUID]models.Availability, error) {
+	tracer := otel.Tracer("InventoryStore")
+
+	ctx, span := tracer.Start(ctx, "GetAvailability-Store")
+
+	defer span.End()
+
+	availability := make(map[uuid.UUID]models.Availability, len(carIDs))
+
+	if len(carIDs) == 0 {
+		return availability, nil
+	}
+
+	ids := make([]string, 0, len(carIDs))
+	for _, id := range carIDs {
+		ids = append(ids, id.String())
+		availability[id] = models.Availability{}
+	}
+
+	rows, err := i.db.QueryContext(ctx, `SELECT car_id, status, COUNT(*)
+			FROM inventory_unit
+			WHERE car_id = ANY($1::uuid[])
+			GROUP BY car_id, status`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var carID uuid.UUID
+		var status models.StockStatus
+		var count int
+		if err := rows.Scan(&carID, &status, &count); err != nil {
+			return nil, err
+		}
+		counts := availability[carID]
+		counts.Add(status, count)
+		availability[carID] = counts
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return availability, nil
+}
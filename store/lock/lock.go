@@ -0,0 +1,51 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// ErrLocked is returned when another request holds the lock.
+var ErrLocked = errors.New("resource is locked by another request")
+
+// releaseScript deletes the lock only if it still carries our token, so a
+// request whose lock already expired cannot release someone else's.
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Acquire takes a Redis lock on key for at most ttl. It does not wait: if
+// the lock is held it returns ErrLocked. The returned release func must be
+// called once the protected work is done.
+func Acquire(ctx context.Context, redisClient *redis.Client, key string, ttl time.Duration) (func(), error) {
+	token := uuid.New().String()
+
+	ok, err := redisClient.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLocked
+	}
+
+	release := func() {
+		// Use a fresh context: the request context may already be cancelled.
+		if err := releaseScript.Run(context.Background(), redisClient, []string{key}, token).Err(); err != nil {
+			log.Println("Failed to release lock: ", err)
+		}
+	}
+	return release, nil
+}
+
+// UnitKey is the lock key guarding an inventory unit's stock status.
+func UnitKey(unitID string) string {
+	return "Lock:unit:" + unitID
+}
@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+// The queries below find what customers should be told about. Each event's
+// dedupe key names its source row, so scanning overlapping windows raises
+// the same event again without it being delivered twice.
+
+// GetPriceDropEvents finds price cuts since the given time on cars that
+// are on someone's wishlist, one event per customer and cut.
+func (s NotificationStore) GetPriceDropEvents(ctx context.Context, since time.Time) ([]models.NotificationEvent, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetPriceDropEvents-Store")
+
+	defer span.End()
+
+	query := `SELECT w.customer, h.id, c.id, c.name, h.old_price, h.new_price, h.new_currency, h.changed_at
+	          FROM car_price_history h
+	          JOIN car c ON c.id = h.car_id
+	          JOIN wishlist_item w ON w.car_id = h.car_id
+	          WHERE h.changed_at > $1
+	            AND h.old_currency = h.new_currency
+	            AND h.new_price < h.old_price
+	          ORDER BY h.changed_at`
+
+	rows, err := s.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectEvents(rows, func(row rowScanner) (models.NotificationEvent, error) {
+		var customer, carName, currency string
+		var historyID, carID uuid.UUID
+		var oldPrice, newPrice decimal.Decimal
+		var changedAt time.Time
+
+		if err := row.Scan(&customer, &historyID, &carID, &carName, &oldPrice, &newPrice, &currency, &changedAt); err != nil {
+			return models.NotificationEvent{}, err
+		}
+
+		return models.NotificationEvent{
+			Customer:  customer,
+			Kind:      models.NotificationPriceDrop,
+			DedupeKey: "price_drop:" + historyID.String() + ":" + customer,
+			Data: map[string]interface{}{
+				"car_id":     carID.String(),
+				"car_name":   carName,
+				"old_price":  models.NewMoney(oldPrice, currency).String(),
+				"new_price":  models.NewMoney(newPrice, currency).String(),
+				"changed_at": changedAt,
+			},
+		}, nil
+	})
+}
+
+// GetReservationExpiringEvents finds active holds that run out between
+// from and to.
+func (s NotificationStore) GetReservationExpiringEvents(ctx context.Context, from, to time.Time) ([]models.NotificationEvent, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetReservationExpiringEvents-Store")
+
+	defer span.End()
+
+	query := `SELECT r.id, r.customer, c.name, r.expires_at
+	          FROM reservation r
+	          JOIN inventory_unit u ON u.id = r.unit_id
+	          JOIN car c ON c.id = u.car_id
+	          WHERE r.status = $1 AND r.expires_at > $2 AND r.expires_at <= $3
+	          ORDER BY r.expires_at`
+
+	rows, err := s.db.QueryContext(ctx, query, models.ReservationStatusActive, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectEvents(rows, reservationEvent(models.NotificationReservationExpiring))
+}
+
+// GetReservationExpiredEvents finds holds that lapsed since the given
+// time.
+func (s NotificationStore) GetReservationExpiredEvents(ctx context.Context, since time.Time) ([]models.NotificationEvent, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetReservationExpiredEvents-Store")
+
+	defer span.End()
+
+	query := `SELECT r.id, r.customer, c.name, r.expires_at
+	          FROM reservation r
+	          JOIN inventory_unit u ON u.id = r.unit_id
+	          JOIN car c ON c.id = u.car_id
+	          WHERE r.status = $1 AND r.updated_at > $2
+	          ORDER BY r.updated_at`
+
+	rows, err := s.db.QueryContext(ctx, query, models.ReservationStatusExpired, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectEvents(rows, reservationEvent(models.NotificationReservationExpired))
+}
+
+func reservationEvent(kind models.NotificationKind) func(row rowScanner) (models.NotificationEvent, error) {
+	return func(row rowScanner) (models.NotificationEvent, error) {
+		var reservationID uuid.UUID
+		var customer, carName string
+		var expiresAt time.Time
+
+		if err := row.Scan(&reservationID, &customer, &carName, &expiresAt); err != nil {
+			return models.NotificationEvent{}, err
+		}
+
+		return models.NotificationEvent{
+			Customer:  customer,
+			Kind:      kind,
+			DedupeKey: string(kind) + ":" + reservationID.String(),
+			Data: map[string]interface{}{
+				"reservation_id": reservationID.String(),
+				"car_name":       carName,
+				"expires_at":     expiresAt,
+			},
+		}, nil
+	}
+}
+
+// GetTestDriveReminderEvents finds booked test drives starting between from
+// and to.
+func (s NotificationStore) GetTestDriveReminderEvents(ctx context.Context, from, to time.Time) ([]models.NotificationEvent, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetTestDriveReminderEvents-Store")
+
+	defer span.End()
+
+	query := `SELECT t.id, t.customer, c.name, d.name, d.address, t.starts_at
+	          FROM test_drive t
+	          JOIN car c ON c.id = t.car_id
+	          JOIN dealership d ON d.id = t.dealership_id
+	          WHERE t.status IN ($1, $2) AND t.starts_at > $3 AND t.starts_at <= $4
+	          ORDER BY t.starts_at`
+
+	rows, err := s.db.QueryContext(ctx, query, models.TestDriveStatusScheduled, models.TestDriveStatusConfirmed, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectEvents(rows, func(row rowScanner) (models.NotificationEvent, error) {
+		var testDriveID uuid.UUID
+		var customer, carName, dealershipName, address string
+		var startsAt time.Time
+
+		if err := row.Scan(&testDriveID, &customer, &carName, &dealershipName, &address, &startsAt); err != nil {
+			return models.NotificationEvent{}, err
+		}
+
+		return models.NotificationEvent{
+			Customer:  customer,
+			Kind:      models.NotificationTestDriveReminder,
+			DedupeKey: "test_drive_reminder:" + testDriveID.String(),
+			Data: map[string]interface{}{
+				"test_drive_id":      testDriveID.String(),
+				"car_name":           carName,
+				"dealership_name":    dealershipName,
+				"dealership_address": address,
+				"starts_at":          startsAt,
+			},
+		}, nil
+	})
+}
+
+func collectEvents(rows *sql.Rows, scan func(row rowScanner) (models.NotificationEvent, error)) ([]models.NotificationEvent, error) {
+	defer rows.Close()
+
+	events := []models.NotificationEvent{}
+
+	for rows.Next() {
+		event, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,411 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	deliveryColumns = "id, customer, kind, channel, dedupe_key, recipient, subject, body, status, attempts, next_attempt_at, last_error, sent_at, created_at, updated_at"
+	inboxColumns    = "id, customer, kind, subject, body, read_at, created_at"
+)
+
+type NotificationStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) NotificationStore {
+	return NotificationStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDelivery(row rowScanner) (models.NotificationDelivery, error) {
+	var delivery models.NotificationDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.Customer,
+		&delivery.Kind,
+		&delivery.Channel,
+		&delivery.DedupeKey,
+		&delivery.Recipient,
+		&delivery.Subject,
+		&delivery.Body,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.SentAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	return delivery, err
+}
+
+func collectDeliveries(rows *sql.Rows) ([]models.NotificationDelivery, error) {
+	defer rows.Close()
+
+	deliveries := []models.NotificationDelivery{}
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func scanInboxMessage(row rowScanner) (models.InboxMessage, error) {
+	var message models.InboxMessage
+	err := row.Scan(
+		&message.ID,
+		&message.Customer,
+		&message.Kind,
+		&message.Subject,
+		&message.Body,
+		&message.ReadAt,
+		&message.CreatedAt,
+	)
+	return message, err
+}
+
+func (s NotificationStore) GetTemplates(ctx context.Context) ([]models.NotificationTemplate, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetTemplates-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT kind, subject, body, updated_at FROM notification_template ORDER BY kind")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.NotificationTemplate{}
+
+	for rows.Next() {
+		var tmpl models.NotificationTemplate
+		if err := rows.Scan(&tmpl.Kind, &tmpl.Subject, &tmpl.Body, &tmpl.UpdatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (s NotificationStore) GetTemplate(ctx context.Context, kind models.NotificationKind) (models.NotificationTemplate, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetTemplate-Store")
+
+	defer span.End()
+
+	var tmpl models.NotificationTemplate
+
+	err := s.db.QueryRowContext(ctx, "SELECT kind, subject, body, updated_at FROM notification_template WHERE kind = $1", kind).
+		Scan(&tmpl.Kind, &tmpl.Subject, &tmpl.Body, &tmpl.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NotificationTemplate{}, models.ErrNotificationTemplateNotFound
+		}
+		return models.NotificationTemplate{}, err
+	}
+
+	return tmpl, nil
+}
+
+func (s NotificationStore) UpsertTemplate(ctx context.Context, kind models.NotificationKind, templateReq *models.NotificationTemplateRequest) (models.NotificationTemplate, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "UpsertTemplate-Store")
+
+	defer span.End()
+
+	query := `INSERT INTO notification_template (kind, subject, body, updated_at) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (kind) DO UPDATE SET subject = EXCLUDED.subject, body = EXCLUDED.body, updated_at = EXCLUDED.updated_at
+	          RETURNING kind, subject, body, updated_at`
+
+	var tmpl models.NotificationTemplate
+
+	err := s.db.QueryRowContext(ctx, query, kind, templateReq.Subject, templateReq.Body, time.Now()).
+		Scan(&tmpl.Kind, &tmpl.Subject, &tmpl.Body, &tmpl.UpdatedAt)
+	if err != nil {
+		return models.NotificationTemplate{}, err
+	}
+
+	return tmpl, nil
+}
+
+// GetPreferences returns the customer's preferences, or empty ones (every
+// kind on the default channels, no email) if none were saved.
+func (s NotificationStore) GetPreferences(ctx context.Context, customer string) (models.NotificationPreferences, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetPreferences-Store")
+
+	defer span.End()
+
+	preferences := models.NotificationPreferences{Customer: customer, Channels: models.NotificationChannels{}}
+
+	err := s.db.QueryRowContext(ctx,
+		"SELECT customer, email, channels, updated_at FROM notification_preference WHERE customer = $1", customer).
+		Scan(&preferences.Customer, &preferences.Email, &preferences.Channels, &preferences.UpdatedAt)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.NotificationPreferences{}, err
+	}
+
+	return preferences, nil
+}
+
+func (s NotificationStore) UpsertPreferences(ctx context.Context, customer string, preferencesReq *models.NotificationPreferencesRequest) (models.NotificationPreferences, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "UpsertPreferences-Store")
+
+	defer span.End()
+
+	channels := preferencesReq.Channels
+	if channels == nil {
+		channels = models.NotificationChannels{}
+	}
+
+	query := `INSERT INTO notification_preference (customer, email, channels, updated_at) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (customer) DO UPDATE SET email = EXCLUDED.email, channels = EXCLUDED.channels, updated_at = EXCLUDED.updated_at
+	          RETURNING customer, email, channels, updated_at`
+
+	var preferences models.NotificationPreferences
+
+	err := s.db.QueryRowContext(ctx, query, customer, preferencesReq.Email, channels, time.Now()).
+		Scan(&preferences.Customer, &preferences.Email, &preferences.Channels, &preferences.UpdatedAt)
+	if err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	return preferences, nil
+}
+
+// EnqueueDelivery queues the delivery unless one with the same dedupe key
+// and channel exists already. It reports whether it was queued.
+func (s NotificationStore) EnqueueDelivery(ctx context.Context, delivery models.NotificationDelivery) (bool, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "EnqueueDelivery-Store")
+
+	defer span.End()
+
+	query := `INSERT INTO notification_delivery (` + deliveryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (dedupe_key, channel) DO NOTHING`
+
+	result, err := s.db.ExecContext(ctx, query,
+		delivery.ID,
+		delivery.Customer,
+		delivery.Kind,
+		delivery.Channel,
+		delivery.DedupeKey,
+		delivery.Recipient,
+		delivery.Subject,
+		delivery.Body,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.SentAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ClaimDueDeliveries takes up to limit pending deliveries that are due and
+// counts the attempt. Their next attempt is pushed lease into the future,
+// so another worker will not pick them up while they are being sent, and
+// a worker that dies mid-send only delays them.
+func (s NotificationStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.NotificationDelivery, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "ClaimDueDeliveries-Store")
+
+	defer span.End()
+
+	query := `UPDATE notification_delivery
+	          SET attempts = attempts + 1, next_attempt_at = $2, updated_at = $1
+	          WHERE id IN (
+	              SELECT id FROM notification_delivery
+	              WHERE status = $3 AND next_attempt_at <= $1
+	              ORDER BY next_attempt_at
+	              LIMIT $4
+	              FOR UPDATE SKIP LOCKED
+	          )
+	          RETURNING ` + deliveryColumns
+
+	rows, err := s.db.QueryContext(ctx, query, now, now.Add(lease), models.DeliveryStatusPending, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectDeliveries(rows)
+}
+
+func (s NotificationStore) MarkDeliverySent(ctx context.Context, id uuid.UUID, at time.Time) error {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "MarkDeliverySent-Store")
+
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE notification_delivery SET status = $2, sent_at = $3, last_error = NULL, updated_at = $3 WHERE id = $1",
+		id, models.DeliveryStatusSent, at)
+	return err
+}
+
+// MarkDeliveryFailed records a failed attempt. The delivery stays pending
+// until nextAttempt, or is given up on when nextAttempt is nil.
+func (s NotificationStore) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttempt *time.Time, at time.Time) error {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "MarkDeliveryFailed-Store")
+
+	defer span.End()
+
+	status := models.DeliveryStatusPending
+	next := at
+	if nextAttempt == nil {
+		status = models.DeliveryStatusFailed
+	} else {
+		next = *nextAttempt
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE notification_delivery SET status = $2, last_error = $3, next_attempt_at = $4, updated_at = $5 WHERE id = $1",
+		id, status, lastError, next, at)
+	return err
+}
+
+// GetDeliveries lists the most recent deliveries, optionally only those in
+// one status.
+func (s NotificationStore) GetDeliveries(ctx context.Context, status *models.DeliveryStatus, limit int) ([]models.NotificationDelivery, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetDeliveries-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+deliveryColumns+" FROM notification_delivery WHERE ($1::text IS NULL OR status = $1) ORDER BY created_at DESC LIMIT $2",
+		status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectDeliveries(rows)
+}
+
+// AddInboxMessage stores an in-app delivery. A retried delivery keeps the
+// message it already added.
+func (s NotificationStore) AddInboxMessage(ctx context.Context, delivery models.NotificationDelivery) error {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "AddInboxMessage-Store")
+
+	defer span.End()
+
+	query := `INSERT INTO inbox_message (id, delivery_id, customer, kind, subject, body, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	          ON CONFLICT (delivery_id) DO NOTHING`
+
+	_, err := s.db.ExecContext(ctx, query,
+		uuid.New(), delivery.ID, delivery.Customer, delivery.Kind, delivery.Subject, delivery.Body, time.Now())
+	return err
+}
+
+// GetInbox lists the customer's in-app messages, newest first.
+func (s NotificationStore) GetInbox(ctx context.Context, customer string, unreadOnly bool) ([]models.InboxMessage, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "GetInbox-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+inboxColumns+" FROM inbox_message WHERE customer = $1 AND (NOT $2 OR read_at IS NULL) ORDER BY created_at DESC",
+		customer, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []models.InboxMessage{}
+
+	for rows.Next() {
+		message, err := scanInboxMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// MarkInboxMessageRead marks the customer's message read. Reading it again
+// keeps the first read time.
+func (s NotificationStore) MarkInboxMessageRead(ctx context.Context, id, customer string, at time.Time) (models.InboxMessage, error) {
+	tracer := otel.Tracer("NotificationStore")
+
+	ctx, span := tracer.Start(ctx, "MarkInboxMessageRead-Store")
+
+	defer span.End()
+
+	query := `UPDATE inbox_message SET read_at = COALESCE(read_at, $3)
+	          WHERE id = $1 AND customer = $2
+	          RETURNING ` + inboxColumns
+
+	message, err := scanInboxMessage(s.db.QueryRowContext(ctx, query, id, customer, at))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.InboxMessage{}, models.ErrInboxMessageNotFound
+		}
+		return models.InboxMessage{}, err
+	}
+
+	return message, nil
+}
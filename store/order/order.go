@@ -0,0 +1,380 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store/lock"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	orderColumns = "id, customer, unit_id, car_id, dealership_id, tax_region, price, discount, tax, total, amount_paid, currency, status, created_at, updated_at"
+
+	unitLockTTL = 10 * time.Second
+)
+
+type OrderStore struct {
+	db          *sql.DB
+	redisClient *redis.Client
+}
+
+func New(db *sql.DB, redisClient *redis.Client) OrderStore {
+	return OrderStore{db: db, redisClient: redisClient}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrder(row rowScanner) (models.Order, error) {
+	var order models.Order
+	var currency string
+	err := row.Scan(
+		&order.ID,
+		&order.Customer,
+		&order.UnitID,
+		&order.CarID,
+		&order.DealershipID,
+		&order.TaxRegion,
+		&order.Price.Amount,
+		&order.Discount.Amount,
+		&order.Tax.Amount,
+		&order.Total.Amount,
+		&order.AmountPaid.Amount,
+		&currency,
+		&order.Status,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	order.Price.Currency = currency
+	order.Discount.Currency = currency
+	order.Tax.Currency = currency
+	order.Total.Currency = currency
+	order.AmountPaid.Currency = currency
+	return order, err
+}
+
+func collectOrders(rows *sql.Rows) ([]models.Order, error) {
+	defer rows.Close()
+
+	orders := []models.Order{}
+
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// checkUnitFree makes sure the customer may take the unit: it must be on
+// sale, or reserved by this customer's own active reservation. The unit row
+// stays locked until the transaction ends.
+func checkUnitFree(ctx context.Context, tx *sql.Tx, unitID uuid.UUID, customer string) error {
+	var status models.StockStatus
+
+	err := tx.QueryRowContext(ctx, "SELECT status FROM inventory_unit WHERE id = $1 FOR UPDATE", unitID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Inventory unit ID not found")
+		}
+		return err
+	}
+
+	switch status {
+	case models.StockStatusAvailable:
+		return nil
+	case models.StockStatusReserved:
+		var held bool
+		err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM reservation WHERE unit_id = $1 AND customer = $2 AND status = $3)",
+			unitID, customer, models.ReservationStatusActive).Scan(&held)
+		if err != nil {
+			return err
+		}
+		if held {
+			return nil
+		}
+	}
+
+	return models.ErrUnitNotAvailable
+}
+
+// CreateOrder stores a draft order priced by the service. The car price is
+// read again under the unit lock; if it changed since the order was priced
+// the order is refused with models.ErrOrderPriceChanged. A draft does not
+// change stock; the unit is only held once confirmed.
+func (s OrderStore) CreateOrder(ctx context.Context, order models.Order) (created models.Order, err error) {
+	tracer := otel.Tracer("OrderStore")
+
+	ctx, span := tracer.Start(ctx, "CreateOrder-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = checkUnitFree(ctx, tx, order.UnitID, order.Customer)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	var price models.Money
+
+	err = tx.QueryRowContext(ctx, `SELECT c.id, u.dealership_id, c.price, c.currency
+			FROM inventory_unit u
+			JOIN car c ON u.car_id = c.id
+			WHERE u.id = $1`, order.UnitID).
+		Scan(&order.CarID, &order.DealershipID, &price.Amount, &price.Currency)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if price.Currency != order.Price.Currency || !price.Amount.Equal(order.Price.Amount) {
+		return models.Order{}, models.ErrOrderPriceChanged
+	}
+
+	order.AmountPaid = models.NewMoney(decimal.Zero, order.Price.Currency)
+
+	now := time.Now()
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO sales_order (`+orderColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
+		order.ID,
+		order.Customer,
+		order.UnitID,
+		order.CarID,
+		order.DealershipID,
+		order.TaxRegion,
+		order.Price.Amount,
+		order.Discount.Amount,
+		order.Tax.Amount,
+		order.Total.Amount,
+		order.AmountPaid.Amount,
+		order.Price.Currency,
+		order.Status,
+		order.CreatedAt,
+		order.UpdatedAt,
+	)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return order, nil
+}
+
+func (s OrderStore) GetOrderById(ctx context.Context, id string) (models.Order, error) {
+	tracer := otel.Tracer("OrderStore")
+
+	ctx, span := tracer.Start(ctx, "GetOrderByID-Store")
+
+	defer span.End()
+
+	order, err := scanOrder(s.db.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM sales_order WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Order{}, models.ErrOrderNotFound
+		}
+		return models.Order{}, err
+	}
+
+	return order, nil
+}
+
+func (s OrderStore) GetOrdersByCustomer(ctx context.Context, customer string) ([]models.Order, error) {
+	tracer := otel.Tracer("OrderStore")
+
+	ctx, span := tracer.Start(ctx, "GetOrdersByCustomer-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+orderColumns+" FROM sales_order WHERE customer = $1 ORDER BY created_at DESC", customer)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectOrders(rows)
+}
+
+func (s OrderStore) GetOrdersByDealership(ctx context.Context, dealershipID string) ([]models.Order, error) {
+	tracer := otel.Tracer("OrderStore")
+
+	ctx, span := tracer.Start(ctx, "GetOrdersByDealership-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+orderColumns+" FROM sales_order WHERE dealership_id = $1 ORDER BY created_at DESC", dealershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectOrders(rows)
+}
+
+// UpdateOrderStatus moves an order along its lifecycle and updates the
+// unit's stock status in the same transaction:
+//
+//	confirmed: unit becomes reserved (a customer's own reservation is converted)
+//	delivered: unit becomes sold
+//	cancelled: a unit held by the order goes back on sale
+func (s OrderStore) UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) (order models.Order, err error) {
+	tracer := otel.Tracer("OrderStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateOrderStatus-Store")
+
+	defer span.End()
+
+	current, err := s.GetOrderById(ctx, id)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	release, err := lock.Acquire(ctx, s.redisClient, lock.UnitKey(current.UnitID.String()), unitLockTTL)
+	if err != nil {
+		return models.Order{}, err
+	}
+	defer release()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	order, err = scanOrder(tx.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM sales_order WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrOrderNotFound
+		}
+		return models.Order{}, err
+	}
+
+	err = models.CanTransitionOrder(order.Status, status)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	now := time.Now()
+
+	switch {
+	case status == models.OrderStatusConfirmed:
+		err = checkUnitFree(ctx, tx, order.UnitID, order.Customer)
+		if err != nil {
+			return models.Order{}, err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE reservation SET status = $3, updated_at = $4 WHERE unit_id = $1 AND customer = $2 AND status = $5",
+			order.UnitID, order.Customer, models.ReservationStatusConverted, now, models.ReservationStatusActive)
+		if err != nil {
+			return models.Order{}, err
+		}
+		err = setUnitStatus(ctx, tx, order.UnitID, models.StockStatusReserved, now)
+
+	case status == models.OrderStatusDelivered:
+		err = setUnitStatus(ctx, tx, order.UnitID, models.StockStatusSold, now)
+
+	case status == models.OrderStatusCancelled && order.Status.HoldsUnit():
+		err = setUnitStatus(ctx, tx, order.UnitID, models.StockStatusAvailable, now)
+	}
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE sales_order SET status = $2, updated_at = $3 WHERE id = $1", id, status, now)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	order.Status = status
+	order.UpdatedAt = now
+
+	return order, nil
+}
+
+// UpdateOrderAmounts stores a new discount, tax and total on an order that
+// is still a draft.
+func (s OrderStore) UpdateOrderAmounts(ctx context.Context, id string, discount, tax, total models.Money) (order models.Order, err error) {
+	tracer := otel.Tracer("OrderStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateOrderAmounts-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	order, err = scanOrder(tx.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM sales_order WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrOrderNotFound
+		}
+		return models.Order{}, err
+	}
+
+	if order.Status != models.OrderStatusDraft {
+		err = fmt.Errorf("%w: the discount of a %s order cannot change", models.ErrInvalidOrderTransition, order.Status)
+		return models.Order{}, err
+	}
+
+	now := time.Now()
+
+	_, err = tx.ExecContext(ctx, "UPDATE sales_order SET discount = $2, tax = $3, total = $4, updated_at = $5 WHERE id = $1",
+		id, discount.Amount, tax.Amount, total.Amount, now)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	order.Discount = discount
+	order.Tax = tax
+	order.Total = total
+	order.UpdatedAt = now
+
+	return order, nil
+}
+
+func setUnitStatus(ctx context.Context, tx *sql.Tx, unitID uuid.UUID, status models.StockStatus, now time.Time) error {
+	_, err := tx.ExecContext(ctx, "UPDATE inventory_unit SET status = $2, updated_at = $3 WHERE id = $1", unitID, status, now)
+	return err
+}
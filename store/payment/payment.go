@@ -0,0 +1,328 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+const paymentColumns = "id, order_id, customer, kind, amount, captured, refunded, currency, status, provider, provider_ref, created_at, updated_at"
+
+type PaymentStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) PaymentStore {
+	return PaymentStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPayment(row rowScanner) (models.Payment, error) {
+	var payment models.Payment
+	var currency string
+	err := row.Scan(
+		&payment.ID,
+		&payment.OrderID,
+		&payment.Customer,
+		&payment.Kind,
+		&payment.Amount.Amount,
+		&payment.Captured.Amount,
+		&payment.Refunded.Amount,
+		&currency,
+		&payment.Status,
+		&payment.Provider,
+		&payment.ProviderRef,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+	payment.Amount.Currency = currency
+	payment.Captured.Currency = currency
+	payment.Refunded.Currency = currency
+	return payment, err
+}
+
+func collectPayments(rows *sql.Rows) ([]models.Payment, error) {
+	defer rows.Close()
+
+	payments := []models.Payment{}
+
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (s PaymentStore) CreatePayment(ctx context.Context, payment models.Payment) (err error) {
+	tracer := otel.Tracer("PaymentStore")
+
+	ctx, span := tracer.Start(ctx, "CreatePayment-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Re-check the balance under the order lock: a concurrent payment may
+	// have been stored since the service read it.
+	var total, paid, held decimal.Decimal
+	err = tx.QueryRowContext(ctx, "SELECT total, amount_paid FROM sales_order WHERE id = $1 FOR UPDATE", payment.OrderID).Scan(&total, &paid)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(SUM(amount - captured), 0) FROM payment WHERE order_id = $1 AND status IN ($2, $3)",
+		payment.OrderID, models.PaymentStatusPending, models.PaymentStatusAuthorized).Scan(&held)
+	if err != nil {
+		return err
+	}
+
+	outstanding := models.NewMoney(total.Sub(paid).Sub(held), payment.Amount.Currency)
+	if payment.Amount.Amount.GreaterThan(outstanding.Amount) {
+		err = fmt.Errorf("%w: amount exceeds %s", models.ErrInvalidPaymentAmount, outstanding)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO payment (`+paymentColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		payment.ID,
+		payment.OrderID,
+		payment.Customer,
+		payment.Kind,
+		payment.Amount.Amount,
+		payment.Captured.Amount,
+		payment.Refunded.Amount,
+		payment.Amount.Currency,
+		payment.Status,
+		payment.Provider,
+		payment.ProviderRef,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// A webhook may already have reported a capture before the insert.
+	err = reconcileOrder(ctx, tx, payment.OrderID)
+	return err
+}
+
+func (s PaymentStore) GetPaymentById(ctx context.Context, id string) (models.Payment, error) {
+	tracer := otel.Tracer("PaymentStore")
+
+	ctx, span := tracer.Start(ctx, "GetPaymentByID-Store")
+
+	defer span.End()
+
+	payment, err := scanPayment(s.db.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payment WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Payment{}, models.ErrPaymentNotFound
+		}
+		return models.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+func (s PaymentStore) GetPaymentsByOrder(ctx context.Context, orderID string) ([]models.Payment, error) {
+	tracer := otel.Tracer("PaymentStore")
+
+	ctx, span := tracer.Start(ctx, "GetPaymentsByOrder-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+paymentColumns+" FROM payment WHERE order_id = $1 ORDER BY created_at", orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectPayments(rows)
+}
+
+// GetOpenPayments lists pending and authorized payments not updated since
+// before, for reconciliation against the provider.
+func (s PaymentStore) GetOpenPayments(ctx context.Context, before time.Time) ([]models.Payment, error) {
+	tracer := otel.Tracer("PaymentStore")
+
+	ctx, span := tracer.Start(ctx, "GetOpenPayments-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+paymentColumns+" FROM payment WHERE status IN ($1, $2) AND updated_at < $3 ORDER BY updated_at",
+		models.PaymentStatusPending, models.PaymentStatusAuthorized, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectPayments(rows)
+}
+
+// ApplySnapshot merges the provider's view into the payment and
+// reconciles the order in one transaction.
+func (s PaymentStore) ApplySnapshot(ctx context.Context, id uuid.UUID, snapshot models.PaymentSnapshot) (payment models.Payment, err error) {
+	tracer := otel.Tracer("PaymentStore")
+
+	ctx, span := tracer.Start(ctx, "ApplySnapshot-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	payment, err = scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payment WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrPaymentNotFound
+		}
+		return models.Payment{}, err
+	}
+
+	err = applySnapshot(ctx, tx, &payment, snapshot)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+// ProcessWebhookEvent records the event and applies it. An event already
+// seen returns models.ErrDuplicateWebhookDelivery and changes nothing; the
+// event row and the payment update commit together, so a failed attempt
+// can be redelivered.
+func (s PaymentStore) ProcessWebhookEvent(ctx context.Context, provider string, event models.PaymentEvent) (payment models.Payment, err error) {
+	tracer := otel.Tracer("PaymentStore")
+
+	ctx, span := tracer.Start(ctx, "ProcessWebhookEvent-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO payment_webhook_event (provider, event_id, event_type, received_at)
+		VALUES ($1, $2, $3, $4) ON CONFLICT (provider, event_id) DO NOTHING`,
+		provider, event.ID, event.Type, time.Now())
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if inserted == 0 {
+		err = models.ErrDuplicateWebhookDelivery
+		return models.Payment{}, err
+	}
+
+	payment, err = scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payment WHERE provider = $1 AND provider_ref = $2 FOR UPDATE",
+		provider, event.Payment.ProviderRef))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrPaymentNotFound
+		}
+		return models.Payment{}, err
+	}
+
+	err = applySnapshot(ctx, tx, &payment, event.Payment)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+func applySnapshot(ctx context.Context, tx *sql.Tx, payment *models.Payment, snapshot models.PaymentSnapshot) error {
+	if !payment.Apply(snapshot) {
+		return nil
+	}
+
+	payment.UpdatedAt = time.Now()
+
+	_, err := tx.ExecContext(ctx, "UPDATE payment SET captured = $2, refunded = $3, status = $4, updated_at = $5 WHERE id = $1",
+		payment.ID, payment.Captured.Amount, payment.Refunded.Amount, payment.Status, payment.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return reconcileOrder(ctx, tx, payment.OrderID)
+}
+
+// reconcileOrder recomputes what has been paid on the order and marks a
+// confirmed order paid once the total is covered. Refunds lower the amount
+// paid but never move the order back; cancelling stays a manual step.
+func reconcileOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+	var status models.OrderStatus
+	var total, paid decimal.Decimal
+
+	err := tx.QueryRowContext(ctx, "SELECT status FROM sales_order WHERE id = $1 FOR UPDATE", orderID).Scan(&status)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `UPDATE sales_order
+			SET amount_paid = (SELECT COALESCE(SUM(captured - refunded), 0) FROM payment WHERE order_id = $1),
+				updated_at = $2
+			WHERE id = $1
+			RETURNING total, amount_paid`, orderID, time.Now()).Scan(&total, &paid)
+	if err != nil {
+		return err
+	}
+
+	if paid.LessThan(total) || models.CanTransitionOrder(status, models.OrderStatusPaid) != nil {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE sales_order SET status = $2 WHERE id = $1", orderID, models.OrderStatusPaid)
+	return err
+}
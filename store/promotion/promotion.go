@@ -0,0 +1,223 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const promotionColumns = "id, name, description, kind, value, currency, gift, brand, fuel_type, year_from, year_to, min_price, max_price, starts_at, ends_at, stackable, priority, active, created_at, updated_at"
+
+type PromotionStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) PromotionStore {
+	return PromotionStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromotion(row rowScanner) (models.Promotion, error) {
+	var promotion models.Promotion
+	err := row.Scan(
+		&promotion.ID,
+		&promotion.Name,
+		&promotion.Description,
+		&promotion.Kind,
+		&promotion.Value,
+		&promotion.Currency,
+		&promotion.Gift,
+		&promotion.Brand,
+		&promotion.FuelType,
+		&promotion.YearFrom,
+		&promotion.YearTo,
+		&promotion.MinPrice,
+		&promotion.MaxPrice,
+		&promotion.StartsAt,
+		&promotion.EndsAt,
+		&promotion.Stackable,
+		&promotion.Priority,
+		&promotion.Active,
+		&promotion.CreatedAt,
+		&promotion.UpdatedAt,
+	)
+	return promotion, err
+}
+
+func collectPromotions(rows *sql.Rows) ([]models.Promotion, error) {
+	defer rows.Close()
+
+	promotions := []models.Promotion{}
+
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+// promotionArgs lists the request's columns in promotionColumns order,
+// from name to active.
+func promotionArgs(promotionReq *models.PromotionRequest) []interface{} {
+	return []interface{}{
+		strings.TrimSpace(promotionReq.Name),
+		strings.TrimSpace(promotionReq.Description),
+		promotionReq.Kind,
+		promotionReq.Value,
+		promotionReq.Currency,
+		promotionReq.Gift,
+		promotionReq.Brand,
+		promotionReq.FuelType,
+		promotionReq.YearFrom,
+		promotionReq.YearTo,
+		promotionReq.MinPrice,
+		promotionReq.MaxPrice,
+		promotionReq.StartsAt,
+		promotionReq.EndsAt,
+		promotionReq.Stackable,
+		promotionReq.Priority,
+		promotionReq.Active,
+	}
+}
+
+// GetPromotions lists every promotion, newest first.
+func (s PromotionStore) GetPromotions(ctx context.Context) ([]models.Promotion, error) {
+	tracer := otel.Tracer("PromotionStore")
+
+	ctx, span := tracer.Start(ctx, "GetPromotions-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+promotionColumns+" FROM promotion ORDER BY created_at DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	return collectPromotions(rows)
+}
+
+// GetLivePromotions lists the active promotions whose validity window
+// contains at.
+func (s PromotionStore) GetLivePromotions(ctx context.Context, at time.Time) ([]models.Promotion, error) {
+	tracer := otel.Tracer("PromotionStore")
+
+	ctx, span := tracer.Start(ctx, "GetLivePromotions-Store")
+
+	defer span.End()
+
+	query := "SELECT " + promotionColumns + ` FROM promotion
+		WHERE active
+		  AND (starts_at IS NULL OR starts_at <= $1)
+		  AND (ends_at IS NULL OR ends_at > $1)
+		ORDER BY priority DESC, id`
+
+	rows, err := s.db.QueryContext(ctx, query, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectPromotions(rows)
+}
+
+func (s PromotionStore) GetPromotionById(ctx context.Context, id string) (models.Promotion, error) {
+	tracer := otel.Tracer("PromotionStore")
+
+	ctx, span := tracer.Start(ctx, "GetPromotionByID-Store")
+
+	defer span.End()
+
+	promotion, err := scanPromotion(s.db.QueryRowContext(ctx, "SELECT "+promotionColumns+" FROM promotion WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Promotion{}, models.ErrPromotionNotFound
+		}
+		return models.Promotion{}, err
+	}
+
+	return promotion, nil
+}
+
+func (s PromotionStore) CreatePromotion(ctx context.Context, promotionReq *models.PromotionRequest) (models.Promotion, error) {
+	tracer := otel.Tracer("PromotionStore")
+
+	ctx, span := tracer.Start(ctx, "CreatePromotion-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	args := append([]interface{}{uuid.New()}, promotionArgs(promotionReq)...)
+	args = append(args, now, now)
+
+	query := `INSERT INTO promotion (` + promotionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		RETURNING ` + promotionColumns
+
+	return scanPromotion(s.db.QueryRowContext(ctx, query, args...))
+}
+
+func (s PromotionStore) UpdatePromotion(ctx context.Context, id string, promotionReq *models.PromotionRequest) (models.Promotion, error) {
+	tracer := otel.Tracer("PromotionStore")
+
+	ctx, span := tracer.Start(ctx, "UpdatePromotion-Store")
+
+	defer span.End()
+
+	args := append([]interface{}{id}, promotionArgs(promotionReq)...)
+	args = append(args, time.Now())
+
+	query := `UPDATE promotion
+	          SET name = $2, description = $3, kind = $4, value = $5, currency = $6, gift = $7, brand = $8, fuel_type = $9,
+	              year_from = $10, year_to = $11, min_price = $12, max_price = $13, starts_at = $14, ends_at = $15,
+	              stackable = $16, priority = $17, active = $18, updated_at = $19
+	          WHERE id = $1
+	          RETURNING ` + promotionColumns
+
+	promotion, err := scanPromotion(s.db.QueryRowContext(ctx, query, args...))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Promotion{}, models.ErrPromotionNotFound
+		}
+		return models.Promotion{}, err
+	}
+
+	return promotion, nil
+}
+
+func (s PromotionStore) DeletePromotion(ctx context.Context, id string) (models.Promotion, error) {
+	tracer := otel.Tracer("PromotionStore")
+
+	ctx, span := tracer.Start(ctx, "DeletePromotion-Store")
+
+	defer span.End()
+
+	promotion, err := scanPromotion(s.db.QueryRowContext(ctx, "DELETE FROM promotion WHERE id = $1 RETURNING "+promotionColumns, id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Promotion{}, models.ErrPromotionNotFound
+		}
+		return models.Promotion{}, err
+	}
+
+	return promotion, nil
+}
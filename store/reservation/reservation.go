@@ -0,0 +1,295 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/NhutNam2904/carzone/store/lock"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	reservationColumns = "id, unit_id, customer, status, expires_at, created_at, updated_at"
+
+	// unitLockTTL bounds how long a crashed request can block a unit.
+	unitLockTTL = 10 * time.Second
+)
+
+type ReservationStore struct {
+	db          *sql.DB
+	redisClient *redis.Client
+}
+
+func New(db *sql.DB, redisClient *redis.Client) ReservationStore {
+	return ReservationStore{db: db, redisClient: redisClient}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReservation(row rowScanner) (models.Reservation, error) {
+	var reservation models.Reservation
+	err := row.Scan(
+		&reservation.ID,
+		&reservation.UnitID,
+		&reservation.Customer,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	return reservation, err
+}
+
+// CreateReservation holds a unit for the customer until expiresAt. The Redis
+// lock serializes concurrent requests for the same unit across instances;
+// the row lock and the partial unique index keep the database consistent
+// even if the lock expires early.
+func (s ReservationStore) CreateReservation(ctx context.Context, customer string, unitID uuid.UUID, expiresAt time.Time) (reservation models.Reservation, err error) {
+	tracer := otel.Tracer("ReservationStore")
+
+	ctx, span := tracer.Start(ctx, "CreateReservation-Store")
+
+	defer span.End()
+
+	release, err := lock.Acquire(ctx, s.redisClient, lock.UnitKey(unitID.String()), unitLockTTL)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	defer release()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var status models.StockStatus
+
+	err = tx.QueryRowContext(ctx, "SELECT status FROM inventory_unit WHERE id = $1 FOR UPDATE", unitID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errors.New("Inventory unit ID not found")
+		}
+		return models.Reservation{}, err
+	}
+
+	if status != models.StockStatusAvailable {
+		err = models.ErrUnitNotAvailable
+		return models.Reservation{}, err
+	}
+
+	now := time.Now()
+
+	reservation = models.Reservation{
+		ID:        uuid.New(),
+		UnitID:    unitID,
+		Customer:  customer,
+		Status:    models.ReservationStatusActive,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO reservation (`+reservationColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		reservation.ID,
+		reservation.UnitID,
+		reservation.Customer,
+		reservation.Status,
+		reservation.ExpiresAt,
+		reservation.CreatedAt,
+		reservation.UpdatedAt,
+	)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE inventory_unit SET status = $2, updated_at = $3 WHERE id = $1",
+		unitID, models.StockStatusReserved, now)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+func (s ReservationStore) GetReservationById(ctx context.Context, id string) (models.Reservation, error) {
+	tracer := otel.Tracer("ReservationStore")
+
+	ctx, span := tracer.Start(ctx, "GetReservationByID-Store")
+
+	defer span.End()
+
+	reservation, err := scanReservation(s.db.QueryRowContext(ctx, "SELECT "+reservationColumns+" FROM reservation WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Reservation{}, models.ErrReservationNotFound
+		}
+		return models.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+func (s ReservationStore) GetReservationsByCustomer(ctx context.Context, customer string) ([]models.Reservation, error) {
+	tracer := otel.Tracer("ReservationStore")
+
+	ctx, span := tracer.Start(ctx, "GetReservationsByCustomer-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+reservationColumns+" FROM reservation WHERE customer = $1 ORDER BY created_at DESC", customer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []models.Reservation{}
+
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// CancelReservation ends an active hold early and puts the unit back on
+// sale. Only the customer who made the reservation may cancel it.
+func (s ReservationStore) CancelReservation(ctx context.Context, id, customer string) (reservation models.Reservation, err error) {
+	tracer := otel.Tracer("ReservationStore")
+
+	ctx, span := tracer.Start(ctx, "CancelReservation-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	reservation, err = scanReservation(tx.QueryRowContext(ctx,
+		"SELECT "+reservationColumns+" FROM reservation WHERE id = $1 AND customer = $2 FOR UPDATE", id, customer))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrReservationNotFound
+		}
+		return models.Reservation{}, err
+	}
+
+	if reservation.Status != models.ReservationStatusActive {
+		err = models.ErrReservationInactive
+		return models.Reservation{}, err
+	}
+
+	now := time.Now()
+
+	_, err = tx.ExecContext(ctx, "UPDATE reservation SET status = $2, updated_at = $3 WHERE id = $1",
+		id, models.ReservationStatusCancelled, now)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE inventory_unit SET status = $2, updated_at = $3 WHERE id = $1 AND status = $4",
+		reservation.UnitID, models.StockStatusAvailable, now, models.StockStatusReserved)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	reservation.Status = models.ReservationStatusCancelled
+	reservation.UpdatedAt = now
+
+	return reservation, nil
+}
+
+// ExpireReservations marks every active hold past its expiry as expired and
+// releases the units. SKIP LOCKED lets several instances run the worker
+// without blocking each other or a concurrent cancel.
+func (s ReservationStore) ExpireReservations(ctx context.Context, now time.Time) (expired int, err error) {
+	tracer := otel.Tracer("ReservationStore")
+
+	ctx, span := tracer.Start(ctx, "ExpireReservations-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	rows, err := tx.QueryContext(ctx, `UPDATE reservation SET status = $2, updated_at = $1
+			WHERE id IN (
+				SELECT id FROM reservation
+				WHERE status = $3 AND expires_at <= $1
+				FOR UPDATE SKIP LOCKED)
+			RETURNING unit_id`,
+		now, models.ReservationStatusExpired, models.ReservationStatusActive)
+	if err != nil {
+		return 0, err
+	}
+
+	var unitIDs []string
+	for rows.Next() {
+		var unitID string
+		if err = rows.Scan(&unitID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		unitIDs = append(unitIDs, unitID)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(unitIDs) == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE inventory_unit SET status = $2, updated_at = $3 WHERE id = ANY($1::uuid[]) AND status = $4",
+		pq.Array(unitIDs), models.StockStatusAvailable, now, models.StockStatusReserved)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(unitIDs), nil
+}
@@ -0,0 +1,277 @@
+package savedsearch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const savedSearchColumns = "id, customer, name, criteria, last_evaluated_at, created_at, updated_at"
+
+type SavedSearchStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) SavedSearchStore {
+	return SavedSearchStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSavedSearch(row rowScanner) (models.SavedSearch, error) {
+	var search models.SavedSearch
+	err := row.Scan(
+		&search.ID,
+		&search.Customer,
+		&search.Name,
+		&search.Criteria,
+		&search.LastEvaluatedAt,
+		&search.CreatedAt,
+		&search.UpdatedAt,
+	)
+	return search, err
+}
+
+func collectSavedSearches(rows *sql.Rows) ([]models.SavedSearch, error) {
+	defer rows.Close()
+
+	searches := []models.SavedSearch{}
+
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+// GetSavedSearches lists every saved search, for the evaluator.
+func (s SavedSearchStore) GetSavedSearches(ctx context.Context) ([]models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchStore")
+
+	ctx, span := tracer.Start(ctx, "GetSavedSearches-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+savedSearchColumns+" FROM saved_search ORDER BY last_evaluated_at")
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSavedSearches(rows)
+}
+
+func (s SavedSearchStore) GetSavedSearchesByCustomer(ctx context.Context, customer string) ([]models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchStore")
+
+	ctx, span := tracer.Start(ctx, "GetSavedSearchesByCustomer-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+savedSearchColumns+" FROM saved_search WHERE customer = $1 ORDER BY created_at DESC", customer)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSavedSearches(rows)
+}
+
+func (s SavedSearchStore) GetSavedSearchById(ctx context.Context, id string) (models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchStore")
+
+	ctx, span := tracer.Start(ctx, "GetSavedSearchByID-Store")
+
+	defer span.End()
+
+	search, err := scanSavedSearch(s.db.QueryRowContext(ctx, "SELECT "+savedSearchColumns+" FROM saved_search WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SavedSearch{}, models.ErrSavedSearchNotFound
+		}
+		return models.SavedSearch{}, err
+	}
+
+	return search, nil
+}
+
+// CreateSavedSearch stores the search as evaluated now, so only cars
+// created or updated from here on are matched against it.
+func (s SavedSearchStore) CreateSavedSearch(ctx context.Context, customer string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchStore")
+
+	ctx, span := tracer.Start(ctx, "CreateSavedSearch-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	query := `INSERT INTO saved_search (` + savedSearchColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $5, $5)
+		RETURNING ` + savedSearchColumns
+
+	return scanSavedSearch(s.db.QueryRowContext(ctx, query,
+		uuid.New(), customer, strings.TrimSpace(searchReq.Name), searchReq.Criteria, now))
+}
+
+// UpdateSavedSearch replaces the name and criteria. Matches found under the
+// old criteria are dropped.
+func (s SavedSearchStore) UpdateSavedSearch(ctx context.Context, id string, searchReq *models.SavedSearchRequest) (search models.SavedSearch, err error) {
+	tracer := otel.Tracer("SavedSearchStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateSavedSearch-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `UPDATE saved_search
+	          SET name = $2, criteria = $3, updated_at = $4
+	          WHERE id = $1
+	          RETURNING ` + savedSearchColumns
+
+	search, err = scanSavedSearch(tx.QueryRowContext(ctx, query, id, strings.TrimSpace(searchReq.Name), searchReq.Criteria, time.Now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrSavedSearchNotFound
+		}
+		return models.SavedSearch{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM saved_search_match WHERE search_id = $1", id)
+	if err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	return search, nil
+}
+
+func (s SavedSearchStore) DeleteSavedSearch(ctx context.Context, id string) (models.SavedSearch, error) {
+	tracer := otel.Tracer("SavedSearchStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteSavedSearch-Store")
+
+	defer span.End()
+
+	search, err := scanSavedSearch(s.db.QueryRowContext(ctx, "DELETE FROM saved_search WHERE id = $1 RETURNING "+savedSearchColumns, id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SavedSearch{}, models.ErrSavedSearchNotFound
+		}
+		return models.SavedSearch{}, err
+	}
+
+	return search, nil
+}
+
+// GetMatches lists the cars that met the search, most recent match first.
+func (s SavedSearchStore) GetMatches(ctx context.Context, searchID string) ([]models.SavedSearchMatch, error) {
+	tracer := otel.Tracer("SavedSearchStore")
+
+	ctx, span := tracer.Start(ctx, "GetMatches-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT search_id, car_id, matched_at FROM saved_search_match WHERE search_id = $1 ORDER BY matched_at DESC", searchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.SavedSearchMatch{}
+
+	for rows.Next() {
+		var match models.SavedSearchMatch
+		if err := rows.Scan(&match.SearchID, &match.CarID, &match.MatchedAt); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// RecordEvaluation stores the cars that matched in one evaluation and moves
+// the search's cursor to evaluatedAt. A car matching again, e.g. after a
+// price change, has its matched_at refreshed. The cursor only moves forward
+// and the search may have been deleted meanwhile, in which case nothing is
+// stored.
+func (s SavedSearchStore) RecordEvaluation(ctx context.Context, searchID uuid.UUID, carIDs []uuid.UUID, evaluatedAt time.Time) (err error) {
+	tracer := otel.Tracer("SavedSearchStore")
+
+	ctx, span := tracer.Start(ctx, "RecordEvaluation-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var result sql.Result
+
+	result, err = tx.ExecContext(ctx,
+		"UPDATE saved_search SET last_evaluated_at = $2 WHERE id = $1 AND last_evaluated_at < $2", searchID, evaluatedAt)
+	if err != nil {
+		return err
+	}
+
+	var affected int64
+
+	affected, err = result.RowsAffected()
+	if err != nil || affected == 0 {
+		return err
+	}
+
+	query := `INSERT INTO saved_search_match (search_id, car_id, matched_at) VALUES ($1, $2, $3)
+	          ON CONFLICT (search_id, car_id) DO UPDATE SET matched_at = EXCLUDED.matched_at`
+
+	for _, carID := range carIDs {
+		_, err = tx.ExecContext(ctx, query, searchID, carID, evaluatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -4,12 +4,6 @@
 ALTER TABLE IF EXISTS car
 DROP CONSTRAINT IF EXISTS fk_engine_id;
 
--- Truncate car table to clear existing data
-TRUNCATE TABLE car;
-
--- Truncate engine table to clear existing data
-TRUNCATE TABLE engine;    
-
 -- Create engine table
 CREATE TABLE IF NOT EXISTS engine (
     id UUID PRIMARY KEY,
@@ -46,12 +40,681 @@ VALUES
     ('e1f86b1a-0873-4c19-bae2-fc60329d0140', 2000, 4, 600),
     ('f4a9c66b-8e38-419b-93c4-215d5cefb318', 1600, 4, 550),
     ('cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 3000, 6, 700),
-    ('9746be12-07b7-42a3-b8ab-7d1f209b63d7', 1800, 4, 500);
+    ('9746be12-07b7-42a3-b8ab-7d1f209b63d7', 1800, 4, 500)
+ON CONFLICT (id) DO NOTHING;
 
 -- Insert dummy data into the car table
 INSERT INTO car (id, name, year, brand, fuel_type, engine_id, price)
 VALUES
-    ('c7c1a6d5-1ec4-4c64-a59a-8a2f6f3d2bf3', 'Honda Civic', '2023', 'Honda', 'Gasoline', 'e1f86b1a-0873-4c19-bae2-fc60329d0140', 25000.00),
-    ('9d6a56f8-79c3-4931-a5c0-6b290c84ba2f', 'Toyota Corolla', '2022', 'Toyota', 'Gasoline', 'f4a9c66b-8e38-419b-93c4-215d5cefb318', 22000.00),
-    ('9b9437c4-3ed1-45a5-b240-0fe3e24e0e4e', 'Ford Mustang', '2024', 'Ford', 'Gasoline', 'cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 40000.00),
-    ('5e9df51a-8d7a-4d84-9c58-4ccfe5c7db06', 'BMW 3 Series', '2023', 'BMW', 'Gasoline', '9746be12-07b7-42a3-b8ab-7d1f209b63d7', 35000.00);
\ No newline at end of file
+    ('c7c1a6d5-1ec4-4c64-a59a-8a2f6f3d2bf3', 'Honda Civic', '2023', 'Honda', 'petrol', 'e1f86b1a-0873-4c19-bae2-fc60329d0140', 25000.00),
+    ('9d6a56f8-79c3-4931-a5c0-6b290c84ba2f', 'Toyota Corolla', '2022', 'Toyota', 'petrol', 'f4a9c66b-8e38-419b-93c4-215d5cefb318', 22000.00),
+    ('9b9437c4-3ed1-45a5-b240-0fe3e24e0e4e', 'Ford Mustang', '2024', 'Ford', 'petrol', 'cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 40000.00),
+    ('5e9df51a-8d7a-4d84-9c58-4ccfe5c7db06', 'BMW 3 Series', '2023', 'BMW', 'petrol', '9746be12-07b7-42a3-b8ab-7d1f209b63d7', 35000.00)
+ON CONFLICT (id) DO NOTHING;
+
+-- Full-text and fuzzy search over car name, brand and fuel type
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_car_search
+ON car USING GIN (to_tsvector('simple', name || ' ' || brand || ' ' || fuel_type));
+
+CREATE INDEX IF NOT EXISTS idx_car_name_trgm ON car USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_car_brand_trgm ON car USING GIN (brand gin_trgm_ops);
+
+-- Brand and model catalog
+CREATE TABLE IF NOT EXISTS brand (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    country_of_origin VARCHAR(100) NOT NULL DEFAULT '',
+    logo_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_brand_name_key ON brand (lower(name));
+
+CREATE TABLE IF NOT EXISTS model (
+    id UUID PRIMARY KEY,
+    brand_id UUID NOT NULL REFERENCES brand(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_model_brand_name_key ON model (brand_id, lower(name));
+
+ALTER TABLE car ADD COLUMN IF NOT EXISTS brand_id UUID REFERENCES brand(id);
+ALTER TABLE car ADD COLUMN IF NOT EXISTS model_id UUID REFERENCES model(id);
+
+-- Deduplicate free-text brands: "Toyota", "toyota" and "TOYOTA " collapse to
+-- one brand row named after the most common spelling.
+INSERT INTO brand (id, name)
+SELECT gen_random_uuid(), spelling
+FROM (
+    SELECT DISTINCT ON (lower(spelling)) spelling
+    FROM (
+        SELECT regexp_replace(trim(brand), '\s+', ' ', 'g') AS spelling, COUNT(*) AS uses
+        FROM car
+        GROUP BY 1
+    ) spellings
+    ORDER BY lower(spelling), uses DESC, spelling
+) canonical
+ON CONFLICT ((lower(name))) DO NOTHING;
+
+UPDATE car c
+SET brand_id = b.id, brand = b.name
+FROM brand b
+WHERE lower(regexp_replace(trim(c.brand), '\s+', ' ', 'g')) = lower(b.name)
+  AND (c.brand_id IS DISTINCT FROM b.id OR c.brand <> b.name);
+
+-- Models are derived from the car name with the brand prefix removed,
+-- so "Honda Civic" becomes model "Civic" under brand "Honda".
+INSERT INTO model (id, brand_id, name)
+SELECT gen_random_uuid(), brand_id, model_name
+FROM (
+    SELECT DISTINCT ON (c.brand_id, lower(m.model_name)) c.brand_id, m.model_name
+    FROM car c
+    JOIN brand b ON b.id = c.brand_id
+    CROSS JOIN LATERAL (
+        SELECT CASE
+            WHEN lower(regexp_replace(trim(c.name), '\s+', ' ', 'g')) LIKE lower(b.name) || ' %'
+            THEN substr(regexp_replace(trim(c.name), '\s+', ' ', 'g'), length(b.name) + 2)
+            ELSE regexp_replace(trim(c.name), '\s+', ' ', 'g')
+        END AS model_name
+    ) m
+    WHERE c.model_id IS NULL
+    ORDER BY c.brand_id, lower(m.model_name), m.model_name
+) derived
+ON CONFLICT (brand_id, (lower(name))) DO NOTHING;
+
+UPDATE car c
+SET model_id = m.id
+FROM brand b, model m
+WHERE b.id = c.brand_id
+  AND m.brand_id = c.brand_id
+  AND c.model_id IS NULL
+  AND lower(m.name) = lower(CASE
+        WHEN lower(regexp_replace(trim(c.name), '\s+', ' ', 'g')) LIKE lower(b.name) || ' %'
+        THEN substr(regexp_replace(trim(c.name), '\s+', ' ', 'g'), length(b.name) + 2)
+        ELSE regexp_replace(trim(c.name), '\s+', ' ', 'g')
+    END);
+
+-- Fuel types: map legacy free-text values onto the canonical set used by
+-- models.FuelType, then enforce it.
+UPDATE car
+SET fuel_type = CASE lower(regexp_replace(trim(fuel_type), '\s+', ' ', 'g'))
+    WHEN 'petrol' THEN 'petrol'
+    WHEN 'gasoline' THEN 'petrol'
+    WHEN 'gas' THEN 'petrol'
+    WHEN 'persol' THEN 'petrol'
+    WHEN 'diesel' THEN 'diesel'
+    WHEN 'electric' THEN 'electric'
+    WHEN 'ev' THEN 'electric'
+    WHEN 'bev' THEN 'electric'
+    WHEN 'hybrid' THEN 'hybrid'
+    WHEN 'hev' THEN 'hybrid'
+    WHEN 'plug_in_hybrid' THEN 'plug_in_hybrid'
+    WHEN 'plug-in hybrid' THEN 'plug_in_hybrid'
+    WHEN 'plug in hybrid' THEN 'plug_in_hybrid'
+    WHEN 'plugin hybrid' THEN 'plug_in_hybrid'
+    WHEN 'phev' THEN 'plug_in_hybrid'
+    WHEN 'hydrogen' THEN 'hydrogen'
+    WHEN 'fuel cell' THEN 'hydrogen'
+    WHEN 'fcev' THEN 'hydrogen'
+    WHEN 'lpg' THEN 'lpg'
+    WHEN 'autogas' THEN 'lpg'
+    ELSE fuel_type
+END
+WHERE fuel_type NOT IN ('petrol', 'diesel', 'electric', 'hybrid', 'plug_in_hybrid', 'hydrogen', 'lpg');
+
+ALTER TABLE car
+DROP CONSTRAINT IF EXISTS chk_car_fuel_type;
+
+ALTER TABLE car
+ADD CONSTRAINT chk_car_fuel_type
+CHECK (fuel_type IN ('petrol', 'diesel', 'electric', 'hybrid', 'plug_in_hybrid', 'hydrogen', 'lpg'));
+
+-- Powertrain: electric, hybrid and fuel cell engines carry motor and battery
+-- specs; combustion engines keep these at zero.
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS powertrain VARCHAR(20) NOT NULL DEFAULT 'combustion';
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS battery_kwh NUMERIC(6, 2) NOT NULL DEFAULT 0;
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS motor_kw NUMERIC(7, 2) NOT NULL DEFAULT 0;
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS charging_standards TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS wltp_range_km INT NOT NULL DEFAULT 0;
+
+ALTER TABLE engine
+DROP CONSTRAINT IF EXISTS chk_engine_powertrain;
+
+ALTER TABLE engine
+ADD CONSTRAINT chk_engine_powertrain
+CHECK (powertrain IN ('combustion', 'electric', 'hybrid', 'fuel_cell'));
+
+-- Performance and emissions, stored in SI units (kW, Nm, g CO2/km).
+-- Zero or an empty transmission means the figure is unknown.
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS power_kw NUMERIC(7, 2) NOT NULL DEFAULT 0;
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS torque_nm NUMERIC(7, 2) NOT NULL DEFAULT 0;
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS transmission VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS co2_g_per_km NUMERIC(6, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE engine
+DROP CONSTRAINT IF EXISTS chk_engine_transmission;
+
+ALTER TABLE engine
+ADD CONSTRAINT chk_engine_transmission
+CHECK (transmission IN ('', 'manual', 'automatic', 'cvt', 'dct', 'amt', 'single_speed'));
+
+-- Prices: exact decimal amounts wide enough for VND, with an ISO 4217
+-- currency code per car.
+ALTER TABLE car ALTER COLUMN price TYPE NUMERIC(20, 4);
+ALTER TABLE car ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE car
+DROP CONSTRAINT IF EXISTS chk_car_currency;
+
+ALTER TABLE car
+ADD CONSTRAINT chk_car_currency
+CHECK (currency ~ '^[A-Z]{3}$');
+
+-- Exchange rates used to show car prices in other currencies.
+CREATE TABLE IF NOT EXISTS exchange_rate (
+    base VARCHAR(3) NOT NULL,
+    quote VARCHAR(3) NOT NULL,
+    rate NUMERIC(24, 10) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base, quote)
+);
+
+-- Price history: one row per price change, written in the same transaction
+-- as the car update.
+CREATE TABLE IF NOT EXISTS car_price_history (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    old_price NUMERIC(20, 4) NOT NULL,
+    old_currency VARCHAR(3) NOT NULL,
+    new_price NUMERIC(20, 4) NOT NULL,
+    new_currency VARCHAR(3) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_car_price_history_car ON car_price_history (car_id, changed_at DESC);
+
+-- Audit log of every create, update and delete on cars and engines.
+-- entity_id is text so entries outlive the rows they describe.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    before JSONB,
+    after JSONB,
+    diff JSONB NOT NULL DEFAULT '{}',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log (created_at DESC);
+
+-- Dealerships: where a car is physically located. Opening hours are a JSON
+-- array of {"day", "open", "close"} entries in local time.
+CREATE TABLE IF NOT EXISTS dealership (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    opening_hours JSONB NOT NULL DEFAULT '[]',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE car ADD COLUMN IF NOT EXISTS dealership_id UUID REFERENCES dealership(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_car_dealership ON car (dealership_id);
+
+-- Inventory: individual vehicles of a car listing, one row per VIN.
+CREATE TABLE IF NOT EXISTS inventory_unit (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    vin VARCHAR(17) NOT NULL UNIQUE,
+    color VARCHAR(50) NOT NULL,
+    mileage_km INT NOT NULL DEFAULT 0 CHECK (mileage_km >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'available'
+        CHECK (status IN ('available', 'reserved', 'sold', 'in_transit')),
+    dealership_id UUID REFERENCES dealership(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_unit_car ON inventory_unit (car_id, status);
+
+-- Reservations: time-limited holds on inventory units. The partial unique
+-- index backs up the Redis lock so a unit never has two active holds.
+CREATE TABLE IF NOT EXISTS reservation (
+    id UUID PRIMARY KEY,
+    unit_id UUID NOT NULL REFERENCES inventory_unit(id) ON DELETE CASCADE,
+    customer VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'cancelled', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reservation_active_unit ON reservation (unit_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_reservation_customer ON reservation (customer, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_reservation_expiry ON reservation (expires_at) WHERE status = 'active';
+
+-- Test drives: bookings of one inventory unit for a time slot. The
+-- exclusion constraint rejects overlapping active bookings on a unit even
+-- when two requests race past the application check.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS test_drive (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    unit_id UUID NOT NULL REFERENCES inventory_unit(id) ON DELETE CASCADE,
+    dealership_id UUID NOT NULL REFERENCES dealership(id) ON DELETE CASCADE,
+    customer VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'confirmed', 'completed', 'cancelled', 'no_show')),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL CHECK (ends_at > starts_at),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT excl_test_drive_unit_overlap EXCLUDE USING gist (
+        unit_id WITH =,
+        tstzrange(starts_at, ends_at) WITH &&
+    ) WHERE (status IN ('scheduled', 'confirmed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_drive_customer ON test_drive (customer, starts_at DESC);
+CREATE INDEX IF NOT EXISTS idx_test_drive_dealership ON test_drive (dealership_id, starts_at);
+
+-- Reservations can be turned into an order.
+ALTER TABLE reservation
+DROP CONSTRAINT IF EXISTS reservation_status_check;
+
+ALTER TABLE reservation
+ADD CONSTRAINT reservation_status_check
+CHECK (status IN ('active', 'cancelled', 'expired', 'converted'));
+
+-- Sales orders ("order" is a reserved word). Amounts share the currency of
+-- the car at the time of ordering. A unit can only be in one open order
+-- past draft.
+CREATE TABLE IF NOT EXISTS sales_order (
+    id UUID PRIMARY KEY,
+    customer VARCHAR(255) NOT NULL,
+    unit_id UUID NOT NULL REFERENCES inventory_unit(id),
+    car_id UUID NOT NULL REFERENCES car(id),
+    dealership_id UUID REFERENCES dealership(id) ON DELETE SET NULL,
+    price NUMERIC(20, 4) NOT NULL,
+    discount NUMERIC(20, 4) NOT NULL DEFAULT 0 CHECK (discount >= 0),
+    tax NUMERIC(20, 4) NOT NULL DEFAULT 0 CHECK (tax >= 0),
+    total NUMERIC(20, 4) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'confirmed', 'paid', 'delivered', 'cancelled')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sales_order_open_unit ON sales_order (unit_id) WHERE status IN ('confirmed', 'paid', 'delivered');
+CREATE INDEX IF NOT EXISTS idx_sales_order_customer ON sales_order (customer, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_sales_order_dealership ON sales_order (dealership_id, created_at DESC);
+
+-- Payments against sales orders. amount_paid is kept in step with the
+-- payments by reconciliation.
+ALTER TABLE sales_order
+ADD COLUMN IF NOT EXISTS amount_paid NUMERIC(20, 4) NOT NULL DEFAULT 0;
+
+-- The tax region an order's tax was worked out for, so it can be priced
+-- again when staff change the discount.
+ALTER TABLE sales_order
+ADD COLUMN IF NOT EXISTS tax_region VARCHAR(16) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS payment (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES sales_order(id),
+    customer VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('deposit', 'balance')),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    captured NUMERIC(20, 4) NOT NULL DEFAULT 0,
+    refunded NUMERIC(20, 4) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('pending', 'authorized', 'captured', 'refunded', 'failed', 'cancelled')),
+    provider VARCHAR(50) NOT NULL,
+    provider_ref VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_payment_provider_ref UNIQUE (provider, provider_ref),
+    CONSTRAINT chk_payment_amounts CHECK (captured <= amount AND refunded <= captured)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_order ON payment (order_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_payment_open ON payment (updated_at) WHERE status IN ('pending', 'authorized');
+
+-- Webhook events already applied, so redeliveries are ignored.
+CREATE TABLE IF NOT EXISTS payment_webhook_event (
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, event_id)
+);
+
+-- Loan products offered by lenders for financing quotes. Percentages are
+-- stored as given, e.g. 7.5 for 7.5%.
+CREATE TABLE IF NOT EXISTS lender_product (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    lender VARCHAR(255) NOT NULL,
+    apr NUMERIC(7, 4) NOT NULL CHECK (apr >= 0 AND apr <= 100),
+    min_term_months INT NOT NULL CHECK (min_term_months >= 1),
+    max_term_months INT NOT NULL CHECK (max_term_months <= 120),
+    min_down_payment_percent NUMERIC(7, 4) NOT NULL DEFAULT 0
+        CHECK (min_down_payment_percent >= 0 AND min_down_payment_percent < 100),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_lender_product_terms CHECK (min_term_months <= max_term_months),
+    CONSTRAINT uq_lender_product_name UNIQUE (lender, name)
+);
+
+-- Depreciation curves for trade-in valuation: one per brand, plus a
+-- default (brand NULL) for brands without their own.
+CREATE TABLE IF NOT EXISTS depreciation_curve (
+    id UUID PRIMARY KEY,
+    brand VARCHAR(255),
+    points JSONB NOT NULL,
+    annual_mileage_km INT NOT NULL CHECK (annual_mileage_km > 0),
+    mileage_rate_per_1000_km NUMERIC(8, 6) NOT NULL DEFAULT 0,
+    max_mileage_adjustment NUMERIC(5, 4) NOT NULL DEFAULT 0,
+    condition_factors JSONB NOT NULL,
+    trade_in_margin_percent NUMERIC(7, 4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_depreciation_curve_brand ON depreciation_curve ((COALESCE(lower(brand), '')));
+
+INSERT INTO depreciation_curve (id, brand, points, annual_mileage_km, mileage_rate_per_1000_km, max_mileage_adjustment, condition_factors, trade_in_margin_percent)
+VALUES (
+    '6b1f3c2e-4d5a-4e8b-9c7d-0a1b2c3d4e5f',
+    NULL,
+    '[{"age_years": 0, "retention": "1.00"}, {"age_years": 1, "retention": "0.80"}, {"age_years": 2, "retention": "0.70"},
+      {"age_years": 3, "retention": "0.62"}, {"age_years": 5, "retention": "0.50"}, {"age_years": 8, "retention": "0.35"},
+      {"age_years": 12, "retention": "0.22"}, {"age_years": 20, "retention": "0.10"}]',
+    15000,
+    0.003,
+    0.25,
+    '{"excellent": "1.05", "good": "1.00", "fair": "0.88", "poor": "0.70"}',
+    12
+)
+ON CONFLICT ((COALESCE(lower(brand), ''))) DO NOTHING;
+
+-- Marketing promotions. Unset conditions match every car; price bounds
+-- and amounts off are in currency.
+CREATE TABLE IF NOT EXISTS promotion (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('percent_off', 'amount_off', 'gift')),
+    value NUMERIC(20, 4) NOT NULL DEFAULT 0 CHECK (value >= 0),
+    currency VARCHAR(3),
+    gift VARCHAR(255),
+    brand VARCHAR(255),
+    fuel_type VARCHAR(50),
+    year_from INT,
+    year_to INT,
+    min_price NUMERIC(20, 4),
+    max_price NUMERIC(20, 4),
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    priority INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_promotion_window CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at < ends_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_live ON promotion (priority DESC) WHERE active;
+
+-- Registration charges per province or city, for on-road prices. Fees are
+-- in VND; electric cars pay ev_registration_fee_percent.
+CREATE TABLE IF NOT EXISTS tax_region (
+    code VARCHAR(16) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    vat_percent NUMERIC(7, 4) NOT NULL CHECK (vat_percent BETWEEN 0 AND 100),
+    registration_fee_percent NUMERIC(7, 4) NOT NULL CHECK (registration_fee_percent BETWEEN 0 AND 100),
+    ev_registration_fee_percent NUMERIC(7, 4) NOT NULL CHECK (ev_registration_fee_percent BETWEEN 0 AND 100),
+    plate_fee NUMERIC(20, 0) NOT NULL DEFAULT 0 CHECK (plate_fee >= 0),
+    road_maintenance_fee NUMERIC(20, 0) NOT NULL DEFAULT 0 CHECK (road_maintenance_fee >= 0),
+    insurance_fee NUMERIC(20, 0) NOT NULL DEFAULT 0 CHECK (insurance_fee >= 0),
+    inspection_fee NUMERIC(20, 0) NOT NULL DEFAULT 0 CHECK (inspection_fee >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tax_region (code, name, vat_percent, registration_fee_percent, ev_registration_fee_percent, plate_fee, road_maintenance_fee, insurance_fee, inspection_fee)
+VALUES
+    ('HN', 'Hà Nội', 10, 12, 0, 20000000, 1560000, 480700, 340000),
+    ('HCM', 'TP. Hồ Chí Minh', 10, 10, 0, 20000000, 1560000, 480700, 340000),
+    ('HP', 'Hải Phòng', 10, 12, 0, 1000000, 1560000, 480700, 340000),
+    ('DN', 'Đà Nẵng', 10, 12, 0, 1000000, 1560000, 480700, 340000),
+    ('CT', 'Cần Thơ', 10, 12, 0, 1000000, 1560000, 480700, 340000),
+    ('OTHER', 'Tỉnh khác', 10, 10, 0, 200000, 1560000, 480700, 340000)
+ON CONFLICT (code) DO NOTHING;
+
+-- Special consumption tax by fuel type and engine displacement. A band
+-- covers min_displacement_cc (exclusive, except 0) to max_displacement_cc
+-- (inclusive, NULL for no bound); rules without a fuel type apply to fuels
+-- with no rules of their own.
+CREATE TABLE IF NOT EXISTS special_consumption_tax_rule (
+    id UUID PRIMARY KEY,
+    fuel_type VARCHAR(50),
+    min_displacement_cc BIGINT NOT NULL DEFAULT 0 CHECK (min_displacement_cc >= 0),
+    max_displacement_cc BIGINT,
+    rate_percent NUMERIC(7, 4) NOT NULL CHECK (rate_percent >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_sct_rule_band CHECK (max_displacement_cc IS NULL OR max_displacement_cc > min_displacement_cc)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sct_rule_band ON special_consumption_tax_rule ((COALESCE(fuel_type, '')), min_displacement_cc);
+
+-- Combustion bands, with hybrids at 70% and plug-in hybrids at 50% of the
+-- combustion rate.
+INSERT INTO special_consumption_tax_rule (id, fuel_type, min_displacement_cc, max_displacement_cc, rate_percent)
+SELECT gen_random_uuid(), fuel.fuel_type, band.min_cc, band.max_cc, band.rate * fuel.factor
+FROM (VALUES
+    (0, 1500, 35),
+    (1500, 2000, 40),
+    (2000, 2500, 50),
+    (2500, 3000, 60),
+    (3000, 4000, 90),
+    (4000, 5000, 110),
+    (5000, 6000, 130),
+    (6000, NULL, 150)
+) AS band (min_cc, max_cc, rate)
+CROSS JOIN (VALUES
+    (NULL, 1.0),
+    ('hybrid', 0.7),
+    ('plug_in_hybrid', 0.5)
+) AS fuel (fuel_type, factor)
+ON CONFLICT ((COALESCE(fuel_type, '')), min_displacement_cc) DO NOTHING;
+
+INSERT INTO special_consumption_tax_rule (id, fuel_type, min_displacement_cc, max_displacement_cc, rate_percent)
+VALUES (gen_random_uuid(), 'electric', 0, NULL, 3)
+ON CONFLICT ((COALESCE(fuel_type, '')), min_displacement_cc) DO NOTHING;
+
+-- Cars customers have favorited.
+CREATE TABLE IF NOT EXISTS wishlist_item (
+    customer VARCHAR(255) NOT NULL,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (customer, car_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_item_customer ON wishlist_item (customer, added_at DESC);
+
+-- Saved search filters. Cars changed after last_evaluated_at have not been
+-- checked against the search yet; the ones that matched are kept in
+-- saved_search_match.
+CREATE TABLE IF NOT EXISTS saved_search (
+    id UUID PRIMARY KEY,
+    customer VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    criteria JSONB NOT NULL,
+    last_evaluated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_customer ON saved_search (customer, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS saved_search_match (
+    search_id UUID NOT NULL REFERENCES saved_search(id) ON DELETE CASCADE,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    matched_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (search_id, car_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_car_updated_at ON car (updated_at);
+
+-- Notification templates, one per kind. Subject and body are Go
+-- text/template sources.
+CREATE TABLE IF NOT EXISTS notification_template (
+    kind VARCHAR(50) PRIMARY KEY,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO notification_template (kind, subject, body)
+VALUES
+    ('price_drop',
+     'Price drop: {{.car_name}} is now {{.new_price}}',
+     E'Good news! {{.car_name}} on your wishlist has dropped from {{.old_price}} to {{.new_price}}.\n'),
+    ('reservation_expiring',
+     'Your reservation for {{.car_name}} expires soon',
+     E'Your hold on {{.car_name}} expires at {{.expires_at.Format "02/01/2006 15:04"}}. Confirm your order before then to keep it.\n'),
+    ('reservation_expired',
+     'Your reservation for {{.car_name}} has expired',
+     E'Your hold on {{.car_name}} expired at {{.expires_at.Format "02/01/2006 15:04"}} and the car has been released. You can reserve it again if it is still available.\n'),
+    ('test_drive_reminder',
+     'Reminder: test drive of {{.car_name}} on {{.starts_at.Format "02/01/2006 15:04"}}',
+     E'See you at {{.dealership_name}}, {{.dealership_address}}, on {{.starts_at.Format "02/01/2006 15:04"}} for your test drive of {{.car_name}}.\n')
+ON CONFLICT (kind) DO NOTHING;
+
+-- Per-customer notification settings. channels maps a kind to its enabled
+-- channels; kinds missing from it use the defaults.
+CREATE TABLE IF NOT EXISTS notification_preference (
+    customer VARCHAR(255) PRIMARY KEY,
+    email VARCHAR(255),
+    channels JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Delivery queue. One row per event and channel; dedupe_key identifies the
+-- event so raising it again queues nothing.
+CREATE TABLE IF NOT EXISTS notification_delivery (
+    id UUID PRIMARY KEY,
+    customer VARCHAR(255) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'in_app')),
+    dedupe_key VARCHAR(255) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_notification_delivery_dedupe UNIQUE (dedupe_key, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_due ON notification_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_created ON notification_delivery (created_at DESC);
+
+-- In-app inbox, filled by the in_app channel.
+CREATE TABLE IF NOT EXISTS inbox_message (
+    id UUID PRIMARY KEY,
+    delivery_id UUID NOT NULL UNIQUE REFERENCES notification_delivery(id) ON DELETE CASCADE,
+    customer VARCHAR(255) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbox_message_customer ON inbox_message (customer, created_at DESC);
+
+-- Outgoing webhooks. Subscribers receive car and engine events, signed with
+-- their secret. event_types holds names such as 'car.updated'.
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+    id UUID PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Events are read from audit_log, which is written in the same transaction
+-- as the change. The cursor records how far it has been read; starting it
+-- at install time keeps existing history from being sent.
+CREATE TABLE IF NOT EXISTS webhook_cursor (
+    name VARCHAR(50) PRIMARY KEY,
+    position TIMESTAMP NOT NULL
+);
+
+INSERT INTO webhook_cursor (name, position) VALUES ('audit_log', CURRENT_TIMESTAMP)
+ON CONFLICT (name) DO NOTHING;
+
+-- One row per event and subscription. event_id is the audit_log entry and
+-- is sent as the event's id, so receivers can discard repeats.
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_status_code INT,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_webhook_delivery_event UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_due ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_subscription ON webhook_delivery (subscription_id, created_at DESC);
+
+-- Delivery log: every POST made for a delivery, including redeliveries.
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempt (
+    id UUID PRIMARY KEY,
+    delivery_id UUID NOT NULL REFERENCES webhook_delivery(id) ON DELETE CASCADE,
+    attempted_at TIMESTAMPTZ NOT NULL,
+    status_code INT,
+    error TEXT,
+    response_body TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempt_delivery ON webhook_delivery_attempt (delivery_id, attempted_at);
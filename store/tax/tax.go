@@ -0,0 +1,259 @@
+package tax
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	regionColumns  = "code, name, vat_percent, registration_fee_percent, ev_registration_fee_percent, plate_fee, road_maintenance_fee, insurance_fee, inspection_fee, created_at, updated_at"
+	sctRuleColumns = "id, fuel_type, min_displacement_cc, max_displacement_cc, rate_percent, created_at, updated_at"
+)
+
+type TaxStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) TaxStore {
+	return TaxStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRegion(row rowScanner) (models.TaxRegion, error) {
+	var region models.TaxRegion
+	err := row.Scan(
+		&region.Code,
+		&region.Name,
+		&region.VATPercent,
+		&region.RegistrationFeePercent,
+		&region.EVRegistrationFeePercent,
+		&region.PlateFee,
+		&region.RoadMaintenanceFee,
+		&region.InsuranceFee,
+		&region.InspectionFee,
+		&region.CreatedAt,
+		&region.UpdatedAt,
+	)
+	return region, err
+}
+
+func scanSCTRule(row rowScanner) (models.SCTRule, error) {
+	var rule models.SCTRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.FuelType,
+		&rule.MinDisplacementCC,
+		&rule.MaxDisplacementCC,
+		&rule.RatePercent,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	return rule, err
+}
+
+func (s TaxStore) GetTaxRegions(ctx context.Context) ([]models.TaxRegion, error) {
+	tracer := otel.Tracer("TaxStore")
+
+	ctx, span := tracer.Start(ctx, "GetTaxRegions-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+regionColumns+" FROM tax_region ORDER BY code")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := []models.TaxRegion{}
+
+	for rows.Next() {
+		region, err := scanRegion(rows)
+		if err != nil {
+			return nil, err
+		}
+		regions = append(regions, region)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return regions, nil
+}
+
+func (s TaxStore) GetTaxRegion(ctx context.Context, code string) (models.TaxRegion, error) {
+	tracer := otel.Tracer("TaxStore")
+
+	ctx, span := tracer.Start(ctx, "GetTaxRegion-Store")
+
+	defer span.End()
+
+	region, err := scanRegion(s.db.QueryRowContext(ctx, "SELECT "+regionColumns+" FROM tax_region WHERE code = $1", code))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TaxRegion{}, models.ErrTaxRegionNotFound
+		}
+		return models.TaxRegion{}, err
+	}
+
+	return region, nil
+}
+
+// UpsertTaxRegion creates the region or replaces its rates and fees,
+// keeping its original created_at.
+func (s TaxStore) UpsertTaxRegion(ctx context.Context, code string, regionReq *models.TaxRegionRequest) (models.TaxRegion, error) {
+	tracer := otel.Tracer("TaxStore")
+
+	ctx, span := tracer.Start(ctx, "UpsertTaxRegion-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	query := `INSERT INTO tax_region (` + regionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+		ON CONFLICT (code) DO UPDATE
+		SET name = EXCLUDED.name, vat_percent = EXCLUDED.vat_percent,
+		    registration_fee_percent = EXCLUDED.registration_fee_percent,
+		    ev_registration_fee_percent = EXCLUDED.ev_registration_fee_percent,
+		    plate_fee = EXCLUDED.plate_fee, road_maintenance_fee = EXCLUDED.road_maintenance_fee,
+		    insurance_fee = EXCLUDED.insurance_fee, inspection_fee = EXCLUDED.inspection_fee,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING ` + regionColumns
+
+	return scanRegion(s.db.QueryRowContext(ctx, query,
+		code,
+		strings.TrimSpace(regionReq.Name),
+		regionReq.VATPercent,
+		regionReq.RegistrationFeePercent,
+		regionReq.EVRegistrationFeePercent,
+		regionReq.PlateFee,
+		regionReq.RoadMaintenanceFee,
+		regionReq.InsuranceFee,
+		regionReq.InspectionFee,
+		now,
+	))
+}
+
+func (s TaxStore) DeleteTaxRegion(ctx context.Context, code string) (models.TaxRegion, error) {
+	tracer := otel.Tracer("TaxStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteTaxRegion-Store")
+
+	defer span.End()
+
+	region, err := scanRegion(s.db.QueryRowContext(ctx, "DELETE FROM tax_region WHERE code = $1 RETURNING "+regionColumns, code))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TaxRegion{}, models.ErrTaxRegionNotFound
+		}
+		return models.TaxRegion{}, err
+	}
+
+	return region, nil
+}
+
+// GetSCTRules lists the special consumption tax rules, fuel-specific rules
+// before generic ones and by displacement within each.
+func (s TaxStore) GetSCTRules(ctx context.Context) ([]models.SCTRule, error) {
+	tracer := otel.Tracer("TaxStore")
+
+	ctx, span := tracer.Start(ctx, "GetSCTRules-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+sctRuleColumns+" FROM special_consumption_tax_rule ORDER BY fuel_type NULLS LAST, min_displacement_cc")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.SCTRule{}
+
+	for rows.Next() {
+		rule, err := scanSCTRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (s TaxStore) CreateSCTRule(ctx context.Context, ruleReq *models.SCTRuleRequest) (models.SCTRule, error) {
+	tracer := otel.Tracer("TaxStore")
+
+	ctx, span := tracer.Start(ctx, "CreateSCTRule-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	query := `INSERT INTO special_consumption_tax_rule (` + sctRuleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING ` + sctRuleColumns
+
+	return scanSCTRule(s.db.QueryRowContext(ctx, query,
+		uuid.New(), ruleReq.FuelType, ruleReq.MinDisplacementCC, ruleReq.MaxDisplacementCC, ruleReq.RatePercent, now))
+}
+
+func (s TaxStore) UpdateSCTRule(ctx context.Context, id string, ruleReq *models.SCTRuleRequest) (models.SCTRule, error) {
+	tracer := otel.Tracer("TaxStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateSCTRule-Store")
+
+	defer span.End()
+
+	query := `UPDATE special_consumption_tax_rule
+	          SET fuel_type = $2, min_displacement_cc = $3, max_displacement_cc = $4, rate_percent = $5, updated_at = $6
+	          WHERE id = $1
+	          RETURNING ` + sctRuleColumns
+
+	rule, err := scanSCTRule(s.db.QueryRowContext(ctx, query,
+		id, ruleReq.FuelType, ruleReq.MinDisplacementCC, ruleReq.MaxDisplacementCC, ruleReq.RatePercent, time.Now()))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SCTRule{}, models.ErrSCTRuleNotFound
+		}
+		return models.SCTRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (s TaxStore) DeleteSCTRule(ctx context.Context, id string) (models.SCTRule, error) {
+	tracer := otel.Tracer("TaxStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteSCTRule-Store")
+
+	defer span.End()
+
+	rule, err := scanSCTRule(s.db.QueryRowContext(ctx, "DELETE FROM special_consumption_tax_rule WHERE id = $1 RETURNING "+sctRuleColumns, id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SCTRule{}, models.ErrSCTRuleNotFound
+		}
+		return models.SCTRule{}, err
+	}
+
+	return rule, nil
+}
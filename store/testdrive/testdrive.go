@@ -0,0 +1,347 @@
+package testdrive
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+const testDriveColumns = "id, car_id, unit_id, dealership_id, customer, status, starts_at, ends_at, created_at, updated_at"
+
+// exclusionViolation is the Postgres error code raised by
+// excl_test_drive_unit_overlap.
+const exclusionViolation = "23P01"
+
+// pickUnitQuery finds a unit of the car at the dealership that has no
+// active booking overlapping [$3, $4), ignoring booking $5 so a reschedule
+// can keep its own unit. Sold units cannot be driven. Locked units are
+// skipped so concurrent bookings spread over the free units.
+const pickUnitQuery = `SELECT u.id FROM inventory_unit u
+	WHERE u.car_id = $1
+		AND u.dealership_id = $2
+		AND u.status <> 'sold'
+		AND NOT EXISTS (
+			SELECT 1 FROM test_drive t
+			WHERE t.unit_id = u.id
+				AND t.id <> $5
+				AND t.status IN ('scheduled', 'confirmed')
+				AND t.starts_at < $4
+				AND t.ends_at > $3)
+	ORDER BY u.id = $6 DESC, u.mileage_km
+	LIMIT 1
+	FOR UPDATE OF u SKIP LOCKED`
+
+type TestDriveStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) TestDriveStore {
+	return TestDriveStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTestDrive(row rowScanner) (models.TestDrive, error) {
+	var testDrive models.TestDrive
+	err := row.Scan(
+		&testDrive.ID,
+		&testDrive.CarID,
+		&testDrive.UnitID,
+		&testDrive.DealershipID,
+		&testDrive.Customer,
+		&testDrive.Status,
+		&testDrive.StartsAt,
+		&testDrive.EndsAt,
+		&testDrive.CreatedAt,
+		&testDrive.UpdatedAt,
+	)
+	return testDrive, err
+}
+
+// slotError turns an exclusion constraint violation into ErrSlotUnavailable.
+func slotError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == exclusionViolation {
+		return models.ErrSlotUnavailable
+	}
+	return err
+}
+
+// CountEligibleUnits returns how many units of the car at the dealership
+// can be booked at all.
+func (s TestDriveStore) CountEligibleUnits(ctx context.Context, carID, dealershipID string) (int, error) {
+	tracer := otel.Tracer("TestDriveStore")
+
+	ctx, span := tracer.Start(ctx, "CountEligibleUnits-Store")
+
+	defer span.End()
+
+	var count int
+
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM inventory_unit
+			WHERE car_id = $1 AND dealership_id = $2 AND status <> 'sold'`, carID, dealershipID).Scan(&count)
+
+	return count, err
+}
+
+// GetActiveTestDrives lists active bookings of the car at the dealership
+// overlapping [from, to).
+func (s TestDriveStore) GetActiveTestDrives(ctx context.Context, carID, dealershipID string, from, to time.Time) ([]models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveStore")
+
+	ctx, span := tracer.Start(ctx, "GetActiveTestDrives-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+testDriveColumns+` FROM test_drive
+			WHERE car_id = $1 AND dealership_id = $2
+				AND status IN ('scheduled', 'confirmed')
+				AND starts_at < $4 AND ends_at > $3
+			ORDER BY starts_at`, carID, dealershipID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectTestDrives(rows)
+}
+
+func (s TestDriveStore) GetTestDrivesByCustomer(ctx context.Context, customer string) ([]models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveStore")
+
+	ctx, span := tracer.Start(ctx, "GetTestDrivesByCustomer-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+testDriveColumns+" FROM test_drive WHERE customer = $1 ORDER BY starts_at DESC", customer)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectTestDrives(rows)
+}
+
+func collectTestDrives(rows *sql.Rows) ([]models.TestDrive, error) {
+	defer rows.Close()
+
+	testDrives := []models.TestDrive{}
+
+	for rows.Next() {
+		testDrive, err := scanTestDrive(rows)
+		if err != nil {
+			return nil, err
+		}
+		testDrives = append(testDrives, testDrive)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return testDrives, nil
+}
+
+func (s TestDriveStore) GetTestDriveById(ctx context.Context, id string) (models.TestDrive, error) {
+	tracer := otel.Tracer("TestDriveStore")
+
+	ctx, span := tracer.Start(ctx, "GetTestDriveByID-Store")
+
+	defer span.End()
+
+	testDrive, err := scanTestDrive(s.db.QueryRowContext(ctx, "SELECT "+testDriveColumns+" FROM test_drive WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TestDrive{}, models.ErrTestDriveNotFound
+		}
+		return models.TestDrive{}, err
+	}
+
+	return testDrive, nil
+}
+
+// CreateTestDrive books the first free unit of the car at the dealership.
+func (s TestDriveStore) CreateTestDrive(ctx context.Context, customer string, testDriveReq *models.TestDriveRequest, endsAt time.Time) (testDrive models.TestDrive, err error) {
+	tracer := otel.Tracer("TestDriveStore")
+
+	ctx, span := tracer.Start(ctx, "CreateTestDrive-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var unitID uuid.UUID
+
+	err = tx.QueryRowContext(ctx, pickUnitQuery,
+		testDriveReq.CarID, testDriveReq.DealershipID, testDriveReq.StartsAt, endsAt, uuid.Nil, uuid.Nil).Scan(&unitID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrSlotUnavailable
+		}
+		return models.TestDrive{}, err
+	}
+
+	now := time.Now()
+
+	testDrive = models.TestDrive{
+		ID:           uuid.New(),
+		CarID:        testDriveReq.CarID,
+		UnitID:       unitID,
+		DealershipID: testDriveReq.DealershipID,
+		Customer:     customer,
+		Status:       models.TestDriveStatusScheduled,
+		StartsAt:     testDriveReq.StartsAt,
+		EndsAt:       endsAt,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO test_drive (`+testDriveColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		testDrive.ID,
+		testDrive.CarID,
+		testDrive.UnitID,
+		testDrive.DealershipID,
+		testDrive.Customer,
+		testDrive.Status,
+		testDrive.StartsAt,
+		testDrive.EndsAt,
+		testDrive.CreatedAt,
+		testDrive.UpdatedAt,
+	)
+	if err != nil {
+		err = slotError(err)
+		return models.TestDrive{}, err
+	}
+
+	return testDrive, nil
+}
+
+// RescheduleTestDrive moves an active booking owned by the customer to a
+// new slot, keeping its unit when that unit is still free.
+func (s TestDriveStore) RescheduleTestDrive(ctx context.Context, id, customer string, startsAt, endsAt time.Time) (testDrive models.TestDrive, err error) {
+	tracer := otel.Tracer("TestDriveStore")
+
+	ctx, span := tracer.Start(ctx, "RescheduleTestDrive-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	testDrive, err = scanTestDrive(tx.QueryRowContext(ctx,
+		"SELECT "+testDriveColumns+" FROM test_drive WHERE id = $1 AND customer = $2 FOR UPDATE", id, customer))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrTestDriveNotFound
+		}
+		return models.TestDrive{}, err
+	}
+
+	if !testDrive.Status.IsActive() {
+		err = models.ErrInvalidTestDriveTransition
+		return models.TestDrive{}, err
+	}
+
+	var unitID uuid.UUID
+
+	err = tx.QueryRowContext(ctx, pickUnitQuery,
+		testDrive.CarID, testDrive.DealershipID, startsAt, endsAt, testDrive.ID, testDrive.UnitID).Scan(&unitID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrSlotUnavailable
+		}
+		return models.TestDrive{}, err
+	}
+
+	now := time.Now()
+
+	// A rescheduled booking needs to be confirmed again.
+	testDrive, err = scanTestDrive(tx.QueryRowContext(ctx, `UPDATE test_drive
+			SET unit_id = $2, starts_at = $3, ends_at = $4, status = $5, updated_at = $6
+			WHERE id = $1
+			RETURNING `+testDriveColumns,
+		id, unitID, startsAt, endsAt, models.TestDriveStatusScheduled, now))
+	if err != nil {
+		err = slotError(err)
+		return models.TestDrive{}, err
+	}
+
+	return testDrive, nil
+}
+
+// UpdateTestDriveStatus applies a status transition. When customer is not
+// empty the booking must belong to that customer.
+func (s TestDriveStore) UpdateTestDriveStatus(ctx context.Context, id, customer string, status models.TestDriveStatus) (testDrive models.TestDrive, err error) {
+	tracer := otel.Tracer("TestDriveStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateTestDriveStatus-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	testDrive, err = scanTestDrive(tx.QueryRowContext(ctx,
+		"SELECT "+testDriveColumns+" FROM test_drive WHERE id = $1 AND ($2 = '' OR customer = $2) FOR UPDATE", id, customer))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrTestDriveNotFound
+		}
+		return models.TestDrive{}, err
+	}
+
+	err = models.CanTransitionTestDrive(testDrive.Status, status)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	now := time.Now()
+
+	_, err = tx.ExecContext(ctx, "UPDATE test_drive SET status = $2, updated_at = $3 WHERE id = $1", id, status, now)
+	if err != nil {
+		return models.TestDrive{}, err
+	}
+
+	testDrive.Status = status
+	testDrive.UpdatedAt = now
+
+	return testDrive, nil
+}
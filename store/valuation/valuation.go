@@ -0,0 +1,313 @@
+package valuation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const curveColumns = "id, brand, points, annual_mileage_km, mileage_rate_per_1000_km, max_mileage_adjustment, condition_factors, trade_in_margin_percent, created_at, updated_at"
+
+type ValuationStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) ValuationStore {
+	return ValuationStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCurve(row rowScanner) (models.DepreciationCurve, error) {
+	var curve models.DepreciationCurve
+	err := row.Scan(
+		&curve.ID,
+		&curve.Brand,
+		&curve.Points,
+		&curve.AnnualMileageKm,
+		&curve.MileageRatePer1000Km,
+		&curve.MaxMileageAdjustment,
+		&curve.ConditionFactors,
+		&curve.TradeInMarginPercent,
+		&curve.CreatedAt,
+		&curve.UpdatedAt,
+	)
+	return curve, err
+}
+
+func trimBrand(brand *string) *string {
+	if brand == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*brand)
+	return &trimmed
+}
+
+func (s ValuationStore) GetCurves(ctx context.Context) ([]models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationStore")
+
+	ctx, span := tracer.Start(ctx, "GetCurves-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+curveColumns+" FROM depreciation_curve ORDER BY brand NULLS FIRST")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	curves := []models.DepreciationCurve{}
+
+	for rows.Next() {
+		curve, err := scanCurve(rows)
+		if err != nil {
+			return nil, err
+		}
+		curves = append(curves, curve)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return curves, nil
+}
+
+func (s ValuationStore) GetCurveById(ctx context.Context, id string) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationStore")
+
+	ctx, span := tracer.Start(ctx, "GetCurveByID-Store")
+
+	defer span.End()
+
+	curve, err := scanCurve(s.db.QueryRowContext(ctx, "SELECT "+curveColumns+" FROM depreciation_curve WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DepreciationCurve{}, models.ErrDepreciationCurveNotFound
+		}
+		return models.DepreciationCurve{}, err
+	}
+
+	return curve, nil
+}
+
+// GetCurveForBrand returns the brand's own curve, or the default curve if
+// it has none.
+func (s ValuationStore) GetCurveForBrand(ctx context.Context, brand string) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationStore")
+
+	ctx, span := tracer.Start(ctx, "GetCurveForBrand-Store")
+
+	defer span.End()
+
+	query := "SELECT " + curveColumns + ` FROM depreciation_curve
+		WHERE lower(brand) = lower($1) OR brand IS NULL
+		ORDER BY brand NULLS LAST
+		LIMIT 1`
+
+	curve, err := scanCurve(s.db.QueryRowContext(ctx, query, strings.TrimSpace(brand)))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DepreciationCurve{}, models.ErrDepreciationCurveNotFound
+		}
+		return models.DepreciationCurve{}, err
+	}
+
+	return curve, nil
+}
+
+func (s ValuationStore) CreateCurve(ctx context.Context, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationStore")
+
+	ctx, span := tracer.Start(ctx, "CreateCurve-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	curve := models.DepreciationCurve{
+		ID:                   uuid.New(),
+		Brand:                trimBrand(curveReq.Brand),
+		Points:               curveReq.Points,
+		AnnualMileageKm:      curveReq.AnnualMileageKm,
+		MileageRatePer1000Km: curveReq.MileageRatePer1000Km,
+		MaxMileageAdjustment: curveReq.MaxMileageAdjustment,
+		ConditionFactors:     curveReq.ConditionFactors,
+		TradeInMarginPercent: curveReq.TradeInMarginPercent,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	query := `INSERT INTO depreciation_curve (` + curveColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := s.db.ExecContext(ctx, query,
+		curve.ID,
+		curve.Brand,
+		curve.Points,
+		curve.AnnualMileageKm,
+		curve.MileageRatePer1000Km,
+		curve.MaxMileageAdjustment,
+		curve.ConditionFactors,
+		curve.TradeInMarginPercent,
+		curve.CreatedAt,
+		curve.UpdatedAt,
+	)
+
+	if err != nil {
+		return models.DepreciationCurve{}, err
+	}
+
+	return curve, nil
+}
+
+func (s ValuationStore) UpdateCurve(ctx context.Context, id string, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateCurve-Store")
+
+	defer span.End()
+
+	query := `UPDATE depreciation_curve
+	          SET brand = $2, points = $3, annual_mileage_km = $4, mileage_rate_per_1000_km = $5, max_mileage_adjustment = $6,
+	              condition_factors = $7, trade_in_margin_percent = $8, updated_at = $9
+	          WHERE id = $1
+	          RETURNING ` + curveColumns
+
+	curve, err := scanCurve(s.db.QueryRowContext(ctx, query,
+		id,
+		trimBrand(curveReq.Brand),
+		curveReq.Points,
+		curveReq.AnnualMileageKm,
+		curveReq.MileageRatePer1000Km,
+		curveReq.MaxMileageAdjustment,
+		curveReq.ConditionFactors,
+		curveReq.TradeInMarginPercent,
+		time.Now(),
+	))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DepreciationCurve{}, models.ErrDepreciationCurveNotFound
+		}
+		return models.DepreciationCurve{}, err
+	}
+
+	return curve, nil
+}
+
+func (s ValuationStore) DeleteCurve(ctx context.Context, id string) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("ValuationStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteCurve-Store")
+
+	defer span.End()
+
+	curve, err := scanCurve(s.db.QueryRowContext(ctx, "DELETE FROM depreciation_curve WHERE id = $1 RETURNING "+curveColumns, id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DepreciationCurve{}, models.ErrDepreciationCurveNotFound
+		}
+		return models.DepreciationCurve{}, err
+	}
+
+	return curve, nil
+}
+
+// modelFilter matches cars by their catalog model, or by name for cars
+// created before models existed. An empty model matches every car.
+const modelFilter = `($2 = '' OR lower(m.name) = lower($2) OR lower(c.name) = lower($2))`
+
+// GetListingSamples returns the current catalog prices for the brand and
+// model. Mileage is not known for listings and is left at zero.
+func (s ValuationStore) GetListingSamples(ctx context.Context, brand, model string) ([]models.ValuationSample, error) {
+	tracer := otel.Tracer("ValuationStore")
+
+	ctx, span := tracer.Start(ctx, "GetListingSamples-Store")
+
+	defer span.End()
+
+	query := `SELECT c.price, c.currency, c.year, c.updated_at
+		FROM car c
+		LEFT JOIN model m ON c.model_id = m.id
+		WHERE lower(c.brand) = lower($1) AND ` + modelFilter
+
+	rows, err := s.db.QueryContext(ctx, query, strings.TrimSpace(brand), strings.TrimSpace(model))
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSamples(rows, false)
+}
+
+// GetSaleSamples returns delivered orders for the brand and model since the
+// given time, at the price they sold for and the unit's recorded mileage.
+func (s ValuationStore) GetSaleSamples(ctx context.Context, brand, model string, since time.Time) ([]models.ValuationSample, error) {
+	tracer := otel.Tracer("ValuationStore")
+
+	ctx, span := tracer.Start(ctx, "GetSaleSamples-Store")
+
+	defer span.End()
+
+	query := `SELECT o.price - o.discount, o.currency, c.year, u.mileage_km, o.updated_at
+		FROM sales_order o
+		JOIN car c ON o.car_id = c.id
+		JOIN inventory_unit u ON o.unit_id = u.id
+		LEFT JOIN model m ON c.model_id = m.id
+		WHERE lower(c.brand) = lower($1) AND ` + modelFilter + `
+		  AND o.status = $3 AND o.updated_at >= $4`
+
+	rows, err := s.db.QueryContext(ctx, query, strings.TrimSpace(brand), strings.TrimSpace(model), models.OrderStatusDelivered, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSamples(rows, true)
+}
+
+func collectSamples(rows *sql.Rows, withMileage bool) ([]models.ValuationSample, error) {
+	defer rows.Close()
+
+	samples := []models.ValuationSample{}
+
+	for rows.Next() {
+		var sample models.ValuationSample
+		var year string
+
+		dest := []interface{}{&sample.Price.Amount, &sample.Price.Currency, &year}
+		if withMileage {
+			dest = append(dest, &sample.MileageKm)
+		}
+		dest = append(dest, &sample.At)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		// Skip listings with a malformed year rather than failing the
+		// whole valuation.
+		parsed, err := strconv.Atoi(year)
+		if err != nil {
+			continue
+		}
+		sample.Year = parsed
+		samples = append(samples, sample)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
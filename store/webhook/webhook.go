@@ -0,0 +1,588 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	subscriptionColumns = "id, url, secret, event_types, description, active, created_at, updated_at"
+	deliveryColumns     = "id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at"
+	attemptColumns      = "id, delivery_id, attempted_at, status_code, error, response_body, duration_ms"
+
+	// auditCursor names the webhook_cursor row tracking how far the audit
+	// log has been turned into deliveries.
+	auditCursor = "audit_log"
+)
+
+type WebhookStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) WebhookStore {
+	return WebhookStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSubscription(row rowScanner) (models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	var eventTypes []string
+
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.Secret,
+		pq.Array(&eventTypes),
+		&subscription.Description,
+		&subscription.Active,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+
+	for _, eventType := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, models.WebhookEventType(eventType))
+	}
+
+	return subscription, err
+}
+
+func collectSubscriptions(rows *sql.Rows) ([]models.WebhookSubscription, error) {
+	defer rows.Close()
+
+	subscriptions := []models.WebhookSubscription{}
+
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func scanDelivery(row rowScanner) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var payload []byte
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	delivery.Payload = json.RawMessage(payload)
+
+	return delivery, err
+}
+
+func collectDeliveries(rows *sql.Rows) ([]models.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func scanAttempt(row rowScanner) (models.WebhookAttempt, error) {
+	var attempt models.WebhookAttempt
+	err := row.Scan(
+		&attempt.ID,
+		&attempt.DeliveryID,
+		&attempt.AttemptedAt,
+		&attempt.StatusCode,
+		&attempt.Error,
+		&attempt.ResponseBody,
+		&attempt.DurationMs,
+	)
+	return attempt, err
+}
+
+func eventTypeStrings(eventTypes []models.WebhookEventType) []string {
+	values := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		values = append(values, string(eventType))
+	}
+	return values
+}
+
+func (s WebhookStore) GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "GetSubscriptions-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscription ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSubscriptions(rows)
+}
+
+// GetActiveSubscriptions lists the subscriptions that receive events.
+func (s WebhookStore) GetActiveSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "GetActiveSubscriptions-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscription WHERE active ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSubscriptions(rows)
+}
+
+func (s WebhookStore) GetSubscriptionById(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "GetSubscriptionById-Store")
+
+	defer span.End()
+
+	subscription, err := scanSubscription(s.db.QueryRowContext(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscription WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookSubscription{}, models.ErrWebhookSubscriptionNotFound
+		}
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (s WebhookStore) CreateSubscription(ctx context.Context, subscriptionReq *models.WebhookSubscriptionRequest, secret string) (models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "CreateSubscription-Store")
+
+	defer span.End()
+
+	now := time.Now()
+
+	query := `INSERT INTO webhook_subscription (` + subscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING ` + subscriptionColumns
+
+	return scanSubscription(s.db.QueryRowContext(ctx, query,
+		uuid.New(),
+		subscriptionReq.URL,
+		secret,
+		pq.Array(eventTypeStrings(subscriptionReq.EventTypes)),
+		strings.TrimSpace(subscriptionReq.Description),
+		subscriptionReq.Active,
+		now,
+	))
+}
+
+// UpdateSubscription replaces the subscription's settings. The secret is
+// only changed when the request carries one.
+func (s WebhookStore) UpdateSubscription(ctx context.Context, id string, subscriptionReq *models.WebhookSubscriptionRequest) (models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "UpdateSubscription-Store")
+
+	defer span.End()
+
+	query := `UPDATE webhook_subscription
+	          SET url = $2, secret = COALESCE($3, secret), event_types = $4, description = $5, active = $6, updated_at = $7
+	          WHERE id = $1
+	          RETURNING ` + subscriptionColumns
+
+	subscription, err := scanSubscription(s.db.QueryRowContext(ctx, query,
+		id,
+		subscriptionReq.URL,
+		subscriptionReq.Secret,
+		pq.Array(eventTypeStrings(subscriptionReq.EventTypes)),
+		strings.TrimSpace(subscriptionReq.Description),
+		subscriptionReq.Active,
+		time.Now(),
+	))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookSubscription{}, models.ErrWebhookSubscriptionNotFound
+		}
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription removes the subscription together with its deliveries
+// and their log.
+func (s WebhookStore) DeleteSubscription(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "DeleteSubscription-Store")
+
+	defer span.End()
+
+	subscription, err := scanSubscription(s.db.QueryRowContext(ctx, "DELETE FROM webhook_subscription WHERE id = $1 RETURNING "+subscriptionColumns, id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookSubscription{}, models.ErrWebhookSubscriptionNotFound
+		}
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// GetCursor is the audit log position up to which events have been queued.
+func (s WebhookStore) GetCursor(ctx context.Context) (time.Time, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "GetCursor-Store")
+
+	defer span.End()
+
+	var position time.Time
+
+	err := s.db.QueryRowContext(ctx, "SELECT position FROM webhook_cursor WHERE name = $1", auditCursor).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Now(), nil
+	}
+
+	return position, err
+}
+
+// GetAuditEntries lists the audit entries of the given entity types written
+// after from and up to to, oldest first.
+func (s WebhookStore) GetAuditEntries(ctx context.Context, entityTypes []string, from, to time.Time) ([]models.AuditEntry, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "GetAuditEntries-Store")
+
+	defer span.End()
+
+	query := `SELECT id, action, entity_type, entity_id, before, after, diff, created_at
+	          FROM audit_log
+	          WHERE entity_type = ANY($1) AND created_at > $2 AND created_at <= $3
+	          ORDER BY created_at`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(entityTypes), from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after, diff []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&before,
+			&after,
+			&diff,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if before != nil {
+			entry.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			entry.After = json.RawMessage(after)
+		}
+		if err := json.Unmarshal(diff, &entry.Diff); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// EnqueueDeliveries queues the deliveries and moves the cursor to position
+// in one transaction. A delivery of an event the subscription has already
+// been sent is skipped, so overlapping scans are harmless.
+func (s WebhookStore) EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery, position time.Time) (err error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "EnqueueDeliveries-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `INSERT INTO webhook_delivery (` + deliveryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	for _, delivery := range deliveries {
+		_, err = tx.ExecContext(ctx, query,
+			delivery.ID,
+			delivery.SubscriptionID,
+			delivery.EventID,
+			delivery.EventType,
+			string(delivery.Payload),
+			delivery.Status,
+			delivery.Attempts,
+			delivery.NextAttemptAt,
+			delivery.LastStatusCode,
+			delivery.LastError,
+			delivery.DeliveredAt,
+			delivery.CreatedAt,
+			delivery.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO webhook_cursor (name, position) VALUES ($1, $2)
+		 ON CONFLICT (name) DO UPDATE SET position = GREATEST(webhook_cursor.position, EXCLUDED.position)`,
+		auditCursor, position)
+
+	return err
+}
+
+// ClaimDueDeliveries takes up to limit pending deliveries of active
+// subscriptions that are due and counts the attempt. Their next attempt is
+// pushed lease into the future, so another worker will not pick them up
+// while they are being sent.
+func (s WebhookStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "ClaimDueDeliveries-Store")
+
+	defer span.End()
+
+	query := `UPDATE webhook_delivery
+	          SET attempts = attempts + 1, next_attempt_at = $2, updated_at = $1
+	          WHERE id IN (
+	              SELECT d.id FROM webhook_delivery d
+	              JOIN webhook_subscription s ON s.id = d.subscription_id
+	              WHERE d.status = $3 AND d.next_attempt_at <= $1 AND s.active
+	              ORDER BY d.next_attempt_at
+	              LIMIT $4
+	              FOR UPDATE OF d SKIP LOCKED
+	          )
+	          RETURNING ` + deliveryColumns
+
+	rows, err := s.db.QueryContext(ctx, query, now, now.Add(lease), models.WebhookDeliveryPending, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectDeliveries(rows)
+}
+
+// RecordAttempt logs an attempt and updates the delivery: delivered when
+// the attempt succeeded, otherwise pending until nextAttempt or failed when
+// nextAttempt is nil.
+func (s WebhookStore) RecordAttempt(ctx context.Context, attempt models.WebhookAttempt, nextAttempt *time.Time) (err error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "RecordAttempt-Store")
+
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO webhook_delivery_attempt (`+attemptColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		attempt.ID,
+		attempt.DeliveryID,
+		attempt.AttemptedAt,
+		attempt.StatusCode,
+		attempt.Error,
+		attempt.ResponseBody,
+		attempt.DurationMs,
+	)
+	if err != nil {
+		return err
+	}
+
+	if attempt.Succeeded() {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE webhook_delivery
+			 SET status = $2, last_status_code = $3, last_error = NULL, delivered_at = $4, updated_at = $4
+			 WHERE id = $1`,
+			attempt.DeliveryID, models.WebhookDeliveryDelivered, attempt.StatusCode, attempt.AttemptedAt)
+		return err
+	}
+
+	status := models.WebhookDeliveryPending
+	next := attempt.AttemptedAt
+	if nextAttempt == nil {
+		status = models.WebhookDeliveryFailed
+	} else {
+		next = *nextAttempt
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE webhook_delivery
+		 SET status = $2, last_status_code = $3, last_error = $4, next_attempt_at = $5, updated_at = $6
+		 WHERE id = $1`,
+		attempt.DeliveryID, status, attempt.StatusCode, attempt.Describe(), next, attempt.AttemptedAt)
+
+	return err
+}
+
+// GetDeliveries lists a subscription's most recent deliveries, optionally
+// only those in one status.
+func (s WebhookStore) GetDeliveries(ctx context.Context, subscriptionID string, status *models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "GetDeliveries-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+deliveryColumns+" FROM webhook_delivery WHERE subscription_id = $1 AND ($2::text IS NULL OR status = $2) ORDER BY created_at DESC LIMIT $3",
+		subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectDeliveries(rows)
+}
+
+// GetDeliveryById returns the delivery with its attempt log.
+func (s WebhookStore) GetDeliveryById(ctx context.Context, id string) (models.WebhookDelivery, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "GetDeliveryById-Store")
+
+	defer span.End()
+
+	delivery, err := scanDelivery(s.db.QueryRowContext(ctx, "SELECT "+deliveryColumns+" FROM webhook_delivery WHERE id = $1", id))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDelivery{}, models.ErrWebhookDeliveryNotFound
+		}
+		return models.WebhookDelivery{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+attemptColumns+" FROM webhook_delivery_attempt WHERE delivery_id = $1 ORDER BY attempted_at", delivery.ID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	delivery.Log = []models.WebhookAttempt{}
+
+	for rows.Next() {
+		attempt, err := scanAttempt(rows)
+		if err != nil {
+			return models.WebhookDelivery{}, err
+		}
+		delivery.Log = append(delivery.Log, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// Redeliver queues the delivery to be sent again at now with a fresh set of
+// attempts, whatever its status. Earlier attempts stay in the log.
+func (s WebhookStore) Redeliver(ctx context.Context, id string, now time.Time) (models.WebhookDelivery, error) {
+	tracer := otel.Tracer("WebhookStore")
+
+	ctx, span := tracer.Start(ctx, "Redeliver-Store")
+
+	defer span.End()
+
+	query := `UPDATE webhook_delivery
+	          SET status = $2, attempts = 0, next_attempt_at = $3, updated_at = $3
+	          WHERE id = $1
+	          RETURNING ` + deliveryColumns
+
+	delivery, err := scanDelivery(s.db.QueryRowContext(ctx, query, id, models.WebhookDeliveryPending, now))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDelivery{}, models.ErrWebhookDeliveryNotFound
+		}
+		return models.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
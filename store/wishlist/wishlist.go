@@ -0,0 +1,98 @@
+package wishlist
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NhutNam2904/carzone/models"
+	"go.opentelemetry.io/otel"
+)
+
+type WishlistStore struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) WishlistStore {
+	return WishlistStore{db: db}
+}
+
+// GetWishlist lists the customer's favorited cars, most recently added
+// first.
+func (s WishlistStore) GetWishlist(ctx context.Context, customer string) ([]models.WishlistItem, error) {
+	tracer := otel.Tracer("WishlistStore")
+
+	ctx, span := tracer.Start(ctx, "GetWishlist-Store")
+
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT car_id, added_at FROM wishlist_item WHERE customer = $1 ORDER BY added_at DESC", customer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.WishlistItem{}
+
+	for rows.Next() {
+		var item models.WishlistItem
+		if err := rows.Scan(&item.CarID, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// AddToWishlist favorites the car. Adding a car that is already in the
+// wishlist keeps its original added_at.
+func (s WishlistStore) AddToWishlist(ctx context.Context, customer, carID string) (models.WishlistItem, error) {
+	tracer := otel.Tracer("WishlistStore")
+
+	ctx, span := tracer.Start(ctx, "AddToWishlist-Store")
+
+	defer span.End()
+
+	query := `INSERT INTO wishlist_item (customer, car_id, added_at) VALUES ($1, $2, $3)
+	          ON CONFLICT (customer, car_id) DO UPDATE SET added_at = wishlist_item.added_at
+	          RETURNING car_id, added_at`
+
+	var item models.WishlistItem
+
+	err := s.db.QueryRowContext(ctx, query, customer, carID, time.Now()).Scan(&item.CarID, &item.AddedAt)
+	if err != nil {
+		return models.WishlistItem{}, err
+	}
+
+	return item, nil
+}
+
+func (s WishlistStore) RemoveFromWishlist(ctx context.Context, customer, carID string) error {
+	tracer := otel.Tracer("WishlistStore")
+
+	ctx, span := tracer.Start(ctx, "RemoveFromWishlist-Store")
+
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM wishlist_item WHERE customer = $1 AND car_id = $2", customer, carID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return models.ErrWishlistItemNotFound
+	}
+
+	return nil
+}